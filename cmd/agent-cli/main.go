@@ -2,59 +2,285 @@ package main
 
 import (
 	"bufio"
+	"context"
+	"flag"
 	"fmt"
 	"log"
+	"net/url"
 	"os"
+	"os/signal"
 	"strings"
-
-	"github.com/chromedp/chromedp"
+	"time"
 
 	"github.com/nbenliogludev/go-browser-ai-agent/internal/agent"
 	"github.com/nbenliogludev/go-browser-ai-agent/internal/browser"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/cliflags"
 	"github.com/nbenliogludev/go-browser-ai-agent/internal/llm"
 )
 
 func main() {
+	opts := agent.DefaultOptions()
+	browserOpts := browser.DefaultOptions()
+	var provider llm.ProviderConfig
+	var headers cliflags.Headers
+	var policyFlags cliflags.Policy
+	var blockTypes, blockURLs, proxyBypass cliflags.List
+	var httpAuth cliflags.Credentials
+
+	startURL := flag.String("url", "", "start URL (prompted when empty; with -remote-tab the tab's page is kept)")
+	rawTask := flag.String("task", "", "task for the agent (prompted when empty)")
+	flag.IntVar(&opts.MaxSteps, "max-steps", opts.MaxSteps, "maximum number of steps")
+	flag.IntVar(&opts.HistoryWindow, "history", opts.HistoryWindow, "number of history lines sent to the model")
+	flag.IntVar(&opts.LoopThreshold, "loop-threshold", opts.LoopThreshold, "block an action after this many identical repeats")
+	flag.IntVar(&opts.PatternLength, "pattern-len", opts.PatternLength, "length of the action sequence checked by the loop guard (1 disables)")
+	flag.IntVar(&opts.PatternRepeats, "pattern-repeats", opts.PatternRepeats, "block a sequence after it occurred this many times")
+	flag.DurationVar(&opts.StepDelay, "step-delay", opts.StepDelay, "pause between steps (0 = none)")
+	flag.DurationVar(&opts.StepTimeout, "step-timeout", opts.StepTimeout, "timeout for a single step (0 = none)")
+	flag.BoolVar(&opts.DisableScreenshots, "no-screenshot", opts.DisableScreenshots, "do not send screenshots to the model")
+	flag.IntVar(&opts.MaxRecoveries, "max-recoveries", opts.MaxRecoveries, "relaunch a crashed browser at most this many times per run (0 = never)")
+	backend := flag.String("backend", string(browser.BackendChromedp), "browser backend: chromedp (Chrome over DevTools) or playwright")
+	flag.StringVar(&browserOpts.Engine, "engine", "", "with -backend playwright: chromium (default), firefox or webkit")
+	headless := flag.String("headless", "off", "run Chrome without a window: off, on or new")
+	flag.IntVar(&browserOpts.WindowWidth, "window-width", browserOpts.WindowWidth, "browser window width")
+	flag.IntVar(&browserOpts.WindowHeight, "window-height", browserOpts.WindowHeight, "browser window height")
+	flag.StringVar(&browserOpts.ExecPath, "chrome", "", "Chrome binary (default: found on the system)")
+	flag.StringVar(&browserOpts.UserDataDir, "user-data-dir", browserOpts.UserDataDir, "Chrome profile directory")
+	flag.StringVar(&browserOpts.Profile, "profile", "", "use the named persistent profile under -profile-root instead of -user-data-dir")
+	flag.StringVar(&browserOpts.ProfileRoot, "profile-root", "", "directory of named profiles (default "+browser.DefaultProfileRoot()+")")
+	flag.BoolVar(&browserOpts.Ephemeral, "ephemeral-profile", false, "use a fresh temporary profile, deleted on exit")
+	flag.StringVar(&browserOpts.ProfileTemplate, "profile-template", "", "with -ephemeral-profile: start from a copy of this profile directory")
+	flag.BoolVar(&browserOpts.NoSandbox, "no-sandbox", false, "disable the Chrome sandbox (needed as root and in most containers)")
+	flag.StringVar(&browserOpts.RemoteURL, "remote", "", "attach to a running Chrome at this DevTools URL (http://host:9222 or ws://...) instead of launching one")
+	flag.StringVar(&browserOpts.RemoteTab, "remote-tab", "", "with -remote: use the open tab whose URL contains this text (default: open a new tab)")
+	policyFlags.Register(flag.CommandLine)
+	stayOnSite := flag.Bool("stay-on-site", false, "allow only the host of the start URL (added to -allow)")
+	flag.Var(&blockTypes, "block", "do not load these resource types, e.g. image,font,media (repeatable, comma-separated)")
+	flag.Var(&blockURLs, "block-url", "do not load URLs matching this pattern, * and ? wildcards (repeatable, comma-separated)")
+	flag.BoolVar(&browserOpts.Block.Trackers, "block-trackers", false, "block the built-in list of ad and analytics hosts")
+	flag.StringVar(&browserOpts.Emulation.Device, "device", "", "emulate a device preset, e.g. 'iPhone 13' or 'Pixel 5' ('list' prints them)")
+	flag.StringVar(&browserOpts.Emulation.Locale, "locale", "", "browser locale and Accept-Language, e.g. ru-RU or tr-TR")
+	flag.StringVar(&browserOpts.Emulation.Timezone, "timezone", "", "time zone, e.g. Europe/Istanbul")
+	geo := flag.String("geo", "", "geolocation reported to pages, 'latitude,longitude[,accuracy]'")
+	flag.StringVar(&browserOpts.Proxy.Server, "proxy", "", "proxy server, http://[user:password@]host:port or socks5://host:port")
+	flag.Var(&proxyBypass, "proxy-bypass", "hosts reached without the proxy, e.g. *.corp.local,<local> (repeatable, comma-separated)")
+	flag.StringVar(&browserOpts.Proxy.Username, "proxy-user", "", "proxy user name; the password is read from -proxy-password-env")
+	proxyPasswordEnv := flag.String("proxy-password-env", "PROXY_PASSWORD", "environment variable holding the proxy password")
+	flag.Var(&httpAuth, "http-auth", "log in to a site with HTTP authentication, 'site=user:password' (repeatable)")
+	storageState := flag.String("storage-state", "", "load cookies and localStorage saved by -save-storage-state before opening the start URL")
+	saveStorageState := flag.String("save-storage-state", "", "save cookies and localStorage to this file when the run ends")
+	flag.Var(&browserOpts.Flags, "chrome-flag", "extra Chrome switch, 'name' or 'name=value' (repeatable)")
+	providerName := flag.String("provider", string(llm.ProviderOpenAI), "LLM provider: openai, anthropic, ollama, vllm, llamacpp")
+	flag.StringVar(&provider.BaseURL, "base-url", "", "LLM API base URL (any OpenAI-compatible server for -provider openai)")
+	flag.StringVar(&provider.Model, "model", "", "model name (default depends on the provider)")
+	flag.StringVar(&provider.APIKeyEnv, "api-key-env", "", "environment variable holding the API key")
+	flag.BoolVar(&provider.PlainJSON, "plain-json", false, "request plain JSON instead of schema-constrained output (for servers without json_schema support)")
+	flag.Var(&headers, "header", "extra HTTP header for LLM requests, 'Name: value' (repeatable)")
+	temperature := flag.Float64("temperature", float64(opts.Model.Temperature), "sampling temperature")
+	flag.IntVar(&opts.Model.MaxTokens, "max-tokens", opts.Model.MaxTokens, "max completion tokens per decision (0 = default)")
+	flag.IntVar(&provider.Retry.MaxAttempts, "llm-retries", llm.DefaultRetryPolicy().MaxAttempts, "attempts per LLM call for rate limits, 5xx and timeouts (1 = no retries)")
+	flag.DurationVar(&provider.Retry.AttemptTimeout, "llm-timeout", llm.DefaultRetryPolicy().AttemptTimeout, "timeout of a single LLM HTTP call (0 = none)")
+	flag.IntVar(&opts.TokenBudget, "token-budget", 0, "stop the run after this many LLM tokens (0 = unlimited)")
+	flag.Float64Var(&opts.CostBudget, "cost-budget", 0, "stop the run after this LLM cost in USD (0 = unlimited)")
+	recordPath := flag.String("record", "", "record LLM calls to this cassette file")
+	replayPath := flag.String("replay", "", "replay LLM calls from this cassette file instead of calling a provider")
+	pricesPath := flag.String("prices", "", "JSON file with per-model prices in USD per million tokens, merged over the built-in table")
+	flag.Parse()
+
+	// A zero in Options means the default; on the command line it means none.
+	if opts.StepDelay == 0 {
+		opts.StepDelay = -1
+	}
+	if opts.MaxRecoveries == 0 {
+		opts.MaxRecoveries = -1
+	}
+
+	opts.Model.Temperature = float32(*temperature)
+	mode, err := browser.ParseHeadless(*headless)
+	if err != nil {
+		log.Fatal(err)
+	}
+	browserOpts.Headless = mode
+	browserOpts.Backend = browser.Backend(*backend)
+	browserOpts.Block.ResourceTypes = blockTypes
+	browserOpts.Block.Patterns = blockURLs
+	browserOpts.Proxy.Bypass = proxyBypass
+	if browserOpts.Proxy.Username != "" {
+		browserOpts.Proxy.Password = os.Getenv(*proxyPasswordEnv)
+	}
+	browserOpts.Credentials = httpAuth
+	if browserOpts.Emulation.Device == "list" {
+		fmt.Println(strings.Join(browser.DeviceNames(), "\n"))
+		return
+	}
+	if *geo != "" {
+		if browserOpts.Emulation.Geolocation, err = browser.ParseGeolocation(*geo); err != nil {
+			log.Fatal(err)
+		}
+	}
+	provider.Provider = llm.Provider(*providerName)
+	provider.Headers = headers
+
+	if *pricesPath != "" {
+		prices, err := llm.LoadPrices(*pricesPath)
+		if err != nil {
+			log.Fatalf("prices: %v", err)
+		}
+		opts.Prices = prices
+	}
+
+	// Temporary profiles of agents that were killed are never removed by
+	// Close; an hour is well past any run.
+	if removed, err := browser.CleanupTempProfiles(time.Hour); err != nil {
+		log.Printf("Failed to remove stale temporary profiles: %v", err)
+	} else if len(removed) > 0 {
+		log.Printf("Removed %d stale temporary profiles", len(removed))
+	}
+
 	reader := bufio.NewReader(os.Stdin)
 
 	fmt.Println("Starting browser agent...")
 
-	fmt.Print("Enter start URL (empty = https://example.com): ")
-	startURL, _ := reader.ReadString('\n')
-	startURL = strings.TrimSpace(startURL)
-	if startURL == "" {
-		startURL = "https://example.com"
+	// An attached tab starts where the user left it.
+	keepTab := browserOpts.RemoteURL != "" && browserOpts.RemoteTab != "" && *startURL == ""
+
+	if *startURL == "" && !keepTab {
+		fmt.Print("Enter start URL (empty = https://example.com): ")
+		*startURL, _ = reader.ReadString('\n')
+		*startURL = strings.TrimSpace(*startURL)
+	}
+	if *startURL == "" && !keepTab {
+		*startURL = "https://example.com"
 	}
 
-	fmt.Println("Describe the task for the agent (for example: 'Find the login button and click it'):")
-	fmt.Print("> ")
-	rawTask, _ := reader.ReadString('\n')
-	rawTask = strings.TrimSpace(rawTask)
-	if rawTask == "" {
+	if *rawTask == "" {
+		fmt.Println("Describe the task for the agent (for example: 'Find the login button and click it'):")
+		fmt.Print("> ")
+		*rawTask, _ = reader.ReadString('\n')
+		*rawTask = strings.TrimSpace(*rawTask)
+	}
+	if *rawTask == "" {
 		log.Fatal("Empty task — nothing for the agent to do.")
 	}
 
-	task := agent.BuildTaskWithEnvironment(rawTask, startURL)
-
-	bm := browser.NewManager()
-	defer bm.Close()
+	if *storageState != "" && keepTab {
+		log.Fatal("-storage-state cannot be loaded into an attached tab; pass -url")
+	}
 
-	if err := chromedp.Run(bm.Ctx, chromedp.Navigate(startURL)); err != nil {
-		log.Fatalf("Failed to open start URL %s: %v", startURL, err)
+	// Everything that can be checked without a browser is checked before
+	// one is launched.
+	policy := policyFlags.URLPolicy()
+	if *stayOnSite && !keepTab {
+		host, err := siteHost(*startURL)
+		if err != nil {
+			log.Fatal(err)
+		}
+		policy.Allow = append(policy.Allow, host)
+	}
+	if err := policy.Check(*startURL); err != nil {
+		log.Fatalf("URL policy: %v", err)
 	}
 
-	llmClient, err := llm.NewOpenAIClient()
+	var (
+		llmClient llm.Client
+		recorder  *llm.Recorder
+	)
+	if *replayPath != "" {
+		llmClient, err = llm.NewReplayerFromFile(*replayPath)
+	} else {
+		llmClient, err = llm.NewClient(provider)
+		if err == nil && *recordPath != "" {
+			recorder = llm.NewRecorder(llmClient, *recordPath)
+			llmClient = recorder
+		}
+	}
 	if err != nil {
 		log.Fatalf("Failed to initialize LLM client: %v", err)
 	}
 
-	rhythmi := agent.NewAgent(bm, llmClient)
+	bm, err := browser.Open(browserOpts)
+	if err != nil {
+		log.Fatalf("Failed to launch browser: %v", err)
+	}
+	defer bm.Close()
+	// log.Fatal skips deferred calls; fatalf closes the browser first so
+	// Chrome, the profile lock and temporary profiles are not left behind.
+	fatalf := func(format string, args ...any) {
+		bm.Close()
+		log.Fatalf(format, args...)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if *storageState != "" {
+		if err := bm.ImportStorageState(ctx, *storageState); err != nil {
+			fatalf("Failed to load storage state: %v", err)
+		}
+	}
+
+	if keepTab {
+		if *startURL, err = bm.Location(ctx); err != nil {
+			fatalf("Failed to read the URL of the attached tab: %v", err)
+		}
+		if *stayOnSite {
+			host, err := siteHost(*startURL)
+			if err != nil {
+				fatalf("%v", err)
+			}
+			policy.Allow = append(policy.Allow, host)
+		}
+	}
 
-	const maxSteps = 40
-	if err := rhythmi.Run(task, maxSteps); err != nil {
+	if err := bm.SetURLPolicy(ctx, policy); err != nil {
+		fatalf("Invalid URL policy: %v", err)
+	}
+
+	if !keepTab {
+		if err := bm.Navigate(ctx, *startURL); err != nil {
+			fatalf("Failed to open start URL %s: %v", *startURL, err)
+		}
+	}
+
+	task := agent.BuildTaskWithEnvironment(*rawTask, *startURL)
+
+	rhythmi := agent.NewAgentWithOptions(bm, llmClient, opts)
+
+	answer, err := rhythmi.Ask(ctx, task, opts.MaxSteps)
+	stop()
+	if recorder != nil {
+		if err := recorder.Save(); err != nil {
+			log.Printf("Failed to save cassette: %v", err)
+		}
+	}
+	if err != nil {
 		log.Printf("Agent finished with error: %v", err)
+	} else if answer.Answer != "" {
+		fmt.Printf("\nANSWER (success=%s): %s\n", answer.Success, answer.Answer)
+		for _, e := range answer.Evidence {
+			fmt.Printf("  - %s\n", e)
+		}
 	}
 
-	fmt.Println("\nPress Enter to close the browser...")
-	_, _ = reader.ReadString('\n')
+	if browserOpts.Headless == browser.HeadlessOff && !bm.Remote() {
+		fmt.Println("\nPress Enter to close the browser...")
+		_, _ = reader.ReadString('\n')
+	}
+
+	// Saved last so a login finished by hand in the window is included.
+	if *saveStorageState != "" {
+		if err := bm.ExportStorageState(context.Background(), *saveStorageState); err != nil {
+			log.Printf("Failed to save storage state: %v", err)
+		} else {
+			fmt.Printf("Storage state saved to %s\n", *saveStorageState)
+		}
+	}
+}
+
+// siteHost returns the host -stay-on-site adds to the allowlist.
+func siteHost(startURL string) (string, error) {
+	u, err := url.Parse(startURL)
+	if err != nil || u.Host == "" {
+		return "", fmt.Errorf("-stay-on-site: start URL %q has no host", startURL)
+	}
+	return u.Host, nil
 }
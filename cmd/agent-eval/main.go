@@ -0,0 +1,222 @@
+// Command agent-eval runs the agent on a task suite, prints a score per task
+// and compares the results with a saved baseline.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/agent"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/browser"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/cliflags"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/eval"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/fixture"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/llm"
+)
+
+func main() {
+	opts := agent.DefaultOptions()
+	// Every task gets a fresh profile so that no state leaks between tasks.
+	browserOpts := browser.DefaultOptions()
+	browserOpts.Ephemeral = true
+	var provider llm.ProviderConfig
+
+	suitePath := flag.String("suite", "", "task suite (JSON)")
+	siteURL := flag.String("site", "", "base URL for relative task URLs")
+	serveFixture := flag.Bool("fixture", false, "serve the built-in offline test site and use it as -site")
+	outPath := flag.String("out", "", "write the report to this file")
+	baselinePath := flag.String("baseline", "", "compare with this report; exit with status 1 on regressions")
+	verbose := flag.Bool("v", false, "print every step of every run")
+	approve := flag.Bool("approve-destructive", false, "approve destructive actions instead of declining them")
+	flag.IntVar(&opts.MaxSteps, "max-steps", 20, "maximum number of steps for tasks without max_steps")
+	flag.DurationVar(&opts.StepDelay, "step-delay", opts.StepDelay, "pause between steps (0 = none)")
+	flag.DurationVar(&opts.StepTimeout, "step-timeout", opts.StepTimeout, "timeout for a single step (0 = none)")
+	flag.BoolVar(&opts.DisableScreenshots, "no-screenshot", opts.DisableScreenshots, "do not send screenshots to the model")
+	flag.IntVar(&opts.MaxRecoveries, "max-recoveries", opts.MaxRecoveries, "relaunch a crashed browser at most this many times per run (0 = never)")
+	backend := flag.String("backend", string(browser.BackendChromedp), "browser backend: chromedp (Chrome over DevTools) or playwright")
+	flag.StringVar(&browserOpts.Engine, "engine", "", "with -backend playwright: chromium (default), firefox or webkit")
+	headless := flag.String("headless", "on", "run Chrome without a window: off, on or new")
+	flag.StringVar(&browserOpts.ExecPath, "chrome", "", "Chrome binary (default: found on the system)")
+	flag.BoolVar(&browserOpts.NoSandbox, "no-sandbox", false, "disable the Chrome sandbox (needed as root and in most containers)")
+	flag.StringVar(&browserOpts.ProfileTemplate, "profile-template", "", "start every task from a copy of this profile directory (e.g. a logged-in profile)")
+	flag.StringVar(&browserOpts.Emulation.Device, "device", "", "emulate a device preset, e.g. 'iPhone 13' or 'Pixel 5' ('list' prints them)")
+	flag.StringVar(&browserOpts.Emulation.Locale, "locale", "", "browser locale and Accept-Language, e.g. ru-RU or tr-TR")
+	flag.StringVar(&browserOpts.Emulation.Timezone, "timezone", "", "time zone, e.g. Europe/Istanbul")
+	geo := flag.String("geo", "", "geolocation reported to pages, 'latitude,longitude[,accuracy]'")
+	storageState := flag.String("storage-state", "", "load cookies and localStorage saved by agent-cli -save-storage-state into every task's browser")
+	var policyFlags cliflags.Policy
+	var blockTypes, blockURLs, proxyBypass cliflags.List
+	var httpAuth cliflags.Credentials
+	var headers cliflags.Headers
+	policyFlags.Register(flag.CommandLine)
+	flag.Var(&blockTypes, "block", "do not load these resource types, e.g. image,font,media (repeatable, comma-separated)")
+	flag.Var(&blockURLs, "block-url", "do not load URLs matching this pattern, * and ? wildcards (repeatable, comma-separated)")
+	flag.BoolVar(&browserOpts.Block.Trackers, "block-trackers", false, "block the built-in list of ad and analytics hosts")
+	flag.StringVar(&browserOpts.Proxy.Server, "proxy", "", "proxy server, http://[user:password@]host:port or socks5://host:port")
+	flag.Var(&proxyBypass, "proxy-bypass", "hosts reached without the proxy, e.g. *.corp.local,<local> (repeatable, comma-separated)")
+	flag.StringVar(&browserOpts.Proxy.Username, "proxy-user", "", "proxy user name; the password is read from -proxy-password-env")
+	proxyPasswordEnv := flag.String("proxy-password-env", "PROXY_PASSWORD", "environment variable holding the proxy password")
+	flag.Var(&httpAuth, "http-auth", "log in to a site with HTTP authentication, 'site=user:password' (repeatable)")
+	flag.Var(&browserOpts.Flags, "chrome-flag", "extra Chrome switch, 'name' or 'name=value' (repeatable)")
+	providerName := flag.String("provider", string(llm.ProviderOpenAI), "LLM provider: openai, anthropic, ollama, vllm, llamacpp")
+	flag.StringVar(&provider.BaseURL, "base-url", "", "LLM API base URL")
+	flag.StringVar(&provider.Model, "model", "", "model name (default depends on the provider)")
+	flag.StringVar(&provider.APIKeyEnv, "api-key-env", "", "environment variable holding the API key")
+	flag.BoolVar(&provider.PlainJSON, "plain-json", false, "request plain JSON instead of schema-constrained output")
+	flag.Var(&headers, "header", "extra HTTP header for LLM requests, 'Name: value' (repeatable)")
+	flag.IntVar(&opts.TokenBudget, "token-budget", 0, "token budget per task (0 = unlimited)")
+	flag.Float64Var(&opts.CostBudget, "cost-budget", 0, "cost budget per task in USD (0 = unlimited)")
+	recordPath := flag.String("record", "", "record LLM calls to this cassette file")
+	replayPath := flag.String("replay", "", "replay LLM calls from this cassette file")
+	pricesPath := flag.String("prices", "", "JSON file with per-model prices, merged over the built-in table")
+	flag.Parse()
+
+	// A zero in Options means the default; on the command line it means none.
+	if opts.StepDelay == 0 {
+		opts.StepDelay = -1
+	}
+	if opts.MaxRecoveries == 0 {
+		opts.MaxRecoveries = -1
+	}
+
+	if browserOpts.Emulation.Device == "list" {
+		fmt.Println(strings.Join(browser.DeviceNames(), "\n"))
+		return
+	}
+
+	if *suitePath == "" {
+		log.Fatal("-suite is required")
+	}
+	suite, err := eval.LoadSuite(*suitePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var baseline *eval.Report
+	if *baselinePath != "" {
+		if baseline, err = eval.LoadReport(*baselinePath); err != nil {
+			log.Fatalf("baseline: %v", err)
+		}
+	}
+
+	browserOpts.Backend = browser.Backend(*backend)
+	if browserOpts.Backend == browser.BackendPlaywright {
+		// Playwright starts every browser from a fresh context already.
+		browserOpts.Ephemeral = false
+	}
+	if browserOpts.Headless, err = browser.ParseHeadless(*headless); err != nil {
+		log.Fatal(err)
+	}
+	browserOpts.Policy = policyFlags.URLPolicy()
+	if err := browserOpts.Policy.Validate(); err != nil {
+		log.Fatalf("URL policy: %v", err)
+	}
+	browserOpts.Block.ResourceTypes = blockTypes
+	browserOpts.Block.Patterns = blockURLs
+	browserOpts.Proxy.Bypass = proxyBypass
+	if browserOpts.Proxy.Username != "" {
+		browserOpts.Proxy.Password = os.Getenv(*proxyPasswordEnv)
+	}
+	browserOpts.Credentials = httpAuth
+	if *geo != "" {
+		if browserOpts.Emulation.Geolocation, err = browser.ParseGeolocation(*geo); err != nil {
+			log.Fatal(err)
+		}
+	}
+	provider.Provider = llm.Provider(*providerName)
+	provider.Headers = headers
+	if *pricesPath != "" {
+		if opts.Prices, err = llm.LoadPrices(*pricesPath); err != nil {
+			log.Fatalf("prices: %v", err)
+		}
+	}
+	if !*verbose {
+		opts.Observers = []agent.Observer{}
+	}
+	opts.Approve = func(context.Context, llm.Action) bool { return *approve }
+
+	if *serveFixture {
+		site := fixture.NewSite()
+		defer site.Close()
+		*siteURL = site.URL("/")
+	}
+
+	var (
+		llmClient llm.Client
+		recorder  *llm.Recorder
+	)
+	if *replayPath != "" {
+		llmClient, err = llm.NewReplayerFromFile(*replayPath)
+	} else {
+		llmClient, err = llm.NewClient(provider)
+		if err == nil && *recordPath != "" {
+			recorder = llm.NewRecorder(llmClient, *recordPath)
+			llmClient = recorder
+		}
+	}
+	if err != nil {
+		log.Fatalf("Failed to initialize LLM client: %v", err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	report, err := eval.Run(ctx, suite, eval.Config{
+		Client:  llmClient,
+		Options: opts,
+		NewBrowser: func() (browser.Browser, error) {
+			bm, err := browser.Open(browserOpts)
+			if err != nil || *storageState == "" {
+				return bm, err
+			}
+			if err := bm.ImportStorageState(ctx, *storageState); err != nil {
+				bm.Close()
+				return nil, fmt.Errorf("load storage state: %w", err)
+			}
+			return bm, nil
+		},
+		BaseURL: *siteURL,
+		OnTask: func(res eval.TaskResult) {
+			fmt.Printf("%-4s %s (%d steps, %s)\n", status(res.Success), res.Name, res.Steps, res.Duration.Round(100*time.Millisecond))
+		},
+	})
+	if err != nil {
+		log.Printf("Evaluation interrupted: %v", err)
+	}
+
+	if recorder != nil {
+		if err := recorder.Save(); err != nil {
+			log.Printf("Failed to save cassette: %v", err)
+		}
+	}
+	if *outPath != "" {
+		if err := report.Save(*outPath); err != nil {
+			log.Printf("Failed to save report: %v", err)
+		}
+	}
+
+	fmt.Println()
+	_ = report.WriteText(os.Stdout)
+
+	if baseline != nil {
+		diff := eval.Compare(baseline, report)
+		fmt.Printf("\nCompared with %s:\n", *baselinePath)
+		_ = diff.WriteText(os.Stdout)
+		if len(diff.Regressions()) > 0 {
+			os.Exit(1)
+		}
+	}
+}
+
+func status(ok bool) string {
+	if ok {
+		return "ok"
+	}
+	return "FAIL"
+}
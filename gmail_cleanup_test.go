@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
 	"testing"
 	"time"
 
@@ -16,6 +18,9 @@ func TestGmailCleanup(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping Gmail e2e test in short mode")
 	}
+	if os.Getenv("OPENAI_API_KEY") == "" {
+		t.Skip("OPENAI_API_KEY is not set, skipping e2e test")
+	}
 
 	startURL := "https://mail.google.com/"
 	task := "Перейди в мой аккаунт Gmail, открой папку «Входящие», прочитай последние 10 писем (тема, отправитель, краткое содержание), проанализируй каждое письмо на спам, рекламные ссылки, подозрительных отправителей и фишинг. Все письма, которые ты считаешь спамом или фишингом, пометь как спам или удали, а в конце сделай краткий отчёт о всех своих действиях."
@@ -24,7 +29,10 @@ func TestGmailCleanup(t *testing.T) {
 	log.Printf("🌍 Navigating to %s ...", startURL)
 
 	// Браузер-менеджер (Chromedp, тот же, что и для других e2e)
-	b := browser.NewManager()
+	b, err := browser.NewManager(browser.DefaultOptions())
+	if err != nil {
+		t.Fatalf("Failed to launch browser: %v", err)
+	}
 	defer b.Close()
 
 	// Навигация на Gmail перед запуском агента
@@ -47,7 +55,7 @@ func TestGmailCleanup(t *testing.T) {
 
 	const maxSteps = 50
 
-	if err := ag.Run(task, maxSteps); err != nil {
+	if err := ag.Run(context.Background(), task, maxSteps); err != nil {
 		t.Fatalf("Agent finished with error: %v", err)
 	}
 
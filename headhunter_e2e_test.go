@@ -1,7 +1,9 @@
 package main
 
 import (
+	"context"
 	"log"
+	"os"
 	"testing"
 	"time"
 
@@ -16,6 +18,9 @@ func TestHeadHunterApply(t *testing.T) {
 	if testing.Short() {
 		t.Skip("skipping HeadHunter e2e test in short mode")
 	}
+	if os.Getenv("OPENAI_API_KEY") == "" {
+		t.Skip("OPENAI_API_KEY is not set, skipping e2e test")
+	}
 
 	startURL := "https://hh.ru/"
 	task := "Изучи моё резюме на hh.ru и на основе моих навыков откликнись на четыре подходящие вакансии."
@@ -24,7 +29,10 @@ func TestHeadHunterApply(t *testing.T) {
 	log.Printf("🌍 Navigating to %s ...", startURL)
 
 	// Браузер-менеджер (Chromedp, тот же, что и для Getir)
-	b := browser.NewManager()
+	b, err := browser.NewManager(browser.DefaultOptions())
+	if err != nil {
+		t.Fatalf("Failed to launch browser: %v", err)
+	}
 	defer b.Close()
 
 	// Навигация на hh.ru перед запуском агента
@@ -47,7 +55,7 @@ func TestHeadHunterApply(t *testing.T) {
 
 	const maxSteps = 40
 
-	if err := ag.Run(task, maxSteps); err != nil {
+	if err := ag.Run(context.Background(), task, maxSteps); err != nil {
 		t.Fatalf("Agent finished with error: %v", err)
 	}
 
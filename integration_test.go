@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
 	"testing"
@@ -24,7 +25,10 @@ func TestGetir(t *testing.T) {
 
 	log.Println("🚀 STARTING AUTOMATED TEST...")
 
-	b := browser.NewManager()
+	b, err := browser.NewManager(browser.DefaultOptions())
+	if err != nil {
+		t.Fatalf("Failed to launch browser: %v", err)
+	}
 	defer b.Close()
 
 	log.Printf("Navigating to %s...", targetURL)
@@ -46,7 +50,7 @@ func TestGetir(t *testing.T) {
 	aiAgent := agent.NewAgent(b, l)
 
 	log.Printf("🤖 AGENT STARTED with task: '%s'", userTask)
-	err = aiAgent.Run(userTask, maxSteps)
+	err = aiAgent.Run(context.Background(), userTask, maxSteps)
 
 	if err != nil {
 		t.Errorf("Agent finished with error: %v", err)
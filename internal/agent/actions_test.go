@@ -0,0 +1,411 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/browser"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/fixture"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/llm"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/llm/llmtest"
+)
+
+// actionTester drives executeAction against the fixture site the way the
+// runner does: snapshot, pick an element by name, act.
+type actionTester struct {
+	t     *testing.T
+	site  *fixture.Site
+	b     *browser.Manager
+	agent *Agent
+}
+
+func newActionTester(t *testing.T) *actionTester {
+	b := fixture.NewBrowser(t)
+	site := fixture.NewSite()
+	t.Cleanup(site.Close)
+	return &actionTester{t: t, site: site, b: b, agent: NewAgentWithOptions(b, nil, testOptions())}
+}
+
+func (at *actionTester) open(path string) {
+	at.t.Helper()
+	if err := at.b.Navigate(context.Background(), at.site.URL(path)); err != nil {
+		at.t.Fatalf("open %s: %v", path, err)
+	}
+}
+
+func (at *actionTester) snapshot() *browser.PageSnapshot {
+	at.t.Helper()
+	snap, err := at.b.Snapshot(context.Background(), 1, false)
+	if err != nil {
+		at.t.Fatal(err)
+	}
+	return snap
+}
+
+// do executes action on the first element whose name matches pattern.
+func (at *actionTester) do(pattern string, action llm.Action) {
+	at.t.Helper()
+	snap := at.snapshot()
+	el, ok := llmtest.FindElement(snap.Tree, pattern)
+	if !ok {
+		at.t.Fatalf("no element matching %q on %s:\n%s", pattern, snap.URL, snap.Tree)
+	}
+	action.TargetID = el.ID
+	if err := at.agent.executeAction(context.Background(), action, snap); err != nil {
+		at.t.Fatalf("%s %q: %v", action.Type, el.Name, err)
+	}
+}
+
+func (at *actionTester) click(pattern string) {
+	at.t.Helper()
+	at.do(pattern, llm.Action{Type: llm.ActionClick})
+}
+
+func (at *actionTester) typeInto(pattern, text string, submit bool) {
+	at.t.Helper()
+	at.do(pattern, llm.Action{Type: llm.ActionTypeInput, Text: text, Submit: submit})
+}
+
+// eval evaluates a JavaScript expression in the page.
+func (at *actionTester) eval(expr string, out any) {
+	at.t.Helper()
+	ctx, cancel := at.b.Bind(context.Background())
+	defer cancel()
+	if err := chromedp.Run(ctx, chromedp.Evaluate(expr, out)); err != nil {
+		at.t.Fatalf("evaluate %s: %v", expr, err)
+	}
+}
+
+// waitFor polls the JavaScript condition until it is true.
+func (at *actionTester) waitFor(cond string) {
+	at.t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		var ok bool
+		ctx, cancel := at.b.Bind(context.Background())
+		// Evaluation fails while a navigation replaces the document.
+		err := chromedp.Run(ctx, chromedp.Evaluate(cond, &ok))
+		cancel()
+		if err == nil && ok {
+			return
+		}
+		if time.Now().After(deadline) {
+			at.t.Fatalf("timed out waiting for %s (last error: %v)\n%s", cond, err, at.snapshot().Tree)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+func (at *actionTester) waitTitle(title string) {
+	at.t.Helper()
+	at.waitFor("document.readyState === 'complete' && document.title === " + jsString(title))
+}
+
+func jsString(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `\'`) + "'"
+}
+
+func TestActionLogin(t *testing.T) {
+	at := newActionTester(t)
+	at.open("/login")
+
+	at.typeInto("^Username", fixture.Username, false)
+	at.typeInto("^Password", "wrong", true)
+	at.waitFor("document.querySelector('[role=alert]') !== null")
+
+	at.typeInto("^Username", fixture.Username, false)
+	at.typeInto("^Password", fixture.Password, true)
+	at.waitTitle("Your account")
+
+	if tree := at.snapshot().Tree; !strings.Contains(tree, `"Welcome, demo"`) {
+		t.Errorf("account page does not greet the user:\n%s", tree)
+	}
+}
+
+func TestActionMultiStepForm(t *testing.T) {
+	at := newActionTester(t)
+	at.open("/form/1")
+
+	at.typeInto("^Full name", "Ada Lovelace", true)
+	at.waitTitle("Sign up: step 2 of 3")
+
+	at.typeInto("^Email", "ada@example.test", false)
+	at.click("Pro$")
+	at.click("^Next$")
+	at.waitTitle("Sign up: step 3 of 3")
+
+	at.click("accept the terms")
+	at.click("^Create account$")
+	at.waitTitle("Account created")
+
+	subs := at.site.Submissions()
+	if len(subs) != 1 {
+		t.Fatalf("submissions = %v", subs)
+	}
+	got := subs[0]
+	if got.Get("name") != "Ada Lovelace" || got.Get("email") != "ada@example.test" ||
+		got.Get("plan") != "pro" || got.Get("terms") != "yes" {
+		t.Errorf("submission = %v", got)
+	}
+}
+
+func TestActionModal(t *testing.T) {
+	at := newActionTester(t)
+	at.open("/modal")
+
+	at.click("^Subscribe$")
+	at.waitFor("document.getElementById('overlay').classList.contains('open')")
+
+	snap := at.snapshot()
+	if _, ok := llmtest.FindElement(snap.Tree, "^Confirm subscription$"); !ok {
+		t.Fatalf("open dialog not in snapshot:\n%s", snap.Tree)
+	}
+
+	at.typeInto("^Email address", "ada@example.test", false)
+	at.click("^Confirm subscription$")
+	at.waitFor("document.getElementById('status').textContent === 'Subscribed as ada@example.test'")
+}
+
+func TestActionCartAndCheckout(t *testing.T) {
+	at := newActionTester(t)
+	at.open("/shop")
+
+	at.click("^Add Margherita pizza to cart$")
+	at.waitFor("document.body.innerText.includes('Cart (1)')")
+	at.click("^Add Cola to cart$")
+	at.waitFor("document.body.innerText.includes('Cart (2)')")
+
+	at.click(`^Cart \(2\)$`)
+	at.waitTitle("Cart")
+	at.click("^Proceed to checkout$")
+	at.waitTitle("Checkout")
+
+	if len(at.site.Payments()) != 0 {
+		t.Fatal("paid before clicking Pay now")
+	}
+	at.click("^Pay now")
+	at.waitTitle("Payment received")
+
+	payments := at.site.Payments()
+	if len(payments) != 1 || payments[0].Total != 11 || len(payments[0].Items) != 2 {
+		t.Errorf("payments = %+v", payments)
+	}
+}
+
+func TestActionTypeEscaping(t *testing.T) {
+	at := newActionTester(t)
+	at.open("/shop")
+
+	text := `it's "quoted" \ and ${not} a template` + "\nnewline"
+	at.typeInto("^Search products", text, false)
+
+	var got string
+	at.eval("document.getElementById('search').value", &got)
+	// A search field drops line breaks.
+	if want := strings.ReplaceAll(text, "\n", ""); got != want {
+		t.Errorf("value = %q, want %q", got, want)
+	}
+}
+
+func TestActionScrollLoadsMore(t *testing.T) {
+	at := newActionTester(t)
+	at.open("/feed")
+
+	before := len(at.snapshot().Elements)
+	if before != 20 {
+		t.Fatalf("feed starts with %d posts, want 20", before)
+	}
+
+	if err := at.agent.executeAction(context.Background(), llm.Action{Type: llm.ActionScroll}, at.snapshot()); err != nil {
+		t.Fatal(err)
+	}
+	at.waitFor("document.querySelectorAll('#feed li').length > 20")
+
+	if _, ok := llmtest.FindElement(at.snapshot().Tree, "^Post 21$"); !ok {
+		t.Error("loaded posts not in snapshot")
+	}
+}
+
+func TestActionNewTabLink(t *testing.T) {
+	at := newActionTester(t)
+	at.open("/tabs")
+
+	at.click("^Open help in a new tab$")
+
+	// The agent keeps working in its own tab; the help page opens in
+	// another target.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		targets, err := chromedp.Targets(at.b.Ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var opened bool
+		for _, target := range targets {
+			if target.Type == "page" && target.URL == at.site.URL("/help") {
+				opened = true
+			}
+		}
+		if opened {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("help page not opened in a new tab; targets: %+v", targets)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+
+	if snap := at.snapshot(); snap.URL != at.site.URL("/tabs") {
+		t.Errorf("agent tab navigated to %s", snap.URL)
+	}
+}
+
+func TestActionUnknownTarget(t *testing.T) {
+	at := newActionTester(t)
+	at.open("/")
+
+	err := at.agent.executeAction(context.Background(), llm.Action{Type: llm.ActionClick, TargetID: 99}, at.snapshot())
+	if err == nil || !strings.Contains(err.Error(), "TargetID 99") {
+		t.Errorf("err = %v", err)
+	}
+}
+
+// TestRunnerCheckoutApproval runs the agent through checkout and checks
+// that the pay button is only pressed when the user approves.
+func TestRunnerCheckoutApproval(t *testing.T) {
+	for _, approve := range []bool{false, true} {
+		b := fixture.NewBrowser(t)
+		site := fixture.NewSite()
+		defer site.Close()
+
+		cart := []string{fixture.Products[0].Name}
+		if err := b.Navigate(context.Background(), site.URL("/cart")); err != nil {
+			t.Fatal(err)
+		}
+		setCartCookie(t, b, site, cart)
+		if err := b.Navigate(context.Background(), site.URL("/checkout")); err != nil {
+			t.Fatal(err)
+		}
+
+		fake := llmtest.NewScripted(
+			func(in llm.DecisionInput) (*llm.DecisionOutput, error) {
+				el, ok := llmtest.FindElement(in.DOMTree, "^Pay now")
+				if !ok {
+					t.Errorf("no pay button:\n%s", in.DOMTree)
+				}
+				return &llm.DecisionOutput{Action: llm.Action{
+					Type: llm.ActionClick, TargetID: el.ID, IsDestructive: true, DestructiveReason: "payment",
+				}}, nil
+			},
+			llmtest.Finish("done"),
+		)
+
+		opts := testOptions()
+		var asked bool
+		opts.Approve = func(context.Context, llm.Action) bool {
+			asked = true
+			return approve
+		}
+		opts.StepDelay = 500 * time.Millisecond
+
+		res, err := NewAgentWithOptions(b, fake, opts).RunWithResult(context.Background(), "pay for the order", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !asked {
+			t.Error("approval was not requested")
+		}
+
+		paid := len(site.Payments())
+		if approve && (paid != 1 || res.Steps[0].Outcome != OutcomeExecuted) {
+			t.Errorf("approved: payments=%d outcome=%s", paid, res.Steps[0].Outcome)
+		}
+		if !approve && (paid != 0 || res.Steps[0].Outcome != OutcomeDeclined) {
+			t.Errorf("declined: payments=%d outcome=%s", paid, res.Steps[0].Outcome)
+		}
+	}
+}
+
+func setCartCookie(t *testing.T, b *browser.Manager, site *fixture.Site, items []string) {
+	t.Helper()
+	ctx, cancel := b.Bind(context.Background())
+	defer cancel()
+	expr := "document.cookie = 'cart=' + encodeURIComponent(" + jsString(strings.Join(items, "|")) + ") + '; path=/'"
+	if err := chromedp.Run(ctx, chromedp.Evaluate(expr, nil)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunnerBlockedNavigationNote(t *testing.T) {
+	at := newActionTester(t)
+	foreign := strings.Replace(at.site.URL("/help"), "127.0.0.1", "localhost", 1)
+	if err := at.b.SetURLPolicy(context.Background(), browser.URLPolicy{Allow: []string{"127.0.0.1"}}); err != nil {
+		t.Fatal(err)
+	}
+	at.open("/shop")
+	at.eval(`document.body.insertAdjacentHTML("beforeend", '<a href="`+foreign+`">Partner offers</a>')`, nil)
+
+	var history string
+	fake := llmtest.NewScripted(
+		llmtest.ClickMatching("^Partner offers"),
+		func(in llm.DecisionInput) (*llm.DecisionOutput, error) {
+			history = in.History
+			return llmtest.Finish("stayed")(in)
+		},
+	)
+	opts := testOptions()
+	opts.StepDelay = 500 * time.Millisecond
+	if _, err := NewAgentWithOptions(at.b, fake, opts).RunWithResult(context.Background(), "look at partner offers", 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(history, "navigation to "+foreign+" was blocked") {
+		t.Errorf("blocked navigation not in history:\n%s", history)
+	}
+	if url, _ := at.b.Location(context.Background()); url != at.site.URL("/shop") {
+		t.Errorf("location = %s, want the shop", url)
+	}
+}
+
+func TestRunnerRecoversCrashedBrowser(t *testing.T) {
+	at := newActionTester(t)
+	at.open("/shop")
+	crash := func(in llm.DecisionInput) (*llm.DecisionOutput, error) {
+		// Crash gets no reply: the renderer that would send it is gone.
+		ctx, cancel := at.b.WithTimeout(time.Second)
+		defer cancel()
+		_ = chromedp.Run(ctx, page.Crash())
+		return llmtest.Scroll()(in)
+	}
+
+	var history string
+	fake := llmtest.NewScripted(
+		crash,
+		func(in llm.DecisionInput) (*llm.DecisionOutput, error) {
+			history = in.History
+			return llmtest.Finish("done")(in)
+		},
+	)
+	res, err := NewAgentWithOptions(at.b, fake, testOptions()).RunWithResult(context.Background(), "look around", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Recoveries != 1 || !strings.Contains(history, "the page "+at.site.URL("/shop")+" was reloaded") {
+		t.Errorf("recoveries = %d, history:\n%s", res.Recoveries, history)
+	}
+
+	// Without recoveries left the run ends instead of failing every step.
+	opts := testOptions()
+	opts.MaxRecoveries = -1
+	res, err = NewAgentWithOptions(at.b, llmtest.NewScripted(crash, llmtest.Finish("done")), opts).
+		RunWithResult(context.Background(), "look around", 0)
+	if !errors.Is(err, browser.ErrBrowserCrashed) || res.ExitReason != ExitCrashed {
+		t.Errorf("exit = %s, err = %v; want ExitCrashed", res.ExitReason, err)
+	}
+}
@@ -1,20 +1,44 @@
 package agent
 
 import (
+	"context"
+
 	"github.com/nbenliogludev/go-browser-ai-agent/internal/browser"
 	"github.com/nbenliogludev/go-browser-ai-agent/internal/llm"
 )
 
 type Agent struct {
-	browser *browser.Manager
+	browser browser.Driver
 	llm     llm.Client
+	opts    Options
+}
+
+func NewAgent(b browser.Driver, c llm.Client) *Agent {
+	return NewAgentWithOptions(b, c, DefaultOptions())
+}
+
+func NewAgentWithOptions(b browser.Driver, c llm.Client, opts Options) *Agent {
+	return &Agent{browser: b, llm: c, opts: opts.withDefaults()}
 }
 
-func NewAgent(b *browser.Manager, c llm.Client) *Agent {
-	return &Agent{browser: b, llm: c}
+func (a *Agent) Options() Options {
+	return a.opts
 }
 
-func (a *Agent) Run(task string, maxSteps int) error {
+func (a *Agent) Run(ctx context.Context, task string, maxSteps int) error {
+	_, err := a.RunWithResult(ctx, task, maxSteps)
+	return err
+}
+
+func (a *Agent) RunWithResult(ctx context.Context, task string, maxSteps int) (*RunResult, error) {
 	runner := NewRunner(a, task, maxSteps)
-	return runner.Run()
+	return runner.Run(ctx)
+}
+
+func (a *Agent) Ask(ctx context.Context, task string, maxSteps int) (*FinalAnswer, error) {
+	res, err := a.RunWithResult(ctx, task, maxSteps)
+	if err != nil {
+		return nil, err
+	}
+	return res.Answer, nil
 }
@@ -0,0 +1,33 @@
+package agent
+
+import (
+	"encoding/json"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/llm"
+)
+
+type FinalAnswer struct {
+	Answer   string           `json:"answer"`
+	Success  llm.FinishStatus `json:"success"`
+	Evidence []string         `json:"evidence,omitempty"`
+	Data     json.RawMessage  `json:"data,omitempty"`
+}
+
+func newFinalAnswer(action llm.Action) *FinalAnswer {
+	status := action.Success
+	if status == "" {
+		status = llm.FinishSuccess
+	}
+
+	var evidence []string
+	if len(action.Evidence) > 0 {
+		evidence = append(evidence, action.Evidence...)
+	}
+
+	return &FinalAnswer{
+		Answer:   action.Answer,
+		Success:  status,
+		Evidence: evidence,
+		Data:     action.Data,
+	}
+}
@@ -0,0 +1,40 @@
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/llm"
+)
+
+func (a *Agent) RunExtract(ctx context.Context, task string, schema json.RawMessage, maxSteps int) (json.RawMessage, error) {
+	res, err := a.RunWithSchema(ctx, task, schema, maxSteps)
+	if err != nil {
+		return nil, err
+	}
+	return res.Answer.Data, nil
+}
+
+func (a *Agent) RunWithSchema(ctx context.Context, task string, schema json.RawMessage, maxSteps int) (*RunResult, error) {
+	if err := llm.ValidateSchema(schema); err != nil {
+		return nil, err
+	}
+
+	runner := NewRunner(a, task, maxSteps)
+	runner.schema = schema
+	return runner.Run(ctx)
+}
+
+func Extract[T any](ctx context.Context, a *Agent, task string, schema json.RawMessage, maxSteps int) (T, error) {
+	var out T
+
+	raw, err := a.RunExtract(ctx, task, schema, maxSteps)
+	if err != nil {
+		return out, err
+	}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return out, fmt.Errorf("decode extracted data: %w", err)
+	}
+	return out, nil
+}
@@ -17,27 +17,36 @@ type StepMemory struct {
 	repeatCount   int
 	loopThreshold int
 
-	recentKeys    []string
-	maxRecent     int
-	patternLen    int
-	patternCounts map[string]int
+	recentKeys     []string
+	maxRecent      int
+	patternLen     int
+	patternRepeats int
+	patternCounts  map[string]int
 
 	loopTriggered bool
 }
 
-func NewStepMemory(maxLines, loopThreshold int) *StepMemory {
+func NewStepMemory(maxLines, loopThreshold, patternLen, patternRepeats int) *StepMemory {
 	if maxLines <= 0 {
 		maxLines = 5
 	}
 	if loopThreshold <= 1 {
 		loopThreshold = 2
 	}
+	if patternRepeats <= 0 {
+		patternRepeats = 1
+	}
+	maxRecent := 10
+	if patternLen > maxRecent {
+		maxRecent = patternLen
+	}
 	return &StepMemory{
-		maxLines:      maxLines,
-		loopThreshold: loopThreshold,
-		maxRecent:     10,
-		patternLen:    2,
-		patternCounts: make(map[string]int),
+		maxLines:       maxLines,
+		loopThreshold:  loopThreshold,
+		maxRecent:      maxRecent,
+		patternLen:     patternLen,
+		patternRepeats: patternRepeats,
+		patternCounts:  make(map[string]int),
 	}
 }
 
@@ -102,7 +111,7 @@ func (m *StepMemory) ShouldBlock(url string, action llm.Action) (bool, string) {
 
 		if len(seq) == m.patternLen {
 			pattern := strings.Join(seq, "->")
-			if count, ok := m.patternCounts[pattern]; ok && count >= 1 {
+			if count, ok := m.patternCounts[pattern]; ok && count >= m.patternRepeats {
 				reason := fmt.Sprintf(
 					"SYSTEM NOTE: The sequence of %d actions (%s) has already occurred before. "+
 						"Do NOT repeat this pattern. Try a different action (for example, moving to the next stage of the flow or finishing).",
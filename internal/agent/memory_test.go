@@ -0,0 +1,63 @@
+package agent
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/llm"
+)
+
+func TestStepMemoryBlocksRepeats(t *testing.T) {
+	m := NewStepMemory(10, 3, 1, 1)
+	click := llm.Action{Type: llm.ActionClick, TargetID: 4}
+
+	for i := 1; i <= 3; i++ {
+		if blocked, _ := m.ShouldBlock("/a", click); blocked {
+			t.Fatalf("blocked after %d repeats, threshold is 3", i-1)
+		}
+		m.Add(i, "/a", click)
+	}
+	if blocked, reason := m.ShouldBlock("/a", click); !blocked || !strings.Contains(reason, "3 times") {
+		t.Errorf("blocked=%v reason=%q", blocked, reason)
+	}
+
+	if blocked, _ := m.ShouldBlock("/b", click); blocked {
+		t.Error("same action on another URL was blocked")
+	}
+	if blocked, _ := m.ShouldBlock("/a", llm.Action{Type: llm.ActionClick, TargetID: 5}); blocked {
+		t.Error("different target was blocked")
+	}
+}
+
+func TestStepMemoryBlocksPatterns(t *testing.T) {
+	m := NewStepMemory(10, 5, 2, 1)
+	a := llm.Action{Type: llm.ActionClick, TargetID: 1}
+	b := llm.Action{Type: llm.ActionClick, TargetID: 2}
+
+	m.Add(1, "/", a)
+	m.Add(2, "/", b)
+	m.Add(3, "/", a)
+
+	// a->b already happened once.
+	if blocked, reason := m.ShouldBlock("/", b); !blocked || !strings.Contains(reason, "sequence of 2 actions") {
+		t.Errorf("blocked=%v reason=%q", blocked, reason)
+	}
+	if blocked, _ := m.ShouldBlock("/", llm.Action{Type: llm.ActionScroll}); blocked {
+		t.Error("new sequence was blocked")
+	}
+}
+
+func TestStepMemoryHistoryWindow(t *testing.T) {
+	m := NewStepMemory(2, 3, 1, 1)
+	m.Add(1, "/", llm.Action{Type: llm.ActionScroll})
+	m.AddSystemNote("  ")
+	m.AddSystemNote("SYSTEM NOTE: one")
+	m.AddSystemNote("SYSTEM NOTE: two")
+
+	if got := m.HistoryLines(); len(got) != 2 || got[0] != "SYSTEM NOTE: one" {
+		t.Errorf("history = %q", got)
+	}
+	if got := m.FullHistory(); len(got) != 3 || !strings.HasPrefix(got[0], "step=1") {
+		t.Errorf("full history = %q", got)
+	}
+}
@@ -0,0 +1,80 @@
+package agent
+
+import (
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/browser"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/llm"
+)
+
+type Observer interface {
+	OnStepStart(step int)
+	OnSnapshot(step int, snap *browser.PageSnapshot)
+	OnDecision(step int, url string, d *llm.DecisionOutput)
+	OnLoopBlocked(step int, action llm.Action, reason string)
+	OnApprovalRequested(step int, action llm.Action)
+	OnActionResult(step int, action llm.Action, err error)
+	OnStepEnd(rec StepRecord)
+	OnFinish(res *RunResult)
+}
+
+// NopObserver implements Observer with no-op methods; embed it to
+// subscribe to a subset of events.
+type NopObserver struct{}
+
+func (NopObserver) OnStepStart(int)                             {}
+func (NopObserver) OnSnapshot(int, *browser.PageSnapshot)       {}
+func (NopObserver) OnDecision(int, string, *llm.DecisionOutput) {}
+func (NopObserver) OnLoopBlocked(int, llm.Action, string)       {}
+func (NopObserver) OnApprovalRequested(int, llm.Action)         {}
+func (NopObserver) OnActionResult(int, llm.Action, error)       {}
+func (NopObserver) OnStepEnd(StepRecord)                        {}
+func (NopObserver) OnFinish(*RunResult)                         {}
+
+type multiObserver []Observer
+
+func (m multiObserver) OnStepStart(step int) {
+	for _, o := range m {
+		o.OnStepStart(step)
+	}
+}
+
+func (m multiObserver) OnSnapshot(step int, snap *browser.PageSnapshot) {
+	for _, o := range m {
+		o.OnSnapshot(step, snap)
+	}
+}
+
+func (m multiObserver) OnDecision(step int, url string, d *llm.DecisionOutput) {
+	for _, o := range m {
+		o.OnDecision(step, url, d)
+	}
+}
+
+func (m multiObserver) OnLoopBlocked(step int, action llm.Action, reason string) {
+	for _, o := range m {
+		o.OnLoopBlocked(step, action, reason)
+	}
+}
+
+func (m multiObserver) OnApprovalRequested(step int, action llm.Action) {
+	for _, o := range m {
+		o.OnApprovalRequested(step, action)
+	}
+}
+
+func (m multiObserver) OnActionResult(step int, action llm.Action, err error) {
+	for _, o := range m {
+		o.OnActionResult(step, action, err)
+	}
+}
+
+func (m multiObserver) OnStepEnd(rec StepRecord) {
+	for _, o := range m {
+		o.OnStepEnd(rec)
+	}
+}
+
+func (m multiObserver) OnFinish(res *RunResult) {
+	for _, o := range m {
+		o.OnFinish(res)
+	}
+}
@@ -0,0 +1,92 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"time"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/llm"
+)
+
+// Options tunes a run. A zero field means its value in DefaultOptions;
+// fields that can be turned off take a negative value (StepDelay,
+// StepTimeout, MaxRecoveries) or an empty non-nil slice (Observers) for
+// none.
+type Options struct {
+	MaxSteps int
+
+	HistoryWindow  int
+	LoopThreshold  int
+	PatternLength  int
+	PatternRepeats int
+
+	StepDelay   time.Duration
+	StepTimeout time.Duration
+
+	DisableScreenshots bool
+
+	// MaxRecoveries caps how often a run reopens a crashed browser before
+	// it ends with ExitCrashed.
+	MaxRecoveries int
+
+	Model llm.ModelParams
+
+	// Prices is used to compute the cost of LLM calls; nil means
+	// llm.DefaultPrices. TokenBudget and CostBudget (USD) end the run with
+	// ExitBudget once exceeded; zero disables them.
+	Prices      llm.PriceTable
+	TokenBudget int
+	CostBudget  float64
+
+	Observers []Observer
+	Approve   func(ctx context.Context, action llm.Action) bool
+}
+
+func DefaultOptions() Options {
+	return Options{
+		MaxSteps:       40,
+		HistoryWindow:  10,
+		LoopThreshold:  3,
+		PatternLength:  2,
+		PatternRepeats: 1,
+		StepDelay:      3 * time.Second,
+		MaxRecoveries:  3,
+		Observers:      []Observer{NewConsoleReporter(os.Stdout)},
+		Approve:        confirmDestructiveAction,
+	}
+}
+
+func (o Options) withDefaults() Options {
+	def := DefaultOptions()
+	if o.MaxSteps <= 0 {
+		o.MaxSteps = def.MaxSteps
+	}
+	if o.HistoryWindow <= 0 {
+		o.HistoryWindow = def.HistoryWindow
+	}
+	if o.LoopThreshold <= 0 {
+		o.LoopThreshold = def.LoopThreshold
+	}
+	if o.PatternLength <= 0 {
+		o.PatternLength = def.PatternLength
+	}
+	if o.PatternRepeats <= 0 {
+		o.PatternRepeats = def.PatternRepeats
+	}
+	if o.StepDelay == 0 {
+		o.StepDelay = def.StepDelay
+	}
+	if o.MaxRecoveries == 0 {
+		o.MaxRecoveries = def.MaxRecoveries
+	}
+	if o.Observers == nil {
+		o.Observers = def.Observers
+	}
+	if o.Prices == nil {
+		o.Prices = llm.DefaultPrices
+	}
+	if o.Approve == nil {
+		o.Approve = def.Approve
+	}
+	return o
+}
@@ -1,18 +1,59 @@
 package agent
 
-func humanizeReason(reason string) string {
+import (
+	"context"
+	"time"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/llm"
+)
+
+const summaryTimeout = 30 * time.Second
+
+func (r *Runner) summarize(ctx context.Context) string {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), summaryTimeout)
+	defer cancel()
+
+	var finalAction llm.Action
+	for i := len(r.result.Steps) - 1; i >= 0; i-- {
+		if d := r.result.Steps[i].Decision; d != nil {
+			finalAction = d.Action
+			break
+		}
+	}
+
+	out, err := r.agent.llm.SummarizeRun(ctx, llm.SummaryInput{
+		Task:        r.task,
+		ExitReason:  humanizeReason(r.result.ExitReason),
+		FinalURL:    r.result.FinalURL,
+		FinalAction: finalAction,
+		Duration:    r.result.Duration.Truncate(time.Millisecond).String(),
+		Steps:       r.mem.FullHistory(),
+		Model:       r.opts.Model.Model,
+	})
+	if err != nil || out == nil {
+		return ""
+	}
+	r.addUsage(out.Usage)
+	return out.Text
+}
+
+func humanizeReason(reason ExitReason) string {
 	switch reason {
-	case "task finished":
+	case ExitFinished:
 		return "model explicitly finished the task"
-	case "max steps reached":
+	case ExitMaxSteps:
 		return "step limit reached"
-	case "interrupted by user (Ctrl+C)":
-		return "execution was interrupted by user (Ctrl+C)"
-	case "llm error":
-		return "LLM client error"
-	case "snapshot error":
-		return "page snapshot error"
+	case ExitCancelled:
+		return "execution was cancelled (e.g. Ctrl+C)"
+	case ExitDeadline:
+		return "run deadline exceeded"
+	case ExitBudget:
+		return "token or cost budget exhausted"
+	case ExitLLMError:
+		return "LLM API error that retries cannot fix (authentication or quota)"
+	case ExitCrashed:
+		return "the browser kept crashing and could not be recovered"
 	default:
-		return reason
+		return string(reason)
 	}
 }
@@ -1,106 +1,142 @@
 package agent
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"os"
 	"strings"
 	"time"
 
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/browser"
 	"github.com/nbenliogludev/go-browser-ai-agent/internal/llm"
 )
 
-type Reporter struct {
-	llm   llm.Client
-	task  string
-	trace []string
-
-	finalAction llm.Action
-	finalURL    string
+type ConsoleReporter struct {
+	w io.Writer
 }
 
-func NewReporter(llmClient llm.Client, task string) *Reporter {
-	return &Reporter{
-		llm:  llmClient,
-		task: task,
+func NewConsoleReporter(w io.Writer) *ConsoleReporter {
+	if w == nil {
+		w = os.Stdout
 	}
+	return &ConsoleReporter{w: w}
 }
 
-func (r *Reporter) LogDecision(step int, url string, d *llm.DecisionOutput) {
-	decor := ""
-	if d.Action.IsDestructive {
-		decor = " [DESTRUCTIVE]"
-	}
-
-	fmt.Println(strings.Repeat("-", 40))
-	fmt.Printf("🧠 PHASE:       %s\n", strings.ToUpper(d.CurrentPhase))
-	fmt.Printf("👀 OBSERVATION: %s\n", d.Observation)
-	fmt.Printf("🤖 THOUGHT:     %s\n", d.Thought)
-	fmt.Printf("⚡ ACTION:      %s [%d] %q%s\n",
-		d.Action.Type,
-		d.Action.TargetID,
-		d.Action.Text,
-		decor,
-	)
-	fmt.Println(strings.Repeat("-", 40))
+func (r *ConsoleReporter) OnStepStart(step int) {
+	fmt.Fprintf(r.w, "\n--- STEP %d ---\n", step)
+}
 
-	r.finalAction = d.Action
-	r.finalURL = url
+func (r *ConsoleReporter) OnSnapshot(step int, snap *browser.PageSnapshot) {
+	fmt.Fprintf(r.w, "URL: %s\nTitle: %s\n", snap.URL, snap.Title)
+}
 
-	r.trace = append(r.trace, fmt.Sprintf(
-		"STEP %d | URL=%s | PHASE=%s | ACTION=%s[%d] %q%s | OBS=%s",
-		step,
-		url,
-		strings.ToUpper(d.CurrentPhase),
+func (r *ConsoleReporter) OnDecision(step int, url string, d *llm.DecisionOutput) {
+	fmt.Fprintln(r.w, strings.Repeat("-", 40))
+	fmt.Fprintf(r.w, "🧠 PHASE:       %s\n", strings.ToUpper(d.CurrentPhase))
+	fmt.Fprintf(r.w, "👀 OBSERVATION: %s\n", d.Observation)
+	fmt.Fprintf(r.w, "🤖 THOUGHT:     %s\n", d.Thought)
+	fmt.Fprintf(r.w, "⚡ ACTION:      %s [%d] %q%s\n",
 		d.Action.Type,
 		d.Action.TargetID,
 		d.Action.Text,
-		decor,
-		d.Observation,
-	))
+		destructiveDecor(d.Action),
+	)
+	fmt.Fprintln(r.w, strings.Repeat("-", 40))
 }
 
-func (r *Reporter) StepError(err error) {
-	fmt.Printf("⚠️ Step error: %v\n", err)
+func (r *ConsoleReporter) OnLoopBlocked(step int, action llm.Action, reason string) {
+	fmt.Fprintf(r.w, "⛔ LOOP GUARD: %s\n", reason)
 }
 
-func (r *Reporter) Finished(start time.Time, mem *StepMemory) {
-	r.printReport(start, "task finished", mem)
+func (r *ConsoleReporter) OnApprovalRequested(step int, action llm.Action) {}
+
+func (r *ConsoleReporter) OnActionResult(step int, action llm.Action, err error) {
+	switch {
+	case errors.Is(err, ErrExtractionRejected):
+		fmt.Fprintf(r.w, "🧾 EXTRACTION REJECTED: %v\n", err)
+	case errors.Is(err, ErrActionDeclined):
+		fmt.Fprintln(r.w, "🚫 Destructive action was not executed.")
+	case err != nil:
+		fmt.Fprintf(r.w, "⚠️ Action error: %v\n", err)
+	case action.Type == llm.ActionScroll:
+		fmt.Fprintln(r.w, "📜 Scrolling down...")
+	}
 }
 
-func (r *Reporter) Interrupted(start time.Time, mem *StepMemory) {
-	r.printReport(start, "interrupted by user (Ctrl+C)", mem)
+func (r *ConsoleReporter) OnStepEnd(rec StepRecord) {
+	if rec.Outcome == OutcomeError {
+		fmt.Fprintf(r.w, "⚠️ Step error: %s\n", rec.Error)
+	}
+	if rec.Usage.Calls > 0 {
+		fmt.Fprintf(r.w, "🔢 Tokens: %s\n", formatUsage(rec.Usage, rec.Cost))
+	}
 }
 
-func (r *Reporter) MaxStepsReached(start time.Time, mem *StepMemory) {
-	r.printReport(start, "max steps reached", mem)
-}
+func (r *ConsoleReporter) OnFinish(res *RunResult) {
+	fmt.Fprintln(r.w, "\n===== EXECUTION REPORT =====")
+	fmt.Fprintf(r.w, "Task: %s\n", res.Task)
+	fmt.Fprintf(r.w, "Duration: %s\n", res.Duration.Truncate(time.Millisecond))
+	fmt.Fprintf(r.w, "Exit reason: %s\n", res.ExitReason)
+	fmt.Fprintf(r.w, "Tokens: %s\n", formatUsage(res.Usage, res.Cost))
+	if res.Blocked.Requests > 0 {
+		fmt.Fprintf(r.w, "Blocked requests: %s\n", res.Blocked)
+	}
+	if res.Recoveries > 0 {
+		fmt.Fprintf(r.w, "Browser recoveries: %d\n", res.Recoveries)
+	}
+	fmt.Fprintln(r.w)
 
-func (r *Reporter) printReport(start time.Time, reason string, mem *StepMemory) {
-	duration := time.Since(start).Truncate(time.Millisecond)
+	fmt.Fprintln(r.w, "--- RAW STEP TRACE ---")
+	for _, rec := range res.Steps {
+		if line := traceLine(rec); line != "" {
+			fmt.Fprintln(r.w, line)
+		}
+	}
 
-	fmt.Println("\n===== EXECUTION REPORT =====")
-	fmt.Printf("Task: %s\n", r.task)
-	fmt.Printf("Duration: %s\n", duration)
-	fmt.Printf("Exit reason: %s\n\n", reason)
+	fmt.Fprintln(r.w, "\n--- LLM SUMMARY ---")
+	if res.Summary == "" {
+		fmt.Fprintln(r.w, "(failed to generate summary)")
+	} else {
+		fmt.Fprintln(r.w, res.Summary)
+	}
 
-	fmt.Println("--- RAW STEP TRACE ---")
-	for _, line := range r.trace {
-		fmt.Println(line)
+	fmt.Fprintln(r.w, "===== END OF REPORT =====")
+}
+
+func traceLine(rec StepRecord) string {
+	if rec.Decision == nil || rec.Snapshot == nil {
+		return ""
 	}
+	d := rec.Decision
+	return fmt.Sprintf(
+		"STEP %d | URL=%s | PHASE=%s | ACTION=%s[%d] %q%s | OBS=%s",
+		rec.Step,
+		rec.Snapshot.URL,
+		strings.ToUpper(d.CurrentPhase),
+		d.Action.Type,
+		d.Action.TargetID,
+		d.Action.Text,
+		destructiveDecor(d.Action),
+		d.Observation,
+	)
+}
 
-	fmt.Println("\n--- LLM SUMMARY ---")
-	summary, err := r.llm.SummarizeRun(llm.SummaryInput{
-		Task:        r.task,
-		ExitReason:  humanizeReason(reason),
-		FinalURL:    r.finalURL,
-		FinalAction: r.finalAction,
-		Duration:    duration.String(),
-		Steps:       mem.FullHistory(),
-	})
-	if err != nil {
-		fmt.Println("(failed to generate summary)")
-	} else {
-		fmt.Println(summary)
+func formatUsage(u llm.Usage, cost float64) string {
+	s := fmt.Sprintf("%d prompt", u.PromptTokens)
+	if u.ImageTokens > 0 {
+		s += fmt.Sprintf(" (~%d image)", u.ImageTokens)
+	}
+	s += fmt.Sprintf(" + %d completion in %d calls", u.CompletionTokens, u.Calls)
+	if cost > 0 {
+		s += fmt.Sprintf(", $%.4f", cost)
 	}
+	return s
+}
 
-	fmt.Println("===== END OF REPORT =====")
+func destructiveDecor(a llm.Action) string {
+	if a.IsDestructive {
+		return " [DESTRUCTIVE]"
+	}
+	return ""
 }
@@ -0,0 +1,68 @@
+package agent
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/browser"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/llm"
+)
+
+func TestConsoleReporter(t *testing.T) {
+	var buf bytes.Buffer
+	r := NewConsoleReporter(&buf)
+
+	decision := &llm.DecisionOutput{
+		CurrentPhase: "cart",
+		Observation:  "cart button visible",
+		Action:       llm.Action{Type: llm.ActionClick, TargetID: 7, IsDestructive: true},
+	}
+
+	r.OnStepStart(1)
+	r.OnDecision(1, "https://shop.test", decision)
+	r.OnActionResult(1, decision.Action, ErrActionDeclined)
+	r.OnStepEnd(StepRecord{Step: 1, Outcome: OutcomeError, Error: "boom", Usage: llm.Usage{Calls: 1, PromptTokens: 900, CompletionTokens: 40}})
+	r.OnFinish(&RunResult{
+		Task:       "buy",
+		ExitReason: ExitFinished,
+		Duration:   1500 * time.Millisecond,
+		Steps: []StepRecord{
+			{Step: 1, Snapshot: &SnapshotInfo{URL: "https://shop.test"}, Decision: decision},
+			{Step: 2, Error: "no decision"},
+		},
+		Usage:   llm.Usage{Calls: 2, PromptTokens: 1800, CompletionTokens: 80, ImageTokens: 765},
+		Cost:    0.0053,
+		Blocked: browser.BlockStats{Requests: 5, ByType: map[string]int{"image": 4, "font": 1}},
+	})
+
+	out := buf.String()
+	for _, want := range []string{
+		"--- STEP 1 ---",
+		"🧠 PHASE:       CART",
+		"click [7] \"\" [DESTRUCTIVE]",
+		"Destructive action was not executed",
+		"⚠️ Step error: boom",
+		"🔢 Tokens: 900 prompt + 40 completion in 1 calls",
+		"Duration: 1.5s",
+		"Tokens: 1800 prompt (~765 image) + 80 completion in 2 calls, $0.0053",
+		"STEP 1 | URL=https://shop.test | PHASE=CART | ACTION=click[7]",
+		"(failed to generate summary)",
+		"Blocked requests: 5 (image 4, font 1)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output does not contain %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "STEP 2 |") {
+		t.Error("step without decision in trace")
+	}
+
+	buf.Reset()
+	r.OnActionResult(2, llm.Action{Type: llm.ActionFinish}, errors.Join(ErrExtractionRejected, errors.New("missing title")))
+	if !strings.Contains(buf.String(), "EXTRACTION REJECTED") {
+		t.Errorf("rejection not reported: %q", buf.String())
+	}
+}
@@ -0,0 +1,81 @@
+package agent
+
+import (
+	"time"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/browser"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/llm"
+)
+
+type ExitReason string
+
+const (
+	ExitFinished  ExitReason = "task finished"
+	ExitMaxSteps  ExitReason = "max steps reached"
+	ExitCancelled ExitReason = "cancelled"
+	ExitDeadline  ExitReason = "deadline exceeded"
+	ExitBudget    ExitReason = "budget exceeded"
+	ExitLLMError  ExitReason = "llm error"
+	ExitCrashed   ExitReason = "browser crashed"
+)
+
+type StepOutcome string
+
+const (
+	OutcomeExecuted StepOutcome = "executed"
+	OutcomeFailed   StepOutcome = "failed"
+	OutcomeBlocked  StepOutcome = "blocked"
+	OutcomeRejected StepOutcome = "rejected"
+	OutcomeDeclined StepOutcome = "declined"
+	OutcomeFinished StepOutcome = "finished"
+	OutcomeError    StepOutcome = "error"
+)
+
+type SnapshotInfo struct {
+	URL           string `json:"url"`
+	Title         string `json:"title"`
+	Elements      int    `json:"elements"`
+	TreeBytes     int    `json:"tree_bytes"`
+	HasScreenshot bool   `json:"has_screenshot"`
+	Unchanged     bool   `json:"unchanged,omitempty"`
+}
+
+type StepTimings struct {
+	Snapshot time.Duration `json:"snapshot"`
+	Decision time.Duration `json:"decision"`
+	Action   time.Duration `json:"action"`
+	Total    time.Duration `json:"total"`
+}
+
+type StepRecord struct {
+	Step      int                 `json:"step"`
+	StartedAt time.Time           `json:"started_at"`
+	Snapshot  *SnapshotInfo       `json:"snapshot,omitempty"`
+	Decision  *llm.DecisionOutput `json:"decision,omitempty"`
+	Outcome   StepOutcome         `json:"outcome"`
+	Note      string              `json:"note,omitempty"`
+	Error     string              `json:"error,omitempty"`
+	Timings   StepTimings         `json:"timings"`
+	Usage     llm.Usage           `json:"usage"`
+	Cost      float64             `json:"cost_usd,omitempty"`
+}
+
+type RunResult struct {
+	Task       string        `json:"task"`
+	ExitReason ExitReason    `json:"exit_reason"`
+	Error      string        `json:"error,omitempty"`
+	FinalURL   string        `json:"final_url,omitempty"`
+	Answer     *FinalAnswer  `json:"answer,omitempty"`
+	Summary    string        `json:"summary,omitempty"`
+	Steps      []StepRecord  `json:"steps"`
+	StartedAt  time.Time     `json:"started_at"`
+	Duration   time.Duration `json:"duration"`
+	Usage      llm.Usage     `json:"usage"`
+	Cost       float64       `json:"cost_usd,omitempty"`
+
+	// Blocked counts the requests dropped by browser.BlockOptions during
+	// the run.
+	Blocked browser.BlockStats `json:"blocked_requests,omitzero"`
+	// Recoveries counts the browser crashes the run recovered from.
+	Recoveries int `json:"recoveries,omitempty"`
+}
@@ -1,61 +1,220 @@
 package agent
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"time"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/browser"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/llm"
 )
 
 var (
-	ErrInterrupted  = errors.New("execution interrupted")
-	ErrMaxSteps     = errors.New("max steps reached")
-	ErrSnapshotFail = errors.New("snapshot error")
-	ErrLLMFail      = errors.New("llm error")
+	ErrInterrupted        = errors.New("execution interrupted")
+	ErrDeadline           = errors.New("run deadline exceeded")
+	ErrMaxSteps           = errors.New("max steps reached")
+	ErrSnapshotFail       = errors.New("snapshot error")
+	ErrLLMFail            = errors.New("llm error")
+	ErrExtractionRejected = errors.New("extracted data does not match schema")
+	ErrActionDeclined     = errors.New("destructive action declined")
+	ErrBudget             = errors.New("budget exceeded")
 )
 
 type Runner struct {
-	agent      *Agent
-	task       string
-	maxSteps   int
-	mem        *StepMemory
-	prevSnap   *PageSnapshotWrapper
-	reporter   *Reporter
-	signalCtrl *SignalController
+	agent    *Agent
+	task     string
+	maxSteps int
+	opts     Options
+	mem      *StepMemory
+	prevSnap *PageSnapshotWrapper
+	obs      multiObserver
+
+	schema json.RawMessage
+	result *RunResult
+
+	blockedAtStart browser.BlockStats
 }
 
 func NewRunner(a *Agent, task string, maxSteps int) *Runner {
+	opts := a.opts
+	if maxSteps <= 0 {
+		maxSteps = opts.MaxSteps
+	}
 	return &Runner{
-		agent:      a,
-		task:       task,
-		maxSteps:   maxSteps,
-		mem:        NewStepMemory(10, 3),
-		reporter:   NewReporter(a.llm, task),
-		signalCtrl: NewSignalController(),
+		agent:    a,
+		task:     task,
+		maxSteps: maxSteps,
+		opts:     opts,
+		mem:      NewStepMemory(opts.HistoryWindow, opts.LoopThreshold, opts.PatternLength, opts.PatternRepeats),
+		obs:      multiObserver(opts.Observers),
 	}
 }
 
-func (r *Runner) Run() error {
+func (r *Runner) Run(ctx context.Context) (*RunResult, error) {
 	start := time.Now()
-	defer r.signalCtrl.Close()
+
+	r.result = &RunResult{Task: r.task, StartedAt: start}
+	r.blockedAtStart = r.blockStats()
 
 	for step := 1; step <= r.maxSteps; step++ {
-		if r.signalCtrl.Interrupted() {
-			r.reporter.Interrupted(start, r.mem)
-			return ErrInterrupted
+		if ctx.Err() != nil {
+			return r.stop(ctx, start)
 		}
 
-		finished, err := r.executeStep(step)
+		r.obs.OnStepStart(step)
+
+		rec := StepRecord{Step: step, StartedAt: time.Now()}
+		finished, err := r.runStep(ctx, &rec)
+		rec.Timings.Total = time.Since(rec.StartedAt)
 		if err != nil {
-			r.reporter.StepError(err)
+			rec.Outcome = OutcomeError
+			rec.Error = err.Error()
 		}
+		rec.Cost = r.addUsage(rec.Usage)
+		r.result.Steps = append(r.result.Steps, rec)
+		r.obs.OnStepEnd(rec)
 
 		if finished {
-			r.reporter.Finished(start, r.mem)
-			return nil
+			return r.finish(ctx, start, ExitFinished, nil)
+		}
+
+		if llm.IsFatal(err) {
+			return r.finish(ctx, start, ExitLLMError, err)
+		}
+
+		if errors.Is(err, browser.ErrBrowserCrashed) {
+			return r.finish(ctx, start, ExitCrashed, err)
+		}
+
+		if err := r.checkBudget(); err != nil {
+			return r.finish(ctx, start, ExitBudget, err)
+		}
+
+		if step == r.maxSteps {
+			break
 		}
 
-		time.Sleep(3 * time.Second)
+		select {
+		case <-ctx.Done():
+			return r.stop(ctx, start)
+		case <-time.After(r.opts.StepDelay):
+		}
+	}
+
+	if ctx.Err() != nil {
+		return r.stop(ctx, start)
+	}
+
+	return r.finish(ctx, start, ExitMaxSteps, ErrMaxSteps)
+}
+
+func (r *Runner) runStep(ctx context.Context, rec *StepRecord) (bool, error) {
+	if r.opts.StepTimeout <= 0 {
+		return r.executeStep(ctx, rec)
+	}
+
+	stepCtx, cancel := context.WithTimeout(ctx, r.opts.StepTimeout)
+	defer cancel()
+
+	finished, err := r.executeStep(stepCtx, rec)
+	if err != nil && ctx.Err() == nil && errors.Is(stepCtx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("step timed out after %s: %w", r.opts.StepTimeout, err)
+	}
+	return finished, err
+}
+
+// recoverBrowser reopens a browser that crashed since the last step and
+// tells the model the page was reloaded.
+func (r *Runner) recoverBrowser(ctx context.Context) error {
+	crash := r.crashed()
+	if crash == nil {
+		return nil
+	}
+	if r.result.Recoveries >= max(r.opts.MaxRecoveries, 0) {
+		return fmt.Errorf("%w (recovered %d times)", crash, r.result.Recoveries)
+	}
+	r.result.Recoveries++
+	url, err := r.agent.browser.(browser.Recoverer).Recover(ctx)
+	if err != nil {
+		return fmt.Errorf("%w: %w", crash, err)
+	}
+	r.prevSnap = nil
+	r.mem.AddSystemNote(fmt.Sprintf(
+		"SYSTEM NOTE: the browser crashed (%v) and was restarted; the page %s was reloaded. "+
+			"Text typed into forms and anything else not kept in the URL or cookies was lost; "+
+			"check the page before continuing.",
+		crash, url,
+	))
+	return nil
+}
+
+// crashed reports a crash of a driver that can recover from it.
+func (r *Runner) crashed() error {
+	if rec, ok := r.agent.browser.(browser.Recoverer); ok {
+		return rec.Crashed()
+	}
+	return nil
+}
+
+// checkpoint saves the browser state a recovery restores, unless the run
+// never recovers.
+func (r *Runner) checkpoint(ctx context.Context) {
+	if rec, ok := r.agent.browser.(browser.Recoverer); ok && r.opts.MaxRecoveries > 0 {
+		rec.Checkpoint(ctx)
+	}
+}
+
+func (r *Runner) blockedNavigations() []browser.BlockedNavigation {
+	if nr, ok := r.agent.browser.(browser.NavigationReporter); ok {
+		return nr.BlockedNavigations()
+	}
+	return nil
+}
+
+func (r *Runner) blockStats() browser.BlockStats {
+	if bc, ok := r.agent.browser.(browser.BlockCounter); ok {
+		return bc.BlockStats()
+	}
+	return browser.BlockStats{}
+}
+
+// addUsage adds u to the run totals and returns its cost.
+func (r *Runner) addUsage(u llm.Usage) float64 {
+	cost, _ := r.opts.Prices.Cost(u)
+	r.result.Usage = r.result.Usage.Add(u)
+	r.result.Cost += cost
+	return cost
+}
+
+func (r *Runner) checkBudget() error {
+	if b := r.opts.TokenBudget; b > 0 && r.result.Usage.TotalTokens() >= b {
+		return fmt.Errorf("%w: used %d of %d tokens", ErrBudget, r.result.Usage.TotalTokens(), b)
+	}
+	if b := r.opts.CostBudget; b > 0 && r.result.Cost >= b {
+		return fmt.Errorf("%w: spent $%.4f of $%.4f", ErrBudget, r.result.Cost, b)
+	}
+	return nil
+}
+
+func (r *Runner) stop(ctx context.Context, start time.Time) (*RunResult, error) {
+	reason, err := ExitCancelled, fmt.Errorf("%w: %w", ErrInterrupted, ctx.Err())
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		reason, err = ExitDeadline, fmt.Errorf("%w: %w", ErrDeadline, ctx.Err())
+	}
+	return r.finish(ctx, start, reason, err)
+}
+
+func (r *Runner) finish(ctx context.Context, start time.Time, reason ExitReason, err error) (*RunResult, error) {
+	r.result.ExitReason = reason
+	r.result.Duration = time.Since(start)
+	r.result.Blocked = r.blockStats().Sub(r.blockedAtStart)
+	if err != nil {
+		r.result.Error = err.Error()
 	}
+	r.result.Summary = r.summarize(ctx)
 
-	r.reporter.MaxStepsReached(start, r.mem)
-	return ErrMaxSteps
+	r.obs.OnFinish(r.result)
+	return r.result, err
 }
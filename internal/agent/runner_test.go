@@ -0,0 +1,305 @@
+package agent
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/browser"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/browser/browsertest"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/fixture"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/llm"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/llm/llmtest"
+)
+
+const testPage = `<!doctype html>
+<html><head><title>Shop</title></head>
+<body>
+  <input aria-label="Search" id="q">
+  <button onclick="document.title='Added'">Add to cart</button>
+  <button>Does nothing</button>
+</body></html>`
+
+// newTestBrowser starts a headless Chrome on a page with testPage.
+func newTestBrowser(t *testing.T) (*browser.Manager, string) {
+	t.Helper()
+
+	b := fixture.NewBrowser(t)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = io.WriteString(w, testPage)
+	}))
+	t.Cleanup(srv.Close)
+
+	if err := b.Navigate(context.Background(), srv.URL); err != nil {
+		t.Fatalf("open test page: %v", err)
+	}
+	return b, srv.URL
+}
+
+func testOptions() Options {
+	return Options{
+		MaxSteps:           5,
+		LoopThreshold:      2,
+		PatternLength:      1,
+		StepDelay:          -1,
+		DisableScreenshots: true,
+		Observers:          []Observer{},
+		Approve:            func(context.Context, llm.Action) bool { return false },
+	}
+}
+
+func runWith(t *testing.T, c llm.Client, opts Options) (*RunResult, error) {
+	t.Helper()
+	b, _ := newTestBrowser(t)
+	return NewAgentWithOptions(b, c, opts).RunWithResult(context.Background(), "buy something", 0)
+}
+
+func TestRunnerFinish(t *testing.T) {
+	fake := llmtest.NewScripted(
+		llmtest.ClickMatching("Add to cart"),
+		llmtest.Finish("added"),
+	)
+
+	res, err := runWith(t, fake, testOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if res.ExitReason != ExitFinished || res.Answer == nil || res.Answer.Answer != "added" {
+		t.Errorf("unexpected result: reason=%s answer=%+v", res.ExitReason, res.Answer)
+	}
+	if len(res.Steps) != 2 || res.Steps[0].Outcome != OutcomeExecuted || res.Steps[1].Outcome != OutcomeFinished {
+		t.Errorf("unexpected steps: %+v", res.Steps)
+	}
+	if res.Summary != "test summary" {
+		t.Errorf("summary = %q", res.Summary)
+	}
+
+	inputs := fake.Inputs()
+	if inputs[1].Task != "buy something" || !strings.Contains(inputs[1].History, "action=click") {
+		t.Errorf("second decision did not see the click in history: %q", inputs[1].History)
+	}
+	if inputs[0].ScreenshotBase64 != "" {
+		t.Error("screenshot sent with DisableScreenshots")
+	}
+	if len(inputs[0].TargetIDs) != 3 {
+		t.Errorf("target IDs = %v, want the 3 interactive elements", inputs[0].TargetIDs)
+	}
+}
+
+func TestRunnerTypesIntoInput(t *testing.T) {
+	fake := llmtest.NewScripted(
+		llmtest.TypeMatching("Search", "pizza", false),
+		func(in llm.DecisionInput) (*llm.DecisionOutput, error) {
+			if !strings.Contains(in.DOMTree, "pizza") {
+				t.Errorf("typed text not visible in DOM:\n%s", in.DOMTree)
+			}
+			return llmtest.Finish("typed")(in)
+		},
+	)
+
+	if _, err := runWith(t, fake, testOptions()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRunnerLoopGuard(t *testing.T) {
+	fake := llmtest.NewFunc(llmtest.ClickMatching("Does nothing"))
+
+	opts := testOptions()
+	opts.MaxSteps = 4
+	res, err := runWith(t, fake, opts)
+	if !errors.Is(err, ErrMaxSteps) {
+		t.Fatalf("err = %v, want ErrMaxSteps", err)
+	}
+
+	var outcomes []StepOutcome
+	for _, rec := range res.Steps {
+		outcomes = append(outcomes, rec.Outcome)
+	}
+	want := []StepOutcome{OutcomeExecuted, OutcomeExecuted, OutcomeBlocked, OutcomeBlocked}
+	if len(outcomes) != len(want) {
+		t.Fatalf("outcomes = %v, want %v", outcomes, want)
+	}
+	for i := range want {
+		if outcomes[i] != want[i] {
+			t.Fatalf("outcomes = %v, want %v", outcomes, want)
+		}
+	}
+	if !strings.Contains(res.Steps[2].Note, "Do NOT repeat") {
+		t.Errorf("blocked step note = %q", res.Steps[2].Note)
+	}
+}
+
+func TestRunnerMaxSteps(t *testing.T) {
+	fake := llmtest.NewFunc(llmtest.Scroll())
+
+	opts := testOptions()
+	opts.MaxSteps = 3
+	opts.LoopThreshold = 10
+	res, err := runWith(t, fake, opts)
+	if !errors.Is(err, ErrMaxSteps) {
+		t.Fatalf("err = %v, want ErrMaxSteps", err)
+	}
+	if res.ExitReason != ExitMaxSteps || len(res.Steps) != 3 || res.Answer != nil {
+		t.Errorf("unexpected result: reason=%s steps=%d answer=%v", res.ExitReason, len(res.Steps), res.Answer)
+	}
+}
+
+func TestRunnerLLMErrors(t *testing.T) {
+	fake := llmtest.NewScripted(
+		llmtest.Fail(errors.New("model unavailable")),
+		llmtest.Finish("recovered"),
+	)
+
+	res, err := runWith(t, fake, testOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Steps[0].Outcome != OutcomeError || !strings.Contains(res.Steps[0].Error, "model unavailable") {
+		t.Errorf("first step = %+v", res.Steps[0])
+	}
+	if res.ExitReason != ExitFinished {
+		t.Errorf("exit reason = %s", res.ExitReason)
+	}
+}
+
+func TestRunnerStopsOnFatalLLMError(t *testing.T) {
+	fake := llmtest.NewFunc(llmtest.Fail(&llm.APIError{Provider: llm.ProviderOpenAI, StatusCode: 401, Kind: llm.ErrorAuth}))
+
+	res, err := runWith(t, fake, testOptions())
+	if !errors.Is(err, ErrLLMFail) || !llm.IsFatal(err) {
+		t.Fatalf("err = %v, want fatal ErrLLMFail", err)
+	}
+	if res.ExitReason != ExitLLMError || len(res.Steps) != 1 {
+		t.Errorf("reason=%s steps=%d", res.ExitReason, len(res.Steps))
+	}
+}
+
+func TestRunnerDeclinedDestructiveAction(t *testing.T) {
+	fake := llmtest.NewScripted(
+		func(in llm.DecisionInput) (*llm.DecisionOutput, error) {
+			el, _ := llmtest.FindElement(in.DOMTree, "Add to cart")
+			return &llm.DecisionOutput{Action: llm.Action{Type: llm.ActionClick, TargetID: el.ID, IsDestructive: true}}, nil
+		},
+		llmtest.Finish("stopped"),
+	)
+
+	res, err := runWith(t, fake, testOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Steps[0].Outcome != OutcomeDeclined {
+		t.Errorf("outcome = %s, want declined", res.Steps[0].Outcome)
+	}
+	if !strings.Contains(fake.Inputs()[1].History, "declined") {
+		t.Errorf("decline note missing from history: %q", fake.Inputs()[1].History)
+	}
+}
+
+func TestRunnerCancelled(t *testing.T) {
+	b, _ := newTestBrowser(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	fake := llmtest.NewFunc(func(in llm.DecisionInput) (*llm.DecisionOutput, error) {
+		cancel()
+		return llmtest.Scroll()(in)
+	})
+
+	opts := testOptions()
+	opts.StepDelay = time.Minute
+	res, err := NewAgentWithOptions(b, fake, opts).RunWithResult(ctx, "t", 0)
+	if !errors.Is(err, ErrInterrupted) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want ErrInterrupted wrapping context.Canceled", err)
+	}
+	if res.ExitReason != ExitCancelled {
+		t.Errorf("exit reason = %s", res.ExitReason)
+	}
+}
+
+func TestRunnerWithFakeDriver(t *testing.T) {
+	d := browsertest.New(map[string]*browsertest.Page{
+		"https://shop.test/": {Title: "Shop", Elements: []*browsertest.Element{
+			{Role: "textbox", Name: "Search", OnSubmit: func(f *browsertest.Fake) { f.Open("https://shop.test/results") }},
+		}},
+		"https://shop.test/results": {Title: "Results", Text: []string{"2 pizzas found"}, Elements: []*browsertest.Element{
+			{Role: "link", Name: "Margherita", Href: "https://shop.test/margherita"},
+		}},
+		"https://shop.test/margherita": {Title: "Margherita"},
+	})
+	if err := d.Navigate(context.Background(), "https://shop.test/"); err != nil {
+		t.Fatal(err)
+	}
+	fake := llmtest.NewScripted(
+		llmtest.TypeMatching("Search", "pizza", true),
+		llmtest.ClickMatching("Margherita"),
+		llmtest.Finish("found"),
+	)
+
+	res, err := NewAgentWithOptions(d, fake, testOptions()).RunWithResult(context.Background(), "find a pizza", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.FinalURL != "https://shop.test/margherita" {
+		t.Errorf("final URL = %q", res.FinalURL)
+	}
+	want := []string{"navigate https://shop.test/", "type Search pizza + enter", "click Margherita"}
+	if got := d.Actions(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("actions = %q, want %q", got, want)
+	}
+	if !strings.Contains(fake.Inputs()[1].DOMTree, "2 pizzas found") {
+		t.Errorf("results page missing from tree:\n%s", fake.Inputs()[1].DOMTree)
+	}
+}
+
+// checkpointCounter is a fake driver that can recover and counts its
+// checkpoints.
+type checkpointCounter struct {
+	*browsertest.Fake
+	checkpoints int
+}
+
+func (c *checkpointCounter) Crashed() error                          { return nil }
+func (c *checkpointCounter) Recover(context.Context) (string, error) { return "", nil }
+func (c *checkpointCounter) Checkpoint(context.Context)              { c.checkpoints++ }
+
+func TestRunnerCheckpointsOnlyWithRecovery(t *testing.T) {
+	for _, tc := range []struct {
+		maxRecoveries int
+		want          int
+	}{{0, 2}, {-1, 0}} {
+		d := &checkpointCounter{Fake: browsertest.New(map[string]*browsertest.Page{
+			"https://shop.test/": {Title: "Shop"},
+		})}
+		opts := testOptions()
+		opts.MaxRecoveries = tc.maxRecoveries
+		fake := llmtest.NewScripted(llmtest.Scroll(), llmtest.Finish("done"))
+		if _, err := NewAgentWithOptions(d, fake, opts).RunWithResult(context.Background(), "t", 0); err != nil {
+			t.Fatal(err)
+		}
+		if d.checkpoints != tc.want {
+			t.Errorf("MaxRecoveries %d: %d checkpoints, want %d", tc.maxRecoveries, d.checkpoints, tc.want)
+		}
+	}
+}
+
+func TestOptionsWithDefaults(t *testing.T) {
+	def := DefaultOptions()
+
+	got := Options{}.withDefaults()
+	if got.MaxSteps != def.MaxSteps || got.StepDelay != def.StepDelay || got.MaxRecoveries != def.MaxRecoveries || len(got.Observers) != 1 {
+		t.Errorf("zero options = %+v, want the defaults", got)
+	}
+
+	got = Options{StepDelay: -1, StepTimeout: -1, MaxRecoveries: -1, Observers: []Observer{}}.withDefaults()
+	if got.StepDelay != -1 || got.StepTimeout != -1 || got.MaxRecoveries != -1 || len(got.Observers) != 0 {
+		t.Errorf("disabled options = %+v, want them kept off", got)
+	}
+}
@@ -1,10 +1,12 @@
 package agent
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"strings"
+	"time"
 
-	"github.com/chromedp/chromedp"
 	"github.com/nbenliogludev/go-browser-ai-agent/internal/llm"
 )
 
@@ -13,49 +15,128 @@ type PageSnapshotWrapper struct {
 	URL  string
 }
 
-func (r *Runner) executeStep(step int) (bool, error) {
-	fmt.Printf("\n--- STEP %d ---\n", step)
+func (r *Runner) executeStep(ctx context.Context, rec *StepRecord) (bool, error) {
+	step := rec.Step
 
-	snap, err := r.agent.browser.Snapshot(step)
+	if err := r.recoverBrowser(ctx); err != nil {
+		return false, err
+	}
+
+	phaseStart := time.Now()
+	snap, err := r.agent.browser.Snapshot(ctx, step, !r.opts.DisableScreenshots)
+	if err != nil && r.crashed() != nil {
+		// The browser died during the snapshot.
+		if err := r.recoverBrowser(ctx); err != nil {
+			return false, err
+		}
+		snap, err = r.agent.browser.Snapshot(ctx, step, !r.opts.DisableScreenshots)
+	}
+	rec.Timings.Snapshot = time.Since(phaseStart)
 	if err != nil {
-		return false, ErrSnapshotFail
+		return false, fmt.Errorf("%w: %v", ErrSnapshotFail, err)
 	}
+	r.checkpoint(ctx)
 
-	if r.prevSnap != nil && snap.Tree == r.prevSnap.Tree {
+	unchanged := r.prevSnap != nil && snap.Tree == r.prevSnap.Tree
+	if unchanged {
 		r.mem.AddSystemNote("SYSTEM ALERT: Last action had NO VISIBLE EFFECT.")
 	}
+	for _, b := range r.blockedNavigations() {
+		r.mem.AddSystemNote(fmt.Sprintf(
+			"SYSTEM NOTE: navigation to %s was blocked (%s). This site is not allowed for the task; "+
+				"do not try to open it again, continue on the allowed pages or finish.",
+			b.URL, b.Reason,
+		))
+	}
 
-	fmt.Printf("URL: %s\nTitle: %s\n", snap.URL, snap.Title)
+	rec.Snapshot = &SnapshotInfo{
+		URL:           snap.URL,
+		Title:         snap.Title,
+		Elements:      len(snap.Elements),
+		TreeBytes:     len(snap.Tree),
+		HasScreenshot: snap.ScreenshotBase64 != "",
+		Unchanged:     unchanged,
+	}
+	r.result.FinalURL = snap.URL
 
-	decision, err := r.agent.llm.DecideAction(llm.DecisionInput{
+	r.obs.OnSnapshot(step, snap)
+
+	phaseStart = time.Now()
+	decision, err := r.agent.llm.DecideAction(ctx, llm.DecisionInput{
 		Task:             r.task,
 		DOMTree:          snap.Tree,
 		CurrentURL:       snap.URL,
 		History:          r.mem.HistoryString(),
 		ScreenshotBase64: snap.ScreenshotBase64,
+		OutputSchema:     string(r.schema),
+		Params:           r.opts.Model,
+		TargetIDs:        snap.Elements.IDs(),
 	})
+	rec.Timings.Decision = time.Since(phaseStart)
 	if err != nil {
-		return false, ErrLLMFail
+		var derr *llm.DecisionError
+		if errors.As(err, &derr) {
+			rec.Usage = derr.Usage
+		}
+		return false, fmt.Errorf("%w: %w", ErrLLMFail, err)
 	}
+	rec.Usage = decision.Usage
+	rec.Decision = decision
 
-	r.reporter.LogDecision(step, snap.URL, decision)
+	r.obs.OnDecision(step, snap.URL, decision)
 
 	if blocked, reason := r.mem.ShouldBlock(snap.URL, decision.Action); blocked {
-		fmt.Printf("⛔ LOOP GUARD: %s\n", reason)
-		_ = chromedp.Run(
-			r.agent.browser.Ctx,
-			chromedp.Evaluate(`window.scrollBy({top: 300, behavior: 'smooth'});`, nil),
-		)
+		r.obs.OnLoopBlocked(step, decision.Action, reason)
+		_ = r.agent.browser.Evaluate(ctx, `window.scrollBy({top: 300, behavior: 'smooth'});`, nil)
 		r.mem.MarkLoopTriggered()
+		rec.Outcome = OutcomeBlocked
+		rec.Note = reason
 		return false, nil
 	}
 
 	if decision.Action.Type == llm.ActionFinish {
+		if r.schema != nil {
+			if err := llm.ValidateJSON(r.schema, decision.Action.Data); err != nil {
+				r.obs.OnActionResult(step, decision.Action, fmt.Errorf("%w: %w", ErrExtractionRejected, err))
+				r.mem.AddSystemNote(fmt.Sprintf(
+					"SYSTEM NOTE: extracted data does not match OUTPUT SCHEMA (%v). "+
+						"Fix the data in action.data and finish again.",
+					err,
+				))
+				rec.Outcome = OutcomeRejected
+				rec.Note = err.Error()
+				return false, nil
+			}
+		}
+		r.result.Answer = newFinalAnswer(decision.Action)
+		rec.Outcome = OutcomeFinished
+		r.obs.OnActionResult(step, decision.Action, nil)
 		return true, nil
 	}
 
-	if err := r.agent.executeAction(decision.Action, snap); err != nil {
+	if needsApproval(decision.Action) {
+		r.obs.OnApprovalRequested(step, decision.Action)
+		if !r.opts.Approve(ctx, decision.Action) {
+			r.obs.OnActionResult(step, decision.Action, ErrActionDeclined)
+			r.mem.AddSystemNote(fmt.Sprintf(
+				"SYSTEM NOTE: the user declined the destructive action %s [%d]. Do not retry it; "+
+					"finish or choose a safe alternative.",
+				decision.Action.Type, decision.Action.TargetID,
+			))
+			rec.Outcome = OutcomeDeclined
+			r.prevSnap = &PageSnapshotWrapper{Tree: snap.Tree, URL: snap.URL}
+			return false, nil
+		}
+	}
+
+	phaseStart = time.Now()
+	err = r.agent.executeAction(ctx, decision.Action, snap)
+	rec.Timings.Action = time.Since(phaseStart)
+	r.obs.OnActionResult(step, decision.Action, err)
+	if err != nil {
 		r.mem.AddSystemNote(fmt.Sprintf("SYSTEM ERROR: %v", err))
+		rec.Outcome = OutcomeFailed
+		rec.Error = err.Error()
 	} else {
 		r.mem.Add(step, snap.URL, decision.Action)
 		r.mem.AddSystemNote(fmt.Sprintf(
@@ -63,6 +144,7 @@ func (r *Runner) executeStep(step int) (bool, error) {
 			strings.ToUpper(decision.CurrentPhase),
 			decision.Observation,
 		))
+		rec.Outcome = OutcomeExecuted
 	}
 
 	r.prevSnap = &PageSnapshotWrapper{
@@ -72,3 +154,7 @@ func (r *Runner) executeStep(step int) (bool, error) {
 
 	return false, nil
 }
+
+func needsApproval(a llm.Action) bool {
+	return a.IsDestructive && a.TargetID != 0 && a.Type != llm.ActionScroll
+}
@@ -0,0 +1,247 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// BlockOptions selects requests the tab does not load, to speed up page
+// loads and snapshots.
+type BlockOptions struct {
+	// ResourceTypes are DevTools resource types in lower case: image, media,
+	// font, stylesheet, script, xhr (fetch() calls included), websocket,
+	// ... Pages themselves ("document") cannot be blocked.
+	ResourceTypes []string
+	// Patterns are URL patterns where * matches any run of characters and
+	// ? a single one, e.g. "*.mp4*" or "*://cdn.example.com/video/*".
+	Patterns []string
+	// Trackers blocks the built-in list of ad and analytics hosts.
+	Trackers bool
+}
+
+func (o BlockOptions) empty() bool {
+	return len(o.ResourceTypes) == 0 && len(o.Patterns) == 0 && !o.Trackers
+}
+
+// trackerHosts are ad, analytics and tracking services blocked with
+// BlockOptions.Trackers, subdomains included.
+var trackerHosts = []string{
+	"doubleclick.net",
+	"googlesyndication.com",
+	"googleadservices.com",
+	"google-analytics.com",
+	"googletagmanager.com",
+	"googletagservices.com",
+	"adservice.google.com",
+	"connect.facebook.net",
+	"analytics.tiktok.com",
+	"bat.bing.com",
+	"clarity.ms",
+	"hotjar.com",
+	"mc.yandex.ru",
+	"an.yandex.ru",
+	"yandexadexchange.net",
+	"top-fwz1.mail.ru",
+	"ad.mail.ru",
+	"vk.com/rtrg",
+	"adriver.ru",
+	"criteo.com",
+	"criteo.net",
+	"adnxs.com",
+	"taboola.com",
+	"outbrain.com",
+	"scorecardresearch.com",
+	"mixpanel.com",
+	"cdn.segment.com",
+	"api.amplitude.com",
+	"mgid.com",
+}
+
+// BlockStats counts requests blocked by BlockOptions, by resource type.
+type BlockStats struct {
+	Requests int            `json:"requests"`
+	ByType   map[string]int `json:"by_type,omitempty"`
+}
+
+// Sub returns the requests blocked since prev was taken.
+func (s BlockStats) Sub(prev BlockStats) BlockStats {
+	d := BlockStats{Requests: s.Requests - prev.Requests}
+	for t, n := range s.ByType {
+		if n -= prev.ByType[t]; n > 0 {
+			if d.ByType == nil {
+				d.ByType = make(map[string]int)
+			}
+			d.ByType[t] = n
+		}
+	}
+	return d
+}
+
+func (s BlockStats) String() string {
+	types := make([]string, 0, len(s.ByType))
+	for t := range s.ByType {
+		types = append(types, t)
+	}
+	sort.Slice(types, func(i, j int) bool {
+		if s.ByType[types[i]] != s.ByType[types[j]] {
+			return s.ByType[types[i]] > s.ByType[types[j]]
+		}
+		return types[i] < types[j]
+	})
+	parts := make([]string, len(types))
+	for i, t := range types {
+		parts[i] = fmt.Sprintf("%s %d", t, s.ByType[t])
+	}
+	if len(parts) == 0 {
+		return fmt.Sprint(s.Requests)
+	}
+	return fmt.Sprintf("%d (%s)", s.Requests, strings.Join(parts, ", "))
+}
+
+// resourceTypes maps the lower-case names accepted in BlockOptions.
+var resourceTypes = func() map[string]network.ResourceType {
+	m := make(map[string]network.ResourceType)
+	for _, t := range []network.ResourceType{
+		network.ResourceTypeStylesheet, network.ResourceTypeImage, network.ResourceTypeMedia,
+		network.ResourceTypeFont, network.ResourceTypeScript, network.ResourceTypeTextTrack,
+		network.ResourceTypeXHR, network.ResourceTypeFetch, network.ResourceTypePrefetch,
+		network.ResourceTypeEventSource, network.ResourceTypeWebSocket, network.ResourceTypeManifest,
+		network.ResourceTypePing, network.ResourceTypeOther,
+	} {
+		m[strings.ToLower(string(t))] = t
+	}
+	return m
+}()
+
+// SetBlocking replaces the request blocking of the tab; empty options stop
+// blocking. Counters in BlockStats are kept.
+func (m *Manager) SetBlocking(ctx context.Context, o BlockOptions) error {
+	if o.empty() {
+		return m.removeRequestFilter(ctx, "block")
+	}
+
+	types := make(map[network.ResourceType]bool)
+	var patterns []*fetch.RequestPattern
+	for _, name := range o.ResourceTypes {
+		t, ok := resourceTypes[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return fmt.Errorf("unknown resource type %q (want one of %s)", name, strings.Join(sortedKeys(resourceTypes), ", "))
+		}
+		types[t] = true
+		patterns = append(patterns, &fetch.RequestPattern{URLPattern: "*", ResourceType: t})
+	}
+	for _, p := range o.Patterns {
+		if p == "" || p == "*" {
+			return fmt.Errorf("invalid block pattern %q", p)
+		}
+		patterns = append(patterns, &fetch.RequestPattern{URLPattern: p})
+	}
+	if o.Trackers {
+		for _, h := range trackerHosts {
+			patterns = append(patterns, &fetch.RequestPattern{URLPattern: "*" + h + "*"})
+		}
+	}
+
+	return m.setRequestFilter(ctx, requestFilter{
+		name:     "block",
+		patterns: patterns,
+		handle: func(ev *fetch.EventRequestPaused) chromedp.Action {
+			if ev.ResourceType == network.ResourceTypeDocument && ev.FrameID == m.mainFrame() {
+				return nil
+			}
+			if !types[ev.ResourceType] && !matchesAny(o.Patterns, ev.Request.URL) && !(o.Trackers && isTracker(ev.Request.URL)) {
+				return nil
+			}
+			m.filterMu.Lock()
+			m.blockStats.Requests++
+			if m.blockStats.ByType == nil {
+				m.blockStats.ByType = make(map[string]int)
+			}
+			m.blockStats.ByType[strings.ToLower(string(ev.ResourceType))]++
+			m.filterMu.Unlock()
+			return fetch.FailRequest(ev.RequestID, network.ErrorReasonBlockedByClient)
+		},
+	}, false)
+}
+
+// BlockStats returns the number of requests blocked since the browser
+// started.
+func (m *Manager) BlockStats() BlockStats {
+	m.filterMu.Lock()
+	defer m.filterMu.Unlock()
+	s := m.blockStats
+	s.ByType = maps.Clone(s.ByType)
+	return s
+}
+
+func isTracker(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(u.Hostname())
+	for _, t := range trackerHosts {
+		h, path, _ := strings.Cut(t, "/")
+		if (host == h || strings.HasSuffix(host, "."+h)) && (path == "" || strings.HasPrefix(u.Path, "/"+path)) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAny(patterns []string, s string) bool {
+	for _, p := range patterns {
+		if wildcardMatch(p, s) {
+			return true
+		}
+	}
+	return false
+}
+
+// wildcardMatch matches s against a Fetch URL pattern: * is any run of
+// characters, ? a single character and a backslash escapes the next one.
+func wildcardMatch(pattern, s string) bool {
+	// Classic backtracking over the last *.
+	p, i := 0, 0
+	star, mark := -1, 0
+	for i < len(s) {
+		switch {
+		case p < len(pattern) && pattern[p] == '*':
+			star, mark = p, i
+			p++
+		case p < len(pattern) && pattern[p] == '\\' && p+1 < len(pattern) && pattern[p+1] == s[i]:
+			p += 2
+			i++
+		case p < len(pattern) && (pattern[p] == '?' || (pattern[p] == s[i] && pattern[p] != '\\')):
+			p++
+			i++
+		case star >= 0:
+			p = star + 1
+			mark++
+			i = mark
+		default:
+			return false
+		}
+	}
+	for p < len(pattern) && pattern[p] == '*' {
+		p++
+	}
+	return p == len(pattern)
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
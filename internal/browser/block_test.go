@@ -0,0 +1,80 @@
+package browser
+
+import "testing"
+
+func TestWildcardMatch(t *testing.T) {
+	for _, c := range []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"*.mp4*", "https://cdn.example/v/clip.mp4?t=1", true},
+		{"*.mp4*", "https://cdn.example/v/clip.webm", false},
+		{"*://cdn.example.com/video/*", "https://cdn.example.com/video/a", true},
+		{"*://cdn.example.com/video/*", "https://cdn.example.com/img/a", false},
+		{"https://a.example/?", "https://a.example/x", true},
+		{"https://a.example/?", "https://a.example/xy", false},
+		{`*\?ad=*`, "https://a.example/p?ad=1", true},
+		{`*\?ad=*`, "https://a.example/pXad=1", false},
+		{"*", "", true},
+	} {
+		if got := wildcardMatch(c.pattern, c.s); got != c.want {
+			t.Errorf("wildcardMatch(%q, %q) = %v, want %v", c.pattern, c.s, got, c.want)
+		}
+	}
+}
+
+func TestIsTracker(t *testing.T) {
+	for url, want := range map[string]bool{
+		"https://www.google-analytics.com/g/collect": true,
+		"https://mc.yandex.ru/watch/1":               true,
+		"https://vk.com/rtrg?p=1":                    true,
+		"https://vk.com/feed":                        false,
+		"https://notdoubleclick.net/":                false,
+		"https://shop.example.com/analytics.js":      false,
+	} {
+		if got := isTracker(url); got != want {
+			t.Errorf("isTracker(%q) = %v, want %v", url, got, want)
+		}
+	}
+}
+
+func TestBlockStats(t *testing.T) {
+	prev := BlockStats{Requests: 2, ByType: map[string]int{"image": 2}}
+	cur := BlockStats{Requests: 6, ByType: map[string]int{"image": 3, "font": 1, "script": 2}}
+
+	d := cur.Sub(prev)
+	if d.Requests != 4 || d.ByType["image"] != 1 || d.ByType["script"] != 2 {
+		t.Errorf("Sub = %+v", d)
+	}
+	if got := d.String(); got != "4 (script 2, font 1, image 1)" {
+		t.Errorf("String() = %q", got)
+	}
+}
+
+func TestAuthPatterns(t *testing.T) {
+	rule, err := parseURLRule("https://*.staging.example:8443")
+	if err != nil {
+		t.Fatal(err)
+	}
+	paused := func(proxy bool, url string) bool {
+		for _, p := range authPatterns(proxy, []urlRule{rule}) {
+			if wildcardMatch(p.URLPattern, url) {
+				return true
+			}
+		}
+		return false
+	}
+	for _, c := range []struct {
+		proxy bool
+		url   string
+		want  bool
+	}{
+		{false, "https://api.staging.example:8443/v1", true},
+		{false, "https://cdn.example/app.js", false},
+		{true, "https://cdn.example/app.js", true},
+	} {
+		if got := paused(c.proxy, c.url); got != c.want {
+			t.Errorf("proxy %v: %s paused = %v, want %v", c.proxy, c.url, got, c.want)
+		}
+	}
+}
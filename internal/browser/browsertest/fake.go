@@ -0,0 +1,217 @@
+// Package browsertest provides a fake browser.Driver for tests that do not
+// need a real browser.
+package browsertest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/browser"
+)
+
+var ErrNoPage = errors.New("browsertest: no such page")
+
+// Page is a page of the fake site.
+type Page struct {
+	Title string
+	// Text lines are listed in the snapshot above the elements.
+	Text     []string
+	Elements []*Element
+}
+
+// Element is an interactive element of a Page, listed in snapshots as
+// "[id] [role] "name"".
+type Element struct {
+	Role string
+	Name string
+	// Value is the current value of an input; Type replaces it.
+	Value string
+	// Href is opened by a click.
+	Href string
+	// OnClick and OnSubmit run on a click and on Type with submit.
+	OnClick  func(f *Fake)
+	OnSubmit func(f *Fake)
+}
+
+// Fake is a browser.Driver on a map of pages keyed by URL. It records the
+// actions it performs for assertions.
+type Fake struct {
+	mu      sync.Mutex
+	pages   map[string]*Page
+	url     string
+	actions []string
+	closed  bool
+
+	// OnEvaluate answers Evaluate; nil only records the call.
+	OnEvaluate func(expression string, res any) error
+}
+
+var _ browser.Driver = (*Fake)(nil)
+
+// New returns a Fake serving pages, on about:blank.
+func New(pages map[string]*Page) *Fake {
+	return &Fake{pages: pages, url: "about:blank"}
+}
+
+// Actions returns what the driver did, in order: "navigate URL",
+// "click NAME", "type NAME TEXT" (with " + enter" on submit), "evaluate
+// EXPRESSION".
+func (f *Fake) Actions() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.actions...)
+}
+
+// Closed reports whether Close was called.
+func (f *Fake) Closed() bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.closed
+}
+
+func (f *Fake) record(format string, args ...any) {
+	f.actions = append(f.actions, fmt.Sprintf(format, args...))
+}
+
+func (f *Fake) Navigate(ctx context.Context, url string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.record("navigate %s", url)
+	if _, ok := f.pages[url]; !ok {
+		return fmt.Errorf("%w: %s", ErrNoPage, url)
+	}
+	f.url = url
+	return nil
+}
+
+func (f *Fake) Location(ctx context.Context) (string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.url, ctx.Err()
+}
+
+func (f *Fake) Snapshot(ctx context.Context, step int, screenshot bool) (*browser.PageSnapshot, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	snap := &browser.PageSnapshot{URL: f.url, Elements: make(browser.ElementMap)}
+	p := f.pages[f.url]
+	if p == nil {
+		return snap, nil
+	}
+	snap.Title = p.Title
+
+	var sb strings.Builder
+	for _, line := range p.Text {
+		fmt.Fprintf(&sb, "- [text] %q\n", line)
+	}
+	for i, el := range p.Elements {
+		id := i + 1
+		snap.Elements[id] = el
+		fmt.Fprintf(&sb, "[%d] [%s] %q", id, el.Role, el.Name)
+		if el.Value != "" {
+			fmt.Fprintf(&sb, " (Val: %s)", el.Value)
+		}
+		sb.WriteString("\n")
+	}
+	snap.Tree = sb.String()
+	if screenshot {
+		snap.ScreenshotBase64 = "ZmFrZQ==" // "fake"
+	}
+	return snap, nil
+}
+
+func (f *Fake) Resolve(ctx context.Context, snap *browser.PageSnapshot, id int) (browser.Element, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	el, ok := snap.Elements[id].(*Element)
+	if !ok {
+		return nil, fmt.Errorf("TargetID %d not found in elements map", id)
+	}
+	for _, cur := range f.pages[f.url].elements() {
+		if cur == el {
+			return el, ctx.Err()
+		}
+	}
+	return nil, fmt.Errorf("element %d %q is no longer on the page", id, el.Name)
+}
+
+func (p *Page) elements() []*Element {
+	if p == nil {
+		return nil
+	}
+	return p.Elements
+}
+
+func (f *Fake) Click(ctx context.Context, el browser.Element) error {
+	e, ok := el.(*Element)
+	if !ok {
+		return fmt.Errorf("element %v was not resolved by this browser", el)
+	}
+	f.mu.Lock()
+	f.record("click %s", e.Name)
+	if e.Href != "" {
+		f.url = e.Href
+	}
+	f.mu.Unlock()
+	if e.OnClick != nil {
+		e.OnClick(f)
+	}
+	return ctx.Err()
+}
+
+func (f *Fake) Type(ctx context.Context, el browser.Element, text string, submit bool) error {
+	e, ok := el.(*Element)
+	if !ok {
+		return fmt.Errorf("element %v was not resolved by this browser", el)
+	}
+	f.mu.Lock()
+	e.Value = text
+	if submit {
+		f.record("type %s %s + enter", e.Name, text)
+	} else {
+		f.record("type %s %s", e.Name, text)
+	}
+	f.mu.Unlock()
+	if submit && e.OnSubmit != nil {
+		e.OnSubmit(f)
+	}
+	return ctx.Err()
+}
+
+// Open switches to the page at url without recording a navigation, for
+// OnClick and OnSubmit handlers.
+func (f *Fake) Open(url string) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.url = url
+}
+
+func (f *Fake) Screenshot(ctx context.Context) ([]byte, error) {
+	return []byte("fake"), ctx.Err()
+}
+
+func (f *Fake) Evaluate(ctx context.Context, expression string, res any) error {
+	f.mu.Lock()
+	f.record("evaluate %s", expression)
+	eval := f.OnEvaluate
+	f.mu.Unlock()
+	if eval != nil {
+		return eval(expression, res)
+	}
+	return ctx.Err()
+}
+
+func (f *Fake) Close() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.closed = true
+}
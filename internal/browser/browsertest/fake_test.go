@@ -0,0 +1,65 @@
+package browsertest
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFake(t *testing.T) {
+	ctx := context.Background()
+	clicked := 0
+	f := New(map[string]*Page{
+		"https://a.test/": {Title: "A", Elements: []*Element{
+			{Role: "textbox", Name: "Email", Value: "old"},
+			{Role: "button", Name: "Next", OnClick: func(*Fake) { clicked++ }},
+			{Role: "link", Name: "B", Href: "https://a.test/b"},
+		}},
+		"https://a.test/b": {Title: "B"},
+	})
+	if err := f.Navigate(ctx, "https://a.test/missing"); !errors.Is(err, ErrNoPage) {
+		t.Fatalf("Navigate to a missing page: %v", err)
+	}
+	if err := f.Navigate(ctx, "https://a.test/"); err != nil {
+		t.Fatal(err)
+	}
+
+	snap, err := f.Snapshot(ctx, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "[1] [textbox] \"Email\" (Val: old)\n[2] [button] \"Next\"\n[3] [link] \"B\"\n"
+	if snap.Tree != want || snap.Title != "A" || len(snap.Elements) != 3 {
+		t.Fatalf("snapshot = %q %q %d", snap.Title, snap.Tree, len(snap.Elements))
+	}
+
+	el, err := f.Resolve(ctx, snap, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Type(ctx, el, "me@a.test", false); err != nil {
+		t.Fatal(err)
+	}
+	el, _ = f.Resolve(ctx, snap, 2)
+	if err := f.Click(ctx, el); err != nil || clicked != 1 {
+		t.Fatalf("Click: %v, clicked %d", err, clicked)
+	}
+	el, _ = f.Resolve(ctx, snap, 3)
+	_ = f.Click(ctx, el)
+	if url, _ := f.Location(ctx); url != "https://a.test/b" {
+		t.Errorf("Location = %q after clicking the link", url)
+	}
+	if _, err := f.Resolve(ctx, snap, 1); err == nil {
+		t.Error("Resolve succeeded on an element of the previous page")
+	}
+
+	_ = f.Navigate(ctx, "https://a.test/")
+	snap, _ = f.Snapshot(ctx, 2, false)
+	if !strings.HasPrefix(snap.Tree, "[1] [textbox] \"Email\" (Val: me@a.test)\n") {
+		t.Errorf("typed value missing: %q", snap.Tree)
+	}
+	if got := len(f.Actions()); got != 6 {
+		t.Errorf("actions = %q", f.Actions())
+	}
+}
@@ -0,0 +1,103 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+)
+
+// Driver is a browser backend. The agent works on pages only through it:
+// Manager drives Chrome over the DevTools protocol, PlaywrightDriver drives
+// Chromium, Firefox or WebKit through Playwright, and browsertest.Fake is a
+// scripted page for tests.
+type Driver interface {
+	// Navigate opens url and waits for the load.
+	Navigate(ctx context.Context, url string) error
+	// Location returns the URL of the current page.
+	Location(ctx context.Context) (string, error)
+	// Snapshot describes the page for the model. Interactive elements are
+	// numbered in the tree; their references are in PageSnapshot.Elements.
+	Snapshot(ctx context.Context, step int, screenshot bool) (*PageSnapshot, error)
+	// Resolve returns a handle of the element numbered id in snap, failing
+	// when it is no longer on the page.
+	Resolve(ctx context.Context, snap *PageSnapshot, id int) (Element, error)
+	// Click scrolls el into view and clicks it, or the clickable element
+	// around it.
+	Click(ctx context.Context, el Element) error
+	// Type replaces the value of the input el by text and, with submit,
+	// presses Enter in it.
+	Type(ctx context.Context, el Element, text string, submit bool) error
+	// Screenshot returns a JPEG of the viewport.
+	Screenshot(ctx context.Context) ([]byte, error)
+	// Evaluate runs a JavaScript expression in the page and, when res is
+	// not nil, stores its JSON result in res.
+	Evaluate(ctx context.Context, expression string, res any) error
+	// Close shuts the browser down.
+	Close()
+}
+
+// Element is a driver's reference to a page element. Snapshots hold one
+// per numbered element; Resolve turns it into a handle for Click and Type.
+// Only the driver that produced an Element can use it.
+type Element any
+
+// Features the agent uses when the driver has them.
+type (
+	// NavigationReporter reports page navigations stopped by a URLPolicy.
+	NavigationReporter interface {
+		BlockedNavigations() []BlockedNavigation
+	}
+	// BlockCounter counts the requests dropped by BlockOptions.
+	BlockCounter interface {
+		BlockStats() BlockStats
+	}
+	// Recoverer reopens a crashed browser; see Manager.Recover. Checkpoint
+	// saves the state Recover restores.
+	Recoverer interface {
+		Crashed() error
+		Recover(ctx context.Context) (string, error)
+		Checkpoint(ctx context.Context)
+	}
+)
+
+// Browser is a Driver opened by Open, with the setup both backends
+// support.
+type Browser interface {
+	Driver
+	NavigationReporter
+	// Text returns the visible text of the current page.
+	Text(ctx context.Context) (string, error)
+	// SetURLPolicy replaces the URL policy; an empty policy lifts all
+	// restrictions.
+	SetURLPolicy(ctx context.Context, p URLPolicy) error
+	// ExportStorageState saves the login of the browser to a file;
+	// ImportStorageState loads it before the first Navigate.
+	ExportStorageState(ctx context.Context, path string) error
+	ImportStorageState(ctx context.Context, path string) error
+	// Remote reports whether the browser was attached to, not launched.
+	Remote() bool
+}
+
+// Open starts the browser of Options.Backend.
+func Open(opts Options) (Browser, error) {
+	switch opts.Backend {
+	case "", BackendChromedp:
+		if opts.Engine != "" && opts.Engine != "chromium" {
+			return nil, fmt.Errorf("browser engine %s needs the playwright backend", opts.Engine)
+		}
+		return NewManager(opts)
+	case BackendPlaywright:
+		return NewPlaywright(opts)
+	default:
+		return nil, fmt.Errorf("unknown browser backend %q (want chromedp or playwright)", opts.Backend)
+	}
+}
+
+var (
+	_ Browser = (*Manager)(nil)
+	_ Browser = (*PlaywrightDriver)(nil)
+
+	_ Driver             = (*Manager)(nil)
+	_ NavigationReporter = (*Manager)(nil)
+	_ BlockCounter       = (*Manager)(nil)
+	_ Recoverer          = (*Manager)(nil)
+)
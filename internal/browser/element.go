@@ -0,0 +1,194 @@
+package browser
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/dom"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+	"github.com/chromedp/chromedp/kb"
+)
+
+// clickScript clicks this, or the clickable element around it: labels of
+// radios and checkboxes click their input, and icons or spans inside
+// buttons and links click the button or link.
+const clickScript = `function() {
+	try {
+		if (this.scrollIntoViewIfNeeded) {
+			this.scrollIntoViewIfNeeded();
+		} else if (this.scrollIntoView) {
+			this.scrollIntoView({ block: "center", inline: "center" });
+		}
+
+		const isClickable = (el) => {
+			if (!el) return false;
+			const tag = (el.tagName || "").toLowerCase();
+			const role = (el.getAttribute && (el.getAttribute("role") || "").toLowerCase()) || "";
+
+			if (tag === "button" || tag === "a") return true;
+			if (tag === "input") {
+				const type = (el.type || "").toLowerCase();
+				if (type === "button" || type === "submit" || type === "radio" || type === "checkbox") return true;
+			}
+			if (tag === "label") return true;
+			if (role === "button" || role === "link" || role === "radio" || role === "checkbox") return true;
+			return false;
+		};
+
+		const clickRadioFromLabel = (label) => {
+			if (!label) return false;
+			const input = label.querySelector("input[type='radio'],input[type='checkbox']");
+			if (input) {
+				input.click();
+				return true;
+			}
+			return false;
+		};
+
+		let el = this;
+
+		if (el.closest) {
+			const directLabel = el.closest("label");
+			if (clickRadioFromLabel(directLabel)) {
+				return;
+			}
+		}
+
+		for (let i = 0; i < 5 && el; i++) {
+			if (isClickable(el)) {
+				if (el.tagName && el.tagName.toLowerCase() === "label") {
+					if (clickRadioFromLabel(el)) return;
+				}
+				el.click();
+				return;
+			}
+			if (el.closest) {
+				const parentLabel = el.closest("label");
+				if (clickRadioFromLabel(parentLabel)) {
+					return;
+				}
+			}
+			el = el.parentElement;
+		}
+
+		this.click();
+	} catch (e) {
+		console.log("click helper error", e);
+	}
+}`
+
+// typeScript sets the value of this to text and fires the events
+// frameworks listen to.
+const typeScript = `function(text) {
+	if (this.scrollIntoViewIfNeeded) {
+		this.scrollIntoViewIfNeeded();
+	} else if (this.scrollIntoView) {
+		this.scrollIntoView({ block: "center", inline: "center" });
+	}
+	this.value = "";
+	this.value = text;
+	this.dispatchEvent(new Event('input', { bubbles: true }));
+	this.dispatchEvent(new Event('change', { bubbles: true }));
+}`
+
+// chromeElement is an element resolved by Manager.
+type chromeElement struct {
+	node   cdp.BackendNodeID
+	object runtime.RemoteObjectID
+}
+
+// Resolve implements Driver.
+func (m *Manager) Resolve(ctx context.Context, snap *PageSnapshot, id int) (Element, error) {
+	node, ok := snap.Elements[id].(cdp.BackendNodeID)
+	if !ok {
+		return nil, fmt.Errorf("TargetID %d not found in elements map", id)
+	}
+	runCtx, cancel := m.Bind(ctx)
+	defer cancel()
+	var obj *runtime.RemoteObject
+	err := chromedp.Run(runCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		obj, err = dom.ResolveNode().WithBackendNodeID(node).Do(ctx)
+		return err
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("resolve node failed: %w", err)
+	}
+	if obj == nil || obj.ObjectID == "" {
+		return nil, fmt.Errorf("object id is empty (node might be detached)")
+	}
+	return chromeElement{node: node, object: obj.ObjectID}, nil
+}
+
+// Click implements Driver.
+func (m *Manager) Click(ctx context.Context, el Element) error {
+	e, ok := el.(chromeElement)
+	if !ok {
+		return fmt.Errorf("element %v was not resolved by this browser", el)
+	}
+	runCtx, cancel := m.Bind(ctx)
+	defer cancel()
+	return chromedp.Run(runCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+		_, _, err := runtime.CallFunctionOn(clickScript).WithObjectID(e.object).Do(ctx)
+		return err
+	}))
+}
+
+// Type implements Driver.
+func (m *Manager) Type(ctx context.Context, el Element, text string, submit bool) error {
+	e, ok := el.(chromeElement)
+	if !ok {
+		return fmt.Errorf("element %v was not resolved by this browser", el)
+	}
+	// The text is passed as an argument so that quotes and backslashes
+	// typed by the model reach the page unchanged.
+	arg, err := json.Marshal(text)
+	if err != nil {
+		return err
+	}
+	runCtx, cancel := m.Bind(ctx)
+	defer cancel()
+	return chromedp.Run(runCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+		_, _, err := runtime.CallFunctionOn(typeScript).
+			WithObjectID(e.object).
+			WithArguments([]*runtime.CallArgument{{Value: arg}}).
+			Do(ctx)
+		if err != nil || !submit {
+			return err
+		}
+		// Enter goes to the focused element; SendKeys would wait for a
+		// selector match forever.
+		if err := dom.Focus().WithBackendNodeID(e.node).Do(ctx); err != nil {
+			return fmt.Errorf("focus for submit failed: %w", err)
+		}
+		return chromedp.KeyEvent(kb.Enter).Do(ctx)
+	}))
+}
+
+// Screenshot implements Driver.
+func (m *Manager) Screenshot(ctx context.Context) ([]byte, error) {
+	runCtx, cancel := m.Bind(ctx)
+	defer cancel()
+	var buf []byte
+	err := chromedp.Run(runCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+		var err error
+		buf, err = captureScreenshot().Do(ctx)
+		return err
+	}))
+	return buf, err
+}
+
+func captureScreenshot() *page.CaptureScreenshotParams {
+	return page.CaptureScreenshot().WithFormat(page.CaptureScreenshotFormatJpeg).WithQuality(50)
+}
+
+// Evaluate implements Driver.
+func (m *Manager) Evaluate(ctx context.Context, expression string, res any) error {
+	runCtx, cancel := m.Bind(ctx)
+	defer cancel()
+	return chromedp.Run(runCtx, chromedp.Evaluate(expression, res))
+}
@@ -0,0 +1,164 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	cdpbrowser "github.com/chromedp/cdproto/browser"
+	"github.com/chromedp/cdproto/emulation"
+	"github.com/chromedp/chromedp"
+	"github.com/chromedp/chromedp/device"
+)
+
+// Emulation makes the tab look like another device, in another country.
+// Empty fields keep the browser's own settings.
+type Emulation struct {
+	// Device is a device preset by name, e.g. "iPhone 13" or "Pixel 5";
+	// see LookupDevice. It sets the viewport, pixel ratio, user agent and
+	// touch support.
+	Device string
+	// Locale is a BCP 47 language tag such as "ru-RU" or "tr-TR", used for
+	// Accept-Language, navigator.language and Intl formatting.
+	Locale string
+	// Timezone is an IANA time zone such as "Europe/Istanbul".
+	Timezone string
+	// Geolocation is reported by navigator.geolocation; the permission is
+	// granted to every site.
+	Geolocation *Geolocation
+}
+
+func (e Emulation) empty() bool {
+	return e.Device == "" && e.Locale == "" && e.Timezone == "" && e.Geolocation == nil
+}
+
+// Geolocation is a position in degrees; Accuracy is in meters and
+// defaults to 100.
+type Geolocation struct {
+	Latitude  float64
+	Longitude float64
+	Accuracy  float64
+}
+
+// ParseGeolocation parses "latitude,longitude" with an optional
+// ",accuracy", e.g. "41.0082,28.9784".
+func ParseGeolocation(s string) (*Geolocation, error) {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 && len(parts) != 3 {
+		return nil, fmt.Errorf("geolocation %q: want latitude,longitude[,accuracy]", s)
+	}
+	var nums []float64
+	for _, p := range parts {
+		n, err := strconv.ParseFloat(strings.TrimSpace(p), 64)
+		if err != nil {
+			return nil, fmt.Errorf("geolocation %q: %w", s, err)
+		}
+		nums = append(nums, n)
+	}
+	g := &Geolocation{Latitude: nums[0], Longitude: nums[1]}
+	if len(nums) == 3 {
+		g.Accuracy = nums[2]
+	}
+	if g.Latitude < -90 || g.Latitude > 90 || g.Longitude < -180 || g.Longitude > 180 || g.Accuracy < 0 {
+		return nil, fmt.Errorf("geolocation %q out of range", s)
+	}
+	return g, nil
+}
+
+// LookupDevice returns the device preset with the given name, ignoring
+// case, spaces and dashes: "iPhone 13", "iphone-13 landscape", "pixel5".
+// The presets are those of Chrome DevTools.
+func LookupDevice(name string) (device.Info, bool) {
+	key := deviceKey(name)
+	for d := device.Reset + 1; d <= device.MotoG4landscape; d++ {
+		if info := d.Device(); deviceKey(info.Name) == key {
+			return info, true
+		}
+	}
+	return device.Info{}, false
+}
+
+// DeviceNames lists the device presets.
+func DeviceNames() []string {
+	var names []string
+	for d := device.Reset + 1; d <= device.MotoG4landscape; d++ {
+		names = append(names, d.Device().Name)
+	}
+	return names
+}
+
+func deviceKey(name string) string {
+	return strings.NewReplacer(" ", "", "-", "", "_", "").Replace(strings.ToLower(name))
+}
+
+// SetEmulation applies e to the tab. Call it before navigating: pages that
+// are already open keep what they read at load time.
+func (m *Manager) SetEmulation(ctx context.Context, e Emulation) error {
+	if e.empty() {
+		return nil
+	}
+	var dev *device.Info
+	if e.Device != "" {
+		info, ok := LookupDevice(e.Device)
+		if !ok {
+			return fmt.Errorf("unknown device %q (see DeviceNames)", e.Device)
+		}
+		dev = &info
+	}
+
+	runCtx, cancel := m.Bind(ctx)
+	defer cancel()
+	m.emulation = e
+	return chromedp.Run(runCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+		if dev != nil {
+			if err := chromedp.Emulate(*dev).Do(ctx); err != nil {
+				return fmt.Errorf("emulate %s: %w", dev.Name, err)
+			}
+		}
+		if e.Locale != "" {
+			// The user agent override carries Accept-Language, so it is
+			// set again with the device's or the browser's own agent.
+			ua := ""
+			if dev != nil {
+				ua = dev.UserAgent
+			} else {
+				_, _, _, agent, _, err := cdpbrowser.GetVersion().Do(ctx)
+				if err != nil {
+					return err
+				}
+				ua = agent
+			}
+			if err := emulation.SetUserAgentOverride(ua).WithAcceptLanguage(e.Locale).Do(ctx); err != nil {
+				return fmt.Errorf("locale %s: %w", e.Locale, err)
+			}
+			if err := emulation.SetLocaleOverride().WithLocale(e.Locale).Do(ctx); err != nil {
+				return fmt.Errorf("locale %s: %w", e.Locale, err)
+			}
+		}
+		if e.Timezone != "" {
+			if err := emulation.SetTimezoneOverride(e.Timezone).Do(ctx); err != nil {
+				return fmt.Errorf("timezone %s: %w", e.Timezone, err)
+			}
+		}
+		if g := e.Geolocation; g != nil {
+			accuracy := g.Accuracy
+			if accuracy == 0 {
+				accuracy = 100
+			}
+			err := cdpbrowser.GrantPermissions([]cdpbrowser.PermissionType{cdpbrowser.PermissionTypeGeolocation}).Do(ctx)
+			if err != nil {
+				return fmt.Errorf("grant geolocation: %w", err)
+			}
+			err = emulation.SetGeolocationOverride().
+				WithLatitude(g.Latitude).
+				WithLongitude(g.Longitude).
+				WithAccuracy(accuracy).
+				Do(ctx)
+			if err != nil {
+				return fmt.Errorf("geolocation: %w", err)
+			}
+		}
+		return nil
+	}))
+}
@@ -0,0 +1,109 @@
+package browser_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/browser"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/fixture"
+)
+
+func TestParseGeolocation(t *testing.T) {
+	g, err := browser.ParseGeolocation("41.0082, 28.9784,50")
+	if err != nil || g.Latitude != 41.0082 || g.Longitude != 28.9784 || g.Accuracy != 50 {
+		t.Errorf("ParseGeolocation = %+v, %v", g, err)
+	}
+	for _, s := range []string{"", "41", "91,0", "a,b", "1,2,3,4"} {
+		if _, err := browser.ParseGeolocation(s); err == nil {
+			t.Errorf("ParseGeolocation(%q) succeeded", s)
+		}
+	}
+}
+
+func TestLookupDevice(t *testing.T) {
+	for _, name := range []string{"iPhone 13", "iphone-13", "IPHONE13"} {
+		if d, ok := browser.LookupDevice(name); !ok || d.Name != "iPhone 13" || d.Width != 390 {
+			t.Errorf("LookupDevice(%q) = %+v, %v", name, d, ok)
+		}
+	}
+	if _, ok := browser.LookupDevice("Nokia 3310"); ok {
+		t.Error("unknown device found")
+	}
+}
+
+func TestEmulation(t *testing.T) {
+	path := fixture.ChromePath()
+	if path == "" {
+		t.Skip("Chrome not found; set CHROME_PATH to run browser tests")
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<meta name="viewport" content="width=device-width"><title>` + r.Header.Get("Accept-Language") + "</title>"))
+	}))
+	defer srv.Close()
+
+	b, err := browser.NewManager(browser.Options{
+		Headless:  browser.Headless,
+		ExecPath:  path,
+		Ephemeral: true,
+		NoSandbox: true,
+		Emulation: browser.Emulation{
+			Device:      "iPhone 13",
+			Locale:      "tr-TR",
+			Timezone:    "Europe/Istanbul",
+			Geolocation: &browser.Geolocation{Latitude: 41.0082, Longitude: 28.9784},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	if err := b.Navigate(context.Background(), srv.URL); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := b.Bind(context.Background())
+	defer cancel()
+	var got struct {
+		Title, UA, Language, Zone string
+		Width                     int
+		Ratio                     float64
+		Touch                     bool
+		Lat, Lon                  float64
+	}
+	err = chromedp.Run(ctx, chromedp.Evaluate(`new Promise(ok => navigator.geolocation.getCurrentPosition(
+		p => ok({
+			Title: document.title, UA: navigator.userAgent, Language: navigator.language,
+			Zone: Intl.DateTimeFormat().resolvedOptions().timeZone,
+			Width: innerWidth, Ratio: devicePixelRatio, Touch: navigator.maxTouchPoints > 0,
+			Lat: p.coords.latitude, Lon: p.coords.longitude,
+		}),
+		e => ok({Title: "geolocation error " + e.message})))`, &got,
+		func(p *runtime.EvaluateParams) *runtime.EvaluateParams { return p.WithAwaitPromise(true) },
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Title != "tr-TR" || got.Language != "tr-TR" {
+		t.Errorf("Accept-Language %q, navigator.language %q; want tr-TR", got.Title, got.Language)
+	}
+	if got.Zone != "Europe/Istanbul" {
+		t.Errorf("time zone = %q", got.Zone)
+	}
+	if got.Width != 390 || got.Ratio != 3 || !got.Touch {
+		t.Errorf("viewport %d @%vx, touch %v; want iPhone 13", got.Width, got.Ratio, got.Touch)
+	}
+	if !strings.Contains(got.UA, "iPhone") {
+		t.Errorf("user agent = %q", got.UA)
+	}
+	if got.Lat != 41.0082 || got.Lon != 28.9784 {
+		t.Errorf("position = %v,%v", got.Lat, got.Lon)
+	}
+}
@@ -0,0 +1,155 @@
+package browser
+
+import (
+	"context"
+	"slices"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// requestFilter is a feature that intercepts requests of the tab through
+// the Fetch domain. Requests matching its patterns are paused and passed to
+// handle, which returns the action answering the request, or nil to leave
+// it to the next filter. A request no filter answers is continued.
+//
+// A filter with auth also answers the authentication challenges of paused
+// requests; nil defers to the next filter, and a challenge nobody answers
+// gets the browser's default response. finished, when set, is told when a
+// request of the tab, by network ID, finished loading or failed.
+type requestFilter struct {
+	name     string
+	patterns []*fetch.RequestPattern
+	handle   func(ev *fetch.EventRequestPaused) chromedp.Action
+	auth     func(ev *fetch.EventAuthRequired) *fetch.AuthChallengeResponse
+	finished func(id network.RequestID)
+}
+
+// setRequestFilter installs f, replacing a filter of the same name, and
+// updates the intercepted patterns. A filter added with first runs before
+// the others.
+func (m *Manager) setRequestFilter(ctx context.Context, f requestFilter, first bool) error {
+	m.filterMu.Lock()
+	m.filters = slices.DeleteFunc(m.filters, func(x requestFilter) bool { return x.name == f.name })
+	if first {
+		m.filters = append([]requestFilter{f}, m.filters...)
+	} else {
+		m.filters = append(m.filters, f)
+	}
+	if !m.listening {
+		m.listenFetch()
+	}
+	m.filterMu.Unlock()
+	return m.syncFetch(ctx)
+}
+
+func (m *Manager) removeRequestFilter(ctx context.Context, name string) error {
+	m.filterMu.Lock()
+	m.filters = slices.DeleteFunc(m.filters, func(x requestFilter) bool { return x.name == name })
+	m.filterMu.Unlock()
+	return m.syncFetch(ctx)
+}
+
+// listenFetch adds the listener of the filters to the tab; filterMu must be
+// held.
+func (m *Manager) listenFetch() {
+	m.listening = true
+	ctx := m.Ctx
+	chromedp.ListenTarget(ctx, func(ev any) { m.onFetchEvent(ctx, ev) })
+}
+
+// syncFetch enables the Fetch domain with the patterns of all filters, or
+// disables it when there are none.
+func (m *Manager) syncFetch(ctx context.Context) error {
+	m.filterMu.Lock()
+	var patterns []*fetch.RequestPattern
+	handleAuth := false
+	for _, f := range m.filters {
+		patterns = append(patterns, f.patterns...)
+		handleAuth = handleAuth || f.auth != nil
+	}
+	m.filterMu.Unlock()
+
+	runCtx, cancel := m.Bind(ctx)
+	defer cancel()
+	if len(patterns) == 0 {
+		return chromedp.Run(runCtx, fetch.Disable())
+	}
+	return chromedp.Run(runCtx, fetch.Enable().WithPatterns(patterns).WithHandleAuthRequests(handleAuth))
+}
+
+// onFetchEvent answers paused requests in tabCtx, the tab the listener was
+// added to.
+func (m *Manager) onFetchEvent(tabCtx context.Context, ev any) {
+	var action chromedp.Action
+	switch ev := ev.(type) {
+	case *fetch.EventRequestPaused:
+		action = m.answerRequest(ev)
+	case *fetch.EventAuthRequired:
+		action = m.answerAuth(ev)
+	case *network.EventLoadingFinished:
+		m.requestFinished(ev.RequestID)
+		return
+	case *network.EventLoadingFailed:
+		m.requestFinished(ev.RequestID)
+		return
+	default:
+		return
+	}
+	// Listeners must not block the event loop.
+	go func() { _ = chromedp.Run(tabCtx, action) }()
+}
+
+func (m *Manager) answerRequest(ev *fetch.EventRequestPaused) chromedp.Action {
+	m.filterMu.Lock()
+	filters := slices.Clone(m.filters)
+	m.filterMu.Unlock()
+
+	for _, f := range filters {
+		if a := f.handle(ev); a != nil {
+			return a
+		}
+	}
+	return fetch.ContinueRequest(ev.RequestID)
+}
+
+func (m *Manager) answerAuth(ev *fetch.EventAuthRequired) chromedp.Action {
+	m.filterMu.Lock()
+	filters := slices.Clone(m.filters)
+	m.filterMu.Unlock()
+
+	for _, f := range filters {
+		if f.auth == nil {
+			continue
+		}
+		if r := f.auth(ev); r != nil {
+			return fetch.ContinueWithAuth(ev.RequestID, r)
+		}
+	}
+	return fetch.ContinueWithAuth(ev.RequestID, &fetch.AuthChallengeResponse{
+		Response: fetch.AuthChallengeResponseResponseDefault,
+	})
+}
+
+func (m *Manager) requestFinished(id network.RequestID) {
+	m.filterMu.Lock()
+	filters := slices.Clone(m.filters)
+	m.filterMu.Unlock()
+
+	for _, f := range filters {
+		if f.finished != nil {
+			f.finished(id)
+		}
+	}
+}
+
+// mainFrame returns the frame ID of the tab's page, which equals its target
+// ID.
+func (m *Manager) mainFrame() cdp.FrameID {
+	if c := chromedp.FromContext(m.Ctx); c != nil && c.Target != nil {
+		return cdp.FrameID(c.Target.TargetID)
+	}
+	return ""
+}
@@ -0,0 +1,56 @@
+//go:build !unix
+
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// profileLock is a lock file created exclusively. A lock left behind by a
+// crashed agent is taken over when its process is gone.
+type profileLock struct {
+	path string
+}
+
+func acquireLock(path string) (*profileLock, error) {
+	for attempt := 0; attempt < 2; attempt++ {
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+		if err == nil {
+			_, _ = f.WriteString(strconv.Itoa(os.Getpid()) + "\n")
+			f.Close()
+			return &profileLock{path: path}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, err
+		}
+
+		owner, _ := os.ReadFile(path)
+		pid, err := strconv.Atoi(strings.TrimSpace(string(owner)))
+		if err == nil && processAlive(pid) {
+			return nil, fmt.Errorf("%w by another agent (pid %d)", ErrProfileInUse, pid)
+		}
+		_ = os.Remove(path)
+	}
+	return nil, fmt.Errorf("%w by another agent", ErrProfileInUse)
+}
+
+func (l *profileLock) release() {
+	if l == nil || l.path == "" {
+		return
+	}
+	_ = os.Remove(l.path)
+	l.path = ""
+}
+
+func processAlive(pid int) bool {
+	p, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	p.Release()
+	return true
+}
@@ -2,8 +2,9 @@ package browser
 
 import (
 	"context"
+	"fmt"
 	"os"
-	"path/filepath"
+	"sync"
 	"time"
 
 	"github.com/chromedp/chromedp"
@@ -12,41 +13,201 @@ import (
 type Manager struct {
 	Ctx    context.Context
 	Cancel context.CancelFunc
+
+	cancelAlloc context.CancelFunc
+	// proc is the Chrome process started by launch.
+	proc       *os.Process
+	profileDir string
+	tempDir    string
+	lock       *profileLock
+
+	// remote is set for a browser reached through Options.RemoteURL;
+	// attached when the tab was already open.
+	remote   bool
+	attached bool
+
+	// live is Ctx, cancelled when the tab's renderer crashes so that
+	// actions in flight return instead of waiting for it forever.
+	liveMu     sync.Mutex
+	live       context.Context
+	liveCancel context.CancelFunc
+
+	// opts relaunches or reconnects the browser in Recover. emulation is
+	// the last one set; lastURL and lastState are restored after a crash.
+	// lastState was read on stateOrigin at stateAt.
+	opts        Options
+	emulation   Emulation
+	lastURL     string
+	lastState   *StorageState
+	stateOrigin string
+	stateAt     time.Time
+
+	filterMu   sync.Mutex
+	filters    []requestFilter
+	listening  bool
+	policy     compiledPolicy
+	blocked    []BlockedNavigation
+	blockStats BlockStats
 }
 
-func NewManager() *Manager {
-	userDir := filepath.Join(os.TempDir(), "go-browser-ai-agent-profile")
+// NewManager launches Chrome with opts and opens a tab. With
+// Options.RemoteURL it connects to a running Chrome instead.
+func NewManager(opts Options) (*Manager, error) {
+	if opts.RemoteURL != "" {
+		return newRemoteManager(opts)
+	}
+	opts = opts.withDefaults()
+	if _, _, _, err := opts.Proxy.parse(); err != nil {
+		return nil, err
+	}
+
+	userDir, temp, err := resolveProfile(opts)
+	if err != nil {
+		return nil, err
+	}
+	m := &Manager{profileDir: userDir, opts: opts}
+	if temp {
+		m.tempDir = userDir
+	}
+	if userDir != "" {
+		if m.lock, err = lockProfile(userDir); err != nil {
+			m.Close()
+			return nil, err
+		}
+	}
 
-	opts := append(
-		chromedp.DefaultExecAllocatorOptions[:],
-		chromedp.UserDataDir(userDir),
-		chromedp.Flag("headless", false),
-		chromedp.Flag("disable-gpu", false),
-		chromedp.Flag("enable-automation", false),
-		chromedp.Flag("disable-extensions", false),
-		chromedp.WindowSize(1280, 800),
-	)
+	if err := m.launch(); err != nil {
+		m.Close()
+		return nil, err
+	}
+	if err := m.applyOptions(opts); err != nil {
+		m.Close()
+		return nil, err
+	}
+	return m, nil
+}
 
-	allocCtx, _ := chromedp.NewExecAllocator(context.Background(), opts...)
-	ctx, cancel := chromedp.NewContext(allocCtx)
+// launch starts Chrome with m.opts and opens the tab.
+func (m *Manager) launch() error {
+	allocCtx, cancelAlloc := chromedp.NewExecAllocator(context.Background(), m.opts.allocatorOptions(m.profileDir)...)
+	m.Ctx, m.Cancel = chromedp.NewContext(allocCtx)
+	m.cancelAlloc = cancelAlloc
 
-	if err := chromedp.Run(ctx); err != nil {
-		cancel()
-		panic(err)
+	// The first Run allocates the browser. It must use the long-lived
+	// context: a browser started through a derived context is shut down
+	// when that context is cancelled.
+	if err := chromedp.Run(m.Ctx); err != nil {
+		return fmt.Errorf("launch chrome: %w", err)
 	}
+	m.proc = chromedp.FromContext(m.Ctx).Browser.Process()
+	return m.watch()
+}
 
-	return &Manager{
-		Ctx:    ctx,
-		Cancel: cancel,
+// applyOptions sets up the tab once it is open.
+func (m *Manager) applyOptions(opts Options) error {
+	if err := m.SetEmulation(context.Background(), opts.Emulation); err != nil {
+		return err
+	}
+	if len(opts.Policy.Allow) > 0 || len(opts.Policy.Deny) > 0 {
+		if err := m.SetURLPolicy(context.Background(), opts.Policy); err != nil {
+			return fmt.Errorf("url policy: %w", err)
+		}
+	}
+	if !opts.Block.empty() {
+		if err := m.SetBlocking(context.Background(), opts.Block); err != nil {
+			return fmt.Errorf("request blocking: %w", err)
+		}
+	}
+	_, user, password, err := opts.Proxy.parse()
+	if err != nil {
+		return err
+	}
+	if user != "" || len(opts.Credentials) > 0 {
+		if err := m.setAuth(context.Background(), user, password, opts.Credentials); err != nil {
+			return err
+		}
 	}
+	return nil
 }
 
+// Close shuts the browser down and removes a temporary profile. A remote
+// browser keeps running: Close only disconnects, and closes the tab if it
+// was opened by NewManager.
 func (m *Manager) Close() {
+	if m.attached {
+		m.detach()
+	}
 	if m.Cancel != nil {
 		m.Cancel()
 	}
+	if m.cancelAlloc != nil {
+		m.cancelAlloc()
+	}
+	m.lock.release()
+	m.lock = nil
+	if m.tempDir != "" {
+		_ = os.RemoveAll(m.tempDir)
+		m.tempDir = ""
+	}
+}
+
+// Remote reports whether the browser was reached through a remote
+// debugging URL.
+func (m *Manager) Remote() bool {
+	return m.remote
+}
+
+// ProfileDir returns the Chrome profile directory in use; empty means
+// Chrome's default.
+func (m *Manager) ProfileDir() string {
+	return m.profileDir
 }
 
 func (m *Manager) WithTimeout(d time.Duration) (context.Context, context.CancelFunc) {
-	return context.WithTimeout(m.Ctx, d)
+	return context.WithTimeout(m.liveCtx(), d)
+}
+
+// Bind returns a browser context that is also cancelled when ctx is done,
+// so chromedp actions stop as soon as the caller cancels. Cancelling the
+// returned context does not close the tab.
+func (m *Manager) Bind(ctx context.Context) (context.Context, context.CancelFunc) {
+	runCtx, cancel := context.WithCancel(m.liveCtx())
+	stop := context.AfterFunc(ctx, cancel)
+	return runCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// Navigate opens url in the tab. A URL the URLPolicy does not allow fails
+// with ErrNavigationBlocked without leaving the current page.
+func (m *Manager) Navigate(ctx context.Context, url string) error {
+	m.filterMu.Lock()
+	policy := m.policy
+	m.filterMu.Unlock()
+	if err := policy.check(url, true); err != nil {
+		return err
+	}
+
+	runCtx, cancel := m.Bind(ctx)
+	defer cancel()
+	return chromedp.Run(runCtx, chromedp.Navigate(url))
+}
+
+// Text returns the visible text of the current page.
+func (m *Manager) Text(ctx context.Context) (string, error) {
+	runCtx, cancel := m.Bind(ctx)
+	defer cancel()
+	var text string
+	err := chromedp.Run(runCtx, chromedp.Evaluate(`document.body ? document.body.innerText : ""`, &text))
+	return text, err
+}
+
+// Location returns the URL of the current page.
+func (m *Manager) Location(ctx context.Context) (string, error) {
+	runCtx, cancel := m.Bind(ctx)
+	defer cancel()
+	var url string
+	err := chromedp.Run(runCtx, chromedp.Location(&url))
+	return url, err
 }
@@ -0,0 +1,197 @@
+package browser
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/chromedp/chromedp"
+)
+
+// HeadlessMode selects how Chrome runs without a window.
+type HeadlessMode string
+
+const (
+	// HeadlessOff shows a browser window.
+	HeadlessOff HeadlessMode = ""
+	// Headless passes --headless. Recent Chrome builds run the new
+	// headless mode for it; chrome-headless-shell runs the old one.
+	Headless HeadlessMode = "headless"
+	// HeadlessNew passes --headless=new, the full browser without a window.
+	HeadlessNew HeadlessMode = "new"
+)
+
+// ParseHeadless parses a headless mode given on the command line: "off",
+// "on" or "new".
+func ParseHeadless(s string) (HeadlessMode, error) {
+	switch strings.ToLower(s) {
+	case "", "off", "false":
+		return HeadlessOff, nil
+	case "on", "true", "headless":
+		return Headless, nil
+	case "new":
+		return HeadlessNew, nil
+	default:
+		return "", fmt.Errorf("unknown headless mode %q (want off, on or new)", s)
+	}
+}
+
+// Backend selects the Driver that Open returns.
+type Backend string
+
+const (
+	// BackendChromedp drives Chrome over the DevTools protocol; see Manager.
+	BackendChromedp Backend = "chromedp"
+	// BackendPlaywright drives Chromium, Firefox or WebKit through
+	// Playwright; see PlaywrightDriver.
+	BackendPlaywright Backend = "playwright"
+)
+
+// Options configures the Chrome instance launched by NewManager.
+type Options struct {
+	// Backend is the Driver opened by Open, BackendChromedp when empty.
+	// Engine is the browser of BackendPlaywright: "chromium" (default),
+	// "firefox" or "webkit".
+	Backend Backend
+	Engine  string
+
+	// RemoteURL attaches to a running Chrome through its DevTools endpoint,
+	// ws://host:port/devtools/browser/<id> or http://host:port, instead of
+	// launching one; the launch options below are then ignored. RemoteTab
+	// selects the tab by target ID or URL substring; empty opens a new tab.
+	RemoteURL string
+	RemoteTab string
+
+	Headless HeadlessMode
+
+	WindowWidth  int
+	WindowHeight int
+
+	// ExecPath is the Chrome binary; empty means the first Chrome found on
+	// the system.
+	ExecPath string
+
+	// UserDataDir is the profile directory. Profile selects a named
+	// persistent profile under ProfileRoot (default DefaultProfileRoot())
+	// instead. Ephemeral uses a fresh temporary profile, removed by Close;
+	// it starts as a copy of ProfileTemplate when set. A profile can only
+	// be used by one Manager at a time.
+	UserDataDir     string
+	Profile         string
+	ProfileRoot     string
+	Ephemeral       bool
+	ProfileTemplate string
+
+	// NoSandbox disables the Chrome sandbox, needed when running as root
+	// or in most containers.
+	NoSandbox bool
+
+	// Flags are extra command line switches.
+	Flags Flags
+
+	// Policy limits the sites the tab may load; see URLPolicy. Block drops
+	// requests the agent does not need. Both also apply to a remote browser.
+	Policy URLPolicy
+	Block  BlockOptions
+
+	// Emulation sets the device, locale, time zone and location the pages
+	// see, from the first navigation on.
+	Emulation Emulation
+
+	// Proxy routes traffic through a proxy server; a remote browser only
+	// takes its credentials. Credentials log in to sites protected by HTTP
+	// authentication, such as staging servers.
+	Proxy       Proxy
+	Credentials []SiteCredentials
+}
+
+// Flags maps Chrome switches, by name without the leading dashes, to their
+// values. A true value passes the bare switch, false removes it. Flags is a
+// flag.Value accepting "name" or "name=value".
+type Flags map[string]any
+
+// DefaultOptions returns the options of an interactive run: a visible
+// 1280x800 window with a persistent profile in the temp directory.
+func DefaultOptions() Options {
+	return Options{
+		WindowWidth:  1280,
+		WindowHeight: 800,
+		UserDataDir:  filepath.Join(os.TempDir(), "go-browser-ai-agent-profile"),
+	}
+}
+
+func (o Options) withDefaults() Options {
+	def := DefaultOptions()
+	if o.WindowWidth <= 0 || o.WindowHeight <= 0 {
+		o.WindowWidth, o.WindowHeight = def.WindowWidth, def.WindowHeight
+	}
+	return o
+}
+
+// allocatorOptions translates o into chromedp allocator options. userDir
+// is the resolved profile directory.
+func (o Options) allocatorOptions(userDir string) []chromedp.ExecAllocatorOption {
+	opts := append(
+		chromedp.DefaultExecAllocatorOptions[:],
+		chromedp.WindowSize(o.WindowWidth, o.WindowHeight),
+	)
+
+	switch o.Headless {
+	case HeadlessOff:
+		opts = append(opts,
+			chromedp.Flag("headless", false),
+			chromedp.Flag("disable-gpu", false),
+			chromedp.Flag("enable-automation", false),
+			chromedp.Flag("disable-extensions", false),
+		)
+	case HeadlessNew:
+		opts = append(opts, chromedp.Flag("headless", "new"))
+	}
+
+	if userDir != "" {
+		opts = append(opts, chromedp.UserDataDir(userDir))
+	}
+	if o.ExecPath != "" {
+		opts = append(opts, chromedp.ExecPath(o.ExecPath))
+	}
+	if o.NoSandbox {
+		opts = append(opts, chromedp.NoSandbox)
+	}
+	if server, _, _, err := o.Proxy.parse(); err == nil && server != "" {
+		opts = append(opts, chromedp.ProxyServer(server))
+		if len(o.Proxy.Bypass) > 0 {
+			opts = append(opts, chromedp.Flag("proxy-bypass-list", strings.Join(o.Proxy.Bypass, ";")))
+		}
+	}
+	for name, value := range o.Flags {
+		opts = append(opts, chromedp.Flag(strings.TrimLeft(name, "-"), value))
+	}
+	return opts
+}
+
+func (f *Flags) String() string {
+	parts := make([]string, 0, len(*f))
+	for name, value := range *f {
+		parts = append(parts, fmt.Sprintf("%s=%v", name, value))
+	}
+	sort.Strings(parts)
+	return strings.Join(parts, " ")
+}
+
+func (f *Flags) Set(s string) error {
+	name, value, hasValue := strings.Cut(strings.TrimLeft(s, "-"), "=")
+	if name == "" {
+		return fmt.Errorf("empty Chrome flag %q", s)
+	}
+	if *f == nil {
+		*f = make(Flags)
+	}
+	if hasValue {
+		(*f)[name] = value
+	} else {
+		(*f)[name] = true
+	}
+	return nil
+}
@@ -0,0 +1,128 @@
+package browser_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/chromedp/chromedp"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/browser"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/fixture"
+)
+
+func TestFlagsSet(t *testing.T) {
+	var f browser.Flags
+	for _, s := range []string{"--disable-web-security", "lang=de-DE", "proxy-server=http://p:1=2"} {
+		if err := f.Set(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if f["disable-web-security"] != true || f["lang"] != "de-DE" || f["proxy-server"] != "http://p:1=2" {
+		t.Errorf("flags = %v", f)
+	}
+	if got := f.String(); got != "disable-web-security=true lang=de-DE proxy-server=http://p:1=2" {
+		t.Errorf("String() = %q", got)
+	}
+	if err := f.Set("--"); err == nil {
+		t.Error("empty flag accepted")
+	}
+}
+
+func TestParseHeadless(t *testing.T) {
+	for in, want := range map[string]browser.HeadlessMode{
+		"": browser.HeadlessOff, "off": browser.HeadlessOff, "on": browser.Headless, "new": browser.HeadlessNew,
+	} {
+		if got, err := browser.ParseHeadless(in); err != nil || got != want {
+			t.Errorf("ParseHeadless(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := browser.ParseHeadless("sometimes"); err == nil {
+		t.Error("unknown mode accepted")
+	}
+}
+
+func TestNewManagerLaunchError(t *testing.T) {
+	_, err := browser.NewManager(browser.Options{
+		Headless: browser.Headless,
+		ExecPath: filepath.Join(t.TempDir(), "no-such-chrome"),
+	})
+	if err == nil {
+		t.Fatal("launch with a missing binary succeeded")
+	}
+}
+
+func TestNewManagerEphemeralProfile(t *testing.T) {
+	path := fixture.ChromePath()
+	if path == "" {
+		t.Skip("Chrome not found; set CHROME_PATH to run browser tests")
+	}
+
+	b, err := browser.NewManager(browser.Options{
+		Headless:     browser.Headless,
+		ExecPath:     path,
+		NoSandbox:    true,
+		Ephemeral:    true,
+		WindowWidth:  800,
+		WindowHeight: 600,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	site := fixture.NewSite()
+	defer site.Close()
+	if err := b.Navigate(context.Background(), site.URL("/")); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := b.Bind(context.Background())
+	defer cancel()
+	var width int
+	if err := chromedp.Run(ctx, chromedp.Evaluate("window.outerWidth", &width)); err != nil {
+		t.Fatal(err)
+	}
+	if width != 800 {
+		t.Errorf("window width = %d, want 800", width)
+	}
+
+	dir := b.ProfileDir()
+	if _, err := os.Stat(filepath.Join(dir, "Default")); err != nil {
+		t.Fatalf("profile %q not used by Chrome: %v", dir, err)
+	}
+
+	b.Close()
+	if _, err := os.Stat(dir); !os.IsNotExist(err) {
+		t.Errorf("temporary profile %s not removed (stat: %v)", dir, err)
+	}
+}
+
+func TestNewManagerProfileInUse(t *testing.T) {
+	path := fixture.ChromePath()
+	if path == "" {
+		t.Skip("Chrome not found; set CHROME_PATH to run browser tests")
+	}
+	opts := browser.Options{
+		Headless:    browser.Headless,
+		ExecPath:    path,
+		NoSandbox:   true,
+		Profile:     "work",
+		ProfileRoot: t.TempDir(),
+	}
+
+	first, err := browser.NewManager(opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := browser.NewManager(opts); !errors.Is(err, browser.ErrProfileInUse) {
+		t.Fatalf("second manager: err = %v, want ErrProfileInUse", err)
+	}
+
+	first.Close()
+	second, err := browser.NewManager(opts)
+	if err != nil {
+		t.Fatalf("profile not released by Close: %v", err)
+	}
+	second.Close()
+}
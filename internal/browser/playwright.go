@@ -0,0 +1,603 @@
+package browser
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// ErrNoPlaywright is returned by NewPlaywright when the Playwright driver
+// is not installed.
+var ErrNoPlaywright = errors.New("playwright is not installed (run `go run github.com/playwright-community/playwright-go/cmd/playwright install`)")
+
+// PlaywrightDriver is a Driver on Playwright, which also runs Firefox and
+// WebKit. It needs the Playwright driver and browsers installed:
+//
+//	go run github.com/playwright-community/playwright-go/cmd/playwright install --with-deps
+//
+// Every run starts from a fresh browser context and UserDataDir is not
+// used; keep logins with ImportStorageState. Remote browsers, profiles,
+// request blocking and crash recovery are only supported by Manager.
+type PlaywrightDriver struct {
+	pw      *playwright.Playwright
+	browser playwright.Browser
+	opts    playwright.BrowserNewContextOptions
+
+	mu      sync.Mutex
+	context playwright.BrowserContext
+	page    playwright.Page
+	// gen numbers the snapshots, so that Resolve does not find an element
+	// of an older one.
+	gen     int
+	policy  compiledPolicy
+	blocked []BlockedNavigation
+}
+
+// NewPlaywright starts the Playwright browser Options.Engine and opens a
+// page.
+func NewPlaywright(opts Options) (*PlaywrightDriver, error) {
+	opts = opts.withDefaults()
+	launch, err := opts.playwrightLaunch()
+	if err != nil {
+		return nil, err
+	}
+	ctxOpts, err := opts.playwrightContext()
+	if err != nil {
+		return nil, err
+	}
+	policy, err := opts.Policy.compile()
+	if err != nil {
+		return nil, fmt.Errorf("url policy: %w", err)
+	}
+
+	pw, err := playwright.Run(&playwright.RunOptions{SkipInstallBrowsers: true})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrNoPlaywright, err)
+	}
+	d := &PlaywrightDriver{pw: pw, opts: ctxOpts, policy: policy}
+
+	var engine playwright.BrowserType
+	switch opts.Engine {
+	case "", "chromium":
+		engine = pw.Chromium
+	case "firefox":
+		engine = pw.Firefox
+	case "webkit":
+		engine = pw.WebKit
+	}
+	if d.browser, err = engine.Launch(launch); err != nil {
+		d.Close()
+		return nil, fmt.Errorf("launch %s: %w", engine.Name(), err)
+	}
+	if err := d.newContext(nil); err != nil {
+		d.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+// playwrightLaunch translates o into Playwright launch options, rejecting
+// those only Manager supports.
+func (o Options) playwrightLaunch() (playwright.BrowserTypeLaunchOptions, error) {
+	var launch playwright.BrowserTypeLaunchOptions
+	switch {
+	case o.RemoteURL != "":
+		return launch, errors.New("the playwright backend cannot attach to a remote browser")
+	case o.Profile != "" || o.Ephemeral || o.ProfileTemplate != "":
+		return launch, errors.New("the playwright backend has no profiles; keep logins with a storage state")
+	case !o.Block.empty():
+		return launch, errors.New("the playwright backend does not block requests")
+	}
+	switch o.Engine {
+	case "", "chromium", "firefox", "webkit":
+	default:
+		return launch, fmt.Errorf("unknown browser engine %q (want chromium, firefox or webkit)", o.Engine)
+	}
+
+	launch.Headless = playwright.Bool(o.Headless != HeadlessOff)
+	if o.ExecPath != "" {
+		launch.ExecutablePath = playwright.String(o.ExecPath)
+	}
+	for name, value := range o.Flags {
+		switch value {
+		case true:
+			launch.Args = append(launch.Args, "--"+strings.TrimLeft(name, "-"))
+		case false:
+			launch.IgnoreDefaultArgs = append(launch.IgnoreDefaultArgs, "--"+strings.TrimLeft(name, "-"))
+		default:
+			launch.Args = append(launch.Args, fmt.Sprintf("--%s=%v", strings.TrimLeft(name, "-"), value))
+		}
+	}
+
+	server, user, password, err := o.Proxy.parse()
+	if err != nil {
+		return launch, err
+	}
+	if server != "" {
+		launch.Proxy = &playwright.Proxy{Server: server}
+		if len(o.Proxy.Bypass) > 0 {
+			launch.Proxy.Bypass = playwright.String(strings.Join(o.Proxy.Bypass, ","))
+		}
+		if user != "" {
+			launch.Proxy.Username = playwright.String(user)
+			launch.Proxy.Password = playwright.String(password)
+		}
+	}
+	return launch, nil
+}
+
+// playwrightContext translates the viewport, emulation and site
+// credentials of o into browser context options.
+func (o Options) playwrightContext() (playwright.BrowserNewContextOptions, error) {
+	ctxOpts := playwright.BrowserNewContextOptions{
+		Viewport: &playwright.Size{Width: o.WindowWidth, Height: o.WindowHeight},
+	}
+	if o.Headless == HeadlessOff {
+		// A visible window keeps its own size.
+		ctxOpts.Viewport = nil
+		ctxOpts.NoViewport = playwright.Bool(true)
+	}
+
+	e := o.Emulation
+	if e.Device != "" {
+		dev, ok := LookupDevice(e.Device)
+		if !ok {
+			return ctxOpts, fmt.Errorf("unknown device %q (see DeviceNames)", e.Device)
+		}
+		ctxOpts.NoViewport = nil
+		ctxOpts.Viewport = &playwright.Size{Width: int(dev.Width), Height: int(dev.Height)}
+		ctxOpts.UserAgent = playwright.String(dev.UserAgent)
+		ctxOpts.DeviceScaleFactor = playwright.Float(dev.Scale)
+		ctxOpts.IsMobile = playwright.Bool(dev.Mobile)
+		ctxOpts.HasTouch = playwright.Bool(dev.Touch)
+	}
+	if e.Locale != "" {
+		ctxOpts.Locale = playwright.String(e.Locale)
+	}
+	if e.Timezone != "" {
+		ctxOpts.TimezoneId = playwright.String(e.Timezone)
+	}
+	if g := e.Geolocation; g != nil {
+		accuracy := g.Accuracy
+		if accuracy == 0 {
+			accuracy = 100
+		}
+		ctxOpts.Geolocation = &playwright.Geolocation{Latitude: g.Latitude, Longitude: g.Longitude, Accuracy: &accuracy}
+		ctxOpts.Permissions = []string{"geolocation"}
+	}
+
+	// Playwright answers the challenges of a single origin.
+	switch len(o.Credentials) {
+	case 0:
+	case 1:
+		c := o.Credentials[0]
+		origin, err := credentialOrigin(c.Site)
+		if err != nil {
+			return ctxOpts, err
+		}
+		ctxOpts.HttpCredentials = &playwright.HttpCredentials{Username: c.Username, Password: c.Password, Origin: &origin}
+	default:
+		return ctxOpts, errors.New("the playwright backend takes credentials for one site only")
+	}
+	return ctxOpts, nil
+}
+
+// credentialOrigin returns the origin of a credentials site rule, which
+// must name one: a scheme and a host, without wildcard or path.
+func credentialOrigin(site string) (string, error) {
+	r, err := parseURLRule(site)
+	if err != nil {
+		return "", fmt.Errorf("credentials: %w", err)
+	}
+	if r.scheme == "" || r.subdomain || r.path != "" {
+		return "", fmt.Errorf("credentials: the playwright backend needs an origin such as https://staging.example.com, got %q", site)
+	}
+	origin := r.scheme + "://" + r.host
+	if r.port != "" {
+		origin += ":" + r.port
+	}
+	return origin, nil
+}
+
+// newContext replaces the browser context by a fresh one holding state,
+// and opens its page.
+func (d *PlaywrightDriver) newContext(state *StorageState) error {
+	opts := d.opts
+	if state != nil {
+		var s playwright.OptionalStorageState
+		if err := convertJSON(state, &s); err != nil {
+			return err
+		}
+		opts.StorageState = &s
+	}
+	bctx, err := d.browser.NewContext(opts)
+	if err != nil {
+		return fmt.Errorf("new browser context: %w", err)
+	}
+	page, err := bctx.NewPage()
+	if err != nil {
+		_ = bctx.Close()
+		return fmt.Errorf("new page: %w", err)
+	}
+
+	d.mu.Lock()
+	old := d.context
+	d.context, d.page, d.gen = bctx, page, 0
+	policy := d.policy
+	d.mu.Unlock()
+	if old != nil {
+		_ = old.Close()
+	}
+	return d.route(policy)
+}
+
+// Close shuts the browser and Playwright down.
+func (d *PlaywrightDriver) Close() {
+	if d.browser != nil {
+		_ = d.browser.Close()
+	}
+	if d.pw != nil {
+		_ = d.pw.Stop()
+	}
+}
+
+// Remote reports false: the driver always launches its browser.
+func (d *PlaywrightDriver) Remote() bool {
+	return false
+}
+
+func (d *PlaywrightDriver) current() (playwright.Page, int) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.page, d.gen
+}
+
+// timeout returns the time left until the deadline of ctx in
+// milliseconds, nil without a deadline.
+func timeout(ctx context.Context) *float64 {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return nil
+	}
+	return playwright.Float(max(float64(time.Until(deadline).Milliseconds()), 1))
+}
+
+// Navigate implements Driver. A URL the URLPolicy does not allow fails
+// with ErrNavigationBlocked without leaving the current page.
+func (d *PlaywrightDriver) Navigate(ctx context.Context, url string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	d.mu.Lock()
+	policy := d.policy
+	d.mu.Unlock()
+	if err := policy.check(url, true); err != nil {
+		return err
+	}
+	page, _ := d.current()
+	_, err := page.Goto(url, playwright.PageGotoOptions{Timeout: timeout(ctx)})
+	return err
+}
+
+// Location implements Driver.
+func (d *PlaywrightDriver) Location(ctx context.Context) (string, error) {
+	page, _ := d.current()
+	return page.URL(), ctx.Err()
+}
+
+// Text returns the visible text of the current page.
+func (d *PlaywrightDriver) Text(ctx context.Context) (string, error) {
+	var text string
+	err := d.Evaluate(ctx, `document.body ? document.body.innerText : ""`, &text)
+	return text, err
+}
+
+// Snapshot implements Driver. The tree is built in the page, as Firefox
+// and WebKit have no accessibility tree over the wire; numbered elements
+// are tagged with a data-agent-id attribute, and Elements holds their
+// selectors.
+func (d *PlaywrightDriver) Snapshot(ctx context.Context, step int, screenshot bool) (*PageSnapshot, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	d.mu.Lock()
+	d.gen++
+	page, gen := d.page, d.gen
+	d.mu.Unlock()
+
+	raw, err := page.Evaluate(snapshotScript, gen)
+	if err != nil {
+		return nil, fmt.Errorf("playwright snapshot failed: %w", err)
+	}
+	var nodes []struct {
+		ID    int    `json:"id"`
+		Role  string `json:"role"`
+		Name  string `json:"name"`
+		Value string `json:"value"`
+	}
+	if err := convertJSON(raw, &nodes); err != nil {
+		return nil, fmt.Errorf("playwright snapshot failed: %w", err)
+	}
+
+	snap := &PageSnapshot{URL: page.URL(), Elements: make(ElementMap)}
+	if snap.Title, err = page.Title(); err != nil {
+		return nil, fmt.Errorf("playwright snapshot failed: %w", err)
+	}
+	var sb strings.Builder
+	for _, n := range nodes {
+		if n.ID != 0 {
+			snap.Elements[n.ID] = fmt.Sprintf(`[data-agent-id="%d-%d"]`, gen, n.ID)
+		}
+		writeTreeLine(&sb, n.ID, n.Role, n.Name, n.Value)
+	}
+	snap.Tree = sb.String()
+
+	if screenshot {
+		buf, err := d.Screenshot(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("playwright snapshot failed: %w", err)
+		}
+		snap.ScreenshotBase64 = base64.StdEncoding.EncodeToString(buf)
+	}
+	return snap, nil
+}
+
+// Resolve implements Driver.
+func (d *PlaywrightDriver) Resolve(ctx context.Context, snap *PageSnapshot, id int) (Element, error) {
+	selector, ok := snap.Elements[id].(string)
+	if !ok {
+		return nil, fmt.Errorf("TargetID %d not found in elements map", id)
+	}
+	page, _ := d.current()
+	loc := page.Locator(selector)
+	n, err := loc.Count()
+	if err != nil {
+		return nil, fmt.Errorf("resolve element failed: %w", err)
+	}
+	if n == 0 {
+		return nil, fmt.Errorf("element %d is no longer on the page", id)
+	}
+	return loc.First(), ctx.Err()
+}
+
+// Click implements Driver.
+func (d *PlaywrightDriver) Click(ctx context.Context, el Element) error {
+	loc, ok := el.(playwright.Locator)
+	if !ok {
+		return fmt.Errorf("element %v was not resolved by this browser", el)
+	}
+	_, err := loc.Evaluate("el => ("+clickScript+").call(el)", nil, playwright.LocatorEvaluateOptions{Timeout: timeout(ctx)})
+	return err
+}
+
+// Type implements Driver.
+func (d *PlaywrightDriver) Type(ctx context.Context, el Element, text string, submit bool) error {
+	loc, ok := el.(playwright.Locator)
+	if !ok {
+		return fmt.Errorf("element %v was not resolved by this browser", el)
+	}
+	_, err := loc.Evaluate("(el, text) => ("+typeScript+").call(el, text)", text, playwright.LocatorEvaluateOptions{Timeout: timeout(ctx)})
+	if err != nil || !submit {
+		return err
+	}
+	return loc.Press("Enter", playwright.LocatorPressOptions{Timeout: timeout(ctx)})
+}
+
+// Screenshot implements Driver.
+func (d *PlaywrightDriver) Screenshot(ctx context.Context) ([]byte, error) {
+	page, _ := d.current()
+	return page.Screenshot(playwright.PageScreenshotOptions{
+		Type:    playwright.ScreenshotTypeJpeg,
+		Quality: playwright.Int(50),
+		Timeout: timeout(ctx),
+	})
+}
+
+// Evaluate implements Driver.
+func (d *PlaywrightDriver) Evaluate(ctx context.Context, expression string, res any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	page, _ := d.current()
+	v, err := page.Evaluate(expression)
+	if err != nil || res == nil {
+		return err
+	}
+	return convertJSON(v, res)
+}
+
+// SetURLPolicy replaces the URL policy of the browser context; an empty
+// policy lifts all restrictions.
+func (d *PlaywrightDriver) SetURLPolicy(ctx context.Context, p URLPolicy) error {
+	c, err := p.compile()
+	if err != nil {
+		return err
+	}
+	d.mu.Lock()
+	d.policy = c
+	d.mu.Unlock()
+	return d.route(c)
+}
+
+// route installs the request handler enforcing policy.
+func (d *PlaywrightDriver) route(policy compiledPolicy) error {
+	d.mu.Lock()
+	bctx := d.context
+	d.mu.Unlock()
+	if err := bctx.UnrouteAll(); err != nil {
+		return err
+	}
+	if policy.empty() {
+		return nil
+	}
+	return bctx.Route("**/*", func(route playwright.Route) {
+		req := route.Request()
+		page, _ := d.current()
+		topLevel := req.IsNavigationRequest() && req.Frame() == page.MainFrame()
+		err := policy.check(req.URL(), topLevel)
+		if err == nil {
+			_ = route.Continue()
+			return
+		}
+		if topLevel {
+			d.mu.Lock()
+			d.blocked = append(d.blocked, BlockedNavigation{URL: req.URL(), Reason: err.Error()})
+			d.mu.Unlock()
+			// An aborted navigation leaves the current page in place.
+			_ = route.Abort("aborted")
+			return
+		}
+		_ = route.Abort("blockedbyclient")
+	})
+}
+
+// BlockedNavigations returns the page navigations blocked by the URL
+// policy since the last call.
+func (d *PlaywrightDriver) BlockedNavigations() []BlockedNavigation {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	blocked := d.blocked
+	d.blocked = nil
+	return blocked
+}
+
+// ExportStorageState saves the cookies and localStorage of the browser
+// context to path. Playwright does not export sessionStorage.
+func (d *PlaywrightDriver) ExportStorageState(ctx context.Context, path string) error {
+	d.mu.Lock()
+	bctx := d.context
+	d.mu.Unlock()
+	raw, err := bctx.StorageState()
+	if err != nil {
+		return err
+	}
+	var state StorageState
+	if err := convertJSON(raw, &state); err != nil {
+		return err
+	}
+	return state.Save(path)
+}
+
+// ImportStorageState loads a file written by ExportStorageState. It
+// replaces the browser context, so call it before the first Navigate.
+func (d *PlaywrightDriver) ImportStorageState(ctx context.Context, path string) error {
+	state, err := LoadStorageState(path)
+	if err != nil {
+		return err
+	}
+	return d.newContext(state)
+}
+
+// convertJSON copies v into out through its JSON encoding.
+func convertJSON(v, out any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, out)
+}
+
+// snapshotScript walks the DOM of the page, argument the snapshot number,
+// and returns its nodes in document order. Roles and names follow the
+// accessibility tree of Chrome closely enough for the model.
+const snapshotScript = `(gen) => {
+	const interactive = new Set(["button", "link", "checkbox", "radio", "searchbox", "textbox", "combobox", "menuitem", "slider", "switch"]);
+	const skipped = new Set(["SCRIPT", "STYLE", "NOSCRIPT", "TEMPLATE", "HEAD"]);
+
+	const roleOf = (el) => {
+		const explicit = (el.getAttribute("role") || "").trim().split(/\s+/)[0].toLowerCase();
+		if (explicit) return explicit;
+		const tag = el.tagName.toLowerCase();
+		switch (tag) {
+		case "a": return el.hasAttribute("href") ? "link" : "";
+		case "button": case "summary": return "button";
+		case "select": return "combobox";
+		case "textarea": return "textbox";
+		case "img": return el.alt ? "image" : "";
+		case "h1": case "h2": case "h3": case "h4": case "h5": case "h6": return "heading";
+		case "input":
+			switch ((el.type || "text").toLowerCase()) {
+			case "hidden": return "";
+			case "button": case "submit": case "reset": case "image": return "button";
+			case "checkbox": return "checkbox";
+			case "radio": return "radio";
+			case "range": return "slider";
+			case "search": return "searchbox";
+			default: return "textbox";
+			}
+		}
+		if (el.isContentEditable && !(el.parentElement && el.parentElement.isContentEditable)) return "textbox";
+		return "";
+	};
+
+	const textOf = (el) => (el.innerText || el.textContent || "").replace(/\s+/g, " ").trim();
+
+	const nameOf = (el) => {
+		const by = el.getAttribute("aria-labelledby");
+		if (by) {
+			const text = by.split(/\s+/).map((id) => document.getElementById(id)).filter(Boolean).map(textOf).join(" ");
+			if (text) return text;
+		}
+		const label = el.getAttribute("aria-label");
+		if (label) return label;
+		const tag = el.tagName.toLowerCase();
+		if (el.labels && el.labels.length) return Array.from(el.labels).map(textOf).join(" ");
+		if (tag === "input" && ["button", "submit", "reset"].includes(el.type)) return el.value;
+		if (tag === "img" || (tag === "input" && el.type === "image")) return el.alt || "";
+		if (!["input", "select", "textarea"].includes(tag)) {
+			const text = textOf(el);
+			if (text) return text;
+		}
+		return el.getAttribute("placeholder") || el.getAttribute("title") || "";
+	};
+
+	const valueOf = (el) => {
+		const tag = el.tagName.toLowerCase();
+		if (tag === "input" && el.type === "password") return "•".repeat(el.value.length);
+		if (tag === "select") return el.selectedOptions.length ? textOf(el.selectedOptions[0]) : "";
+		if (tag === "textarea" || (tag === "input" && !["checkbox", "radio", "button", "submit", "reset", "image"].includes(el.type))) return el.value || "";
+		if (el.isContentEditable) return textOf(el);
+		return "";
+	};
+
+	document.querySelectorAll("[data-agent-id]").forEach((el) => el.removeAttribute("data-agent-id"));
+
+	const out = [];
+	let id = 0;
+	const walk = (el, inside) => {
+		if (skipped.has(el.tagName) || el.getAttribute("aria-hidden") === "true") return;
+		const style = getComputedStyle(el);
+		if (style.display === "none") return;
+		const shown = style.visibility !== "hidden";
+		const role = roleOf(el);
+		if (shown && interactive.has(role) && !el.disabled) {
+			id++;
+			el.setAttribute("data-agent-id", gen + "-" + id);
+			out.push({ id, role, name: nameOf(el), value: valueOf(el) });
+			inside = true;
+		} else if (shown && (role === "heading" || role === "image")) {
+			out.push({ role, name: nameOf(el) });
+			inside = true;
+		}
+		for (const child of el.childNodes) {
+			if (child.nodeType === Node.ELEMENT_NODE) {
+				walk(child, inside);
+			} else if (child.nodeType === Node.TEXT_NODE && shown && !inside) {
+				const text = child.textContent.replace(/\s+/g, " ").trim();
+				if (text) out.push({ role: "StaticText", name: text });
+			}
+		}
+		if (el.shadowRoot) {
+			for (const child of el.shadowRoot.children) walk(child, inside);
+		}
+	};
+	if (document.body) walk(document.body, false);
+	return out;
+}`
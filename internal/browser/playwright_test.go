@@ -0,0 +1,108 @@
+package browser_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/browser"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/fixture"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/llm/llmtest"
+)
+
+func TestOpenInvalidOptions(t *testing.T) {
+	for name, opts := range map[string]browser.Options{
+		"unknown backend":       {Backend: "selenium"},
+		"firefox on chromedp":   {Engine: "firefox"},
+		"unknown engine":        {Backend: browser.BackendPlaywright, Engine: "opera"},
+		"remote":                {Backend: browser.BackendPlaywright, RemoteURL: "http://127.0.0.1:9222"},
+		"profile":               {Backend: browser.BackendPlaywright, Profile: "work"},
+		"blocking":              {Backend: browser.BackendPlaywright, Block: browser.BlockOptions{Trackers: true}},
+		"credentials wildcard":  {Backend: browser.BackendPlaywright, Credentials: []browser.SiteCredentials{{Site: "*.example.com"}}},
+		"credentials no scheme": {Backend: browser.BackendPlaywright, Credentials: []browser.SiteCredentials{{Site: "staging.example.com"}}},
+		"two credentials": {Backend: browser.BackendPlaywright, Credentials: []browser.SiteCredentials{
+			{Site: "https://a.example.com"}, {Site: "https://b.example.com"},
+		}},
+		"unknown device": {Backend: browser.BackendPlaywright, Emulation: browser.Emulation{Device: "Nokia 3310"}},
+		"bad policy":     {Backend: browser.BackendPlaywright, Policy: browser.URLPolicy{Allow: []string{"*"}}},
+	} {
+		b, err := browser.Open(opts)
+		if err == nil {
+			b.Close()
+			t.Errorf("%s: Open succeeded", name)
+		} else if errors.Is(err, browser.ErrNoPlaywright) {
+			t.Errorf("%s: options were not checked before starting Playwright: %v", name, err)
+		}
+	}
+}
+
+func TestPlaywrightDriver(t *testing.T) {
+	site := fixture.NewSite()
+	defer site.Close()
+	d := fixture.NewPlaywright(t)
+	ctx := context.Background()
+
+	if err := d.Navigate(ctx, site.URL("/login")); err != nil {
+		t.Fatal(err)
+	}
+	snap, err := d.Snapshot(ctx, 1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snap.Title != "Sign in" || snap.ScreenshotBase64 == "" || !strings.Contains(snap.Tree, `- [heading] "Sign in"`) {
+		t.Fatalf("snapshot = %q, screenshot %d bytes:\n%s", snap.Title, len(snap.ScreenshotBase64), snap.Tree)
+	}
+
+	for field, text := range map[string]string{"Username": fixture.Username, "Password": fixture.Password} {
+		el, ok := llmtest.FindElement(snap.Tree, field)
+		if !ok || el.Role != "textbox" {
+			t.Fatalf("%s input missing from tree:\n%s", field, snap.Tree)
+		}
+		h, err := d.Resolve(ctx, snap, el.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := d.Type(ctx, h, text, field == "Password"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitUntil(t, "logged in", func() bool {
+		url, _ := d.Location(ctx)
+		return strings.HasSuffix(url, "/account")
+	})
+
+	// Elements of an older snapshot are not resolved on the new page.
+	if _, err := d.Resolve(ctx, snap, 1); err == nil {
+		t.Error("Resolve found an element of a previous page")
+	}
+
+	var title string
+	if err := d.Evaluate(ctx, `document.title`, &title); err != nil || title == "" {
+		t.Errorf("Evaluate = %q, %v", title, err)
+	}
+}
+
+func TestPlaywrightURLPolicy(t *testing.T) {
+	site := fixture.NewSite()
+	defer site.Close()
+	d := fixture.NewPlaywright(t)
+	ctx := context.Background()
+
+	if err := d.SetURLPolicy(ctx, browser.URLPolicy{Allow: []string{"127.0.0.1/shop"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Navigate(ctx, site.URL("/help")); !errors.Is(err, browser.ErrNavigationBlocked) {
+		t.Fatalf("Navigate to a page outside the allowlist: %v", err)
+	}
+	if err := d.Navigate(ctx, site.URL("/shop")); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.Evaluate(ctx, `location.href = "/help"`, nil); err != nil {
+		t.Fatal(err)
+	}
+	waitUntil(t, "blocked navigation reported", func() bool { return len(d.BlockedNavigations()) > 0 })
+	if url, _ := d.Location(ctx); url != site.URL("/shop") {
+		t.Errorf("Location = %q after a blocked navigation", url)
+	}
+}
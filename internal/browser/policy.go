@@ -0,0 +1,216 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// ErrNavigationBlocked is returned by Navigate for a URL the URLPolicy does
+// not allow.
+var ErrNavigationBlocked = errors.New("navigation blocked by URL policy")
+
+// URLPolicy limits where the tab may go. A rule is a host, optionally with
+// a scheme, a port and a path prefix: "example.com" matches the host and
+// its subdomains, "*.example.com" only the subdomains, and
+// "shop.example.com/cart" only paths under /cart.
+//
+// Deny blocks every request of the tab, including scripts, frames and
+// XHR. Allow, when not empty, is the list of sites the tab may navigate to;
+// it only applies to page navigations, so CDNs and embedded frames keep
+// working. Deny wins over Allow. URLs other than http and https are not
+// checked.
+type URLPolicy struct {
+	Allow []string
+	Deny  []string
+}
+
+// BlockedNavigation is a page navigation stopped by the URLPolicy.
+type BlockedNavigation struct {
+	URL    string
+	Reason string
+}
+
+type urlRule struct {
+	raw       string
+	scheme    string
+	host      string
+	port      string
+	subdomain bool // only subdomains of host
+	path      string
+}
+
+func parseURLRule(s string) (urlRule, error) {
+	r := urlRule{raw: s}
+	rest := strings.TrimSpace(s)
+	if scheme, after, ok := strings.Cut(rest, "://"); ok {
+		r.scheme, rest = strings.ToLower(scheme), after
+	}
+	hostport, path, _ := strings.Cut(rest, "/")
+	if path != "" {
+		r.path = "/" + strings.TrimSuffix(path, "/")
+	}
+	if h, p, err := net.SplitHostPort(hostport); err == nil {
+		hostport, r.port = h, p
+	}
+	if after, ok := strings.CutPrefix(hostport, "*."); ok {
+		hostport, r.subdomain = after, true
+	}
+	r.host = strings.ToLower(strings.Trim(strings.TrimSuffix(hostport, "."), "[]"))
+	if r.host == "" || strings.ContainsAny(r.host, "*?") {
+		return urlRule{}, fmt.Errorf("invalid URL rule %q", s)
+	}
+	return r, nil
+}
+
+func (r urlRule) matches(u *url.URL) bool {
+	if r.scheme != "" && r.scheme != u.Scheme {
+		return false
+	}
+	if r.port != "" && r.port != u.Port() {
+		return false
+	}
+	host := strings.ToLower(strings.TrimSuffix(u.Hostname(), "."))
+	sub := strings.HasSuffix(host, "."+r.host)
+	if !sub && (r.subdomain || host != r.host) {
+		return false
+	}
+	if r.path == "" {
+		return true
+	}
+	path := u.EscapedPath()
+	return path == r.path || strings.HasPrefix(path, r.path+"/")
+}
+
+// compiledPolicy is a URLPolicy with parsed rules.
+type compiledPolicy struct {
+	allow, deny []urlRule
+}
+
+func (p URLPolicy) compile() (compiledPolicy, error) {
+	var c compiledPolicy
+	for _, list := range []struct {
+		raw   []string
+		rules *[]urlRule
+	}{{p.Allow, &c.allow}, {p.Deny, &c.deny}} {
+		for _, s := range list.raw {
+			r, err := parseURLRule(s)
+			if err != nil {
+				return compiledPolicy{}, err
+			}
+			*list.rules = append(*list.rules, r)
+		}
+	}
+	return c, nil
+}
+
+// Validate reports a malformed rule.
+func (p URLPolicy) Validate() error {
+	_, err := p.compile()
+	return err
+}
+
+// Check returns an error wrapping ErrNavigationBlocked when the tab may not
+// navigate to rawURL.
+func (p URLPolicy) Check(rawURL string) error {
+	c, err := p.compile()
+	if err != nil {
+		return err
+	}
+	return c.check(rawURL, true)
+}
+
+// check applies the policy to a request; navigation tells whether it loads
+// a page, which the allowlist applies to.
+func (c compiledPolicy) check(rawURL string, navigation bool) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return nil
+	}
+	for _, r := range c.deny {
+		if r.matches(u) {
+			return fmt.Errorf("%w: %s matches denied %q", ErrNavigationBlocked, u.Host, r.raw)
+		}
+	}
+	if !navigation || len(c.allow) == 0 {
+		return nil
+	}
+	for _, r := range c.allow {
+		if r.matches(u) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s is not in the allowlist", ErrNavigationBlocked, u.Host+u.EscapedPath())
+}
+
+func (c compiledPolicy) empty() bool {
+	return len(c.allow) == 0 && len(c.deny) == 0
+}
+
+// patterns returns the requests the policy has to see: page loads for the
+// allowlist, and requests whose URL mentions a denied host.
+func (c compiledPolicy) patterns() []*fetch.RequestPattern {
+	var patterns []*fetch.RequestPattern
+	if len(c.allow) > 0 {
+		patterns = append(patterns, &fetch.RequestPattern{URLPattern: "*", ResourceType: network.ResourceTypeDocument})
+	}
+	for _, r := range c.deny {
+		patterns = append(patterns, &fetch.RequestPattern{URLPattern: "*" + r.host + "*"})
+	}
+	return patterns
+}
+
+// SetURLPolicy replaces the URL policy of the tab; an empty policy lifts
+// all restrictions.
+func (m *Manager) SetURLPolicy(ctx context.Context, p URLPolicy) error {
+	c, err := p.compile()
+	if err != nil {
+		return err
+	}
+	m.filterMu.Lock()
+	m.policy = c
+	m.filterMu.Unlock()
+	if c.empty() {
+		return m.removeRequestFilter(ctx, "url-policy")
+	}
+
+	return m.setRequestFilter(ctx, requestFilter{
+		name:     "url-policy",
+		patterns: c.patterns(),
+		handle: func(ev *fetch.EventRequestPaused) chromedp.Action {
+			// The main frame is looked up per request: a relaunched browser
+			// has a new one.
+			topLevel := ev.ResourceType == network.ResourceTypeDocument && ev.FrameID == m.mainFrame()
+			err := c.check(ev.Request.URL, topLevel)
+			if err == nil {
+				return nil
+			}
+			if !topLevel {
+				return fetch.FailRequest(ev.RequestID, network.ErrorReasonBlockedByClient)
+			}
+			m.filterMu.Lock()
+			m.blocked = append(m.blocked, BlockedNavigation{URL: ev.Request.URL, Reason: err.Error()})
+			m.filterMu.Unlock()
+			// A 204 answer cancels the navigation and leaves the current
+			// page in place, instead of showing an error page.
+			return fetch.FulfillRequest(ev.RequestID, 204)
+		},
+	}, false)
+}
+
+// BlockedNavigations returns the page navigations blocked by the URL
+// policy since the last call.
+func (m *Manager) BlockedNavigations() []BlockedNavigation {
+	m.filterMu.Lock()
+	defer m.filterMu.Unlock()
+	blocked := m.blocked
+	m.blocked = nil
+	return blocked
+}
@@ -0,0 +1,182 @@
+package browser_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chromedp/cdproto/runtime"
+	"github.com/chromedp/chromedp"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/browser"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/fixture"
+)
+
+func TestURLPolicyCheck(t *testing.T) {
+	p := browser.URLPolicy{
+		Allow: []string{"shop.example.com/catalog", "*.cdn.example", "https://secure.example:8443"},
+		Deny:  []string{"pay.shop.example.com"},
+	}
+	for url, allowed := range map[string]bool{
+		"https://shop.example.com/catalog":                    true,
+		"https://shop.example.com/catalog/pizza?x=":           true,
+		"https://m.shop.example.com/catalog/":                 true,
+		"https://shop.example.com/catalogue":                  false,
+		"https://shop.example.com/":                           false,
+		"https://pay.shop.example.com/catalog":                false,
+		"https://img.cdn.example/a.png":                       true,
+		"https://cdn.example/a.png":                           false,
+		"https://secure.example:8443/login":                   true,
+		"http://secure.example:8443/login":                    false,
+		"https://secure.example/login":                        false,
+		"https://evil.example/?next=shop.example.com/catalog": false,
+		"about:blank":              true,
+		"data:text/html,<p>hi</p>": true,
+	} {
+		err := p.Check(url)
+		if got := err == nil; got != allowed {
+			t.Errorf("Check(%q) = %v, want allowed=%v", url, err, allowed)
+		}
+		if err != nil && !errors.Is(err, browser.ErrNavigationBlocked) {
+			t.Errorf("Check(%q) = %v, want ErrNavigationBlocked", url, err)
+		}
+	}
+
+	if err := (browser.URLPolicy{Deny: []string{"ads.example"}}).Check("https://news.example/"); err != nil {
+		t.Errorf("deny-only policy blocked an unrelated site: %v", err)
+	}
+	if err := (browser.URLPolicy{Allow: []string{"*"}}).Check("https://example.com/"); err == nil {
+		t.Error("invalid rule accepted")
+	}
+}
+
+func TestURLPolicyEnforced(t *testing.T) {
+	site := fixture.NewSite()
+	defer site.Close()
+	// The fixture listens on 127.0.0.1; localhost is another site to Chrome.
+	foreign := strings.Replace(site.URL("/help"), "127.0.0.1", "localhost", 1)
+
+	b := fixture.NewBrowser(t)
+	if err := b.SetURLPolicy(context.Background(), browser.URLPolicy{Allow: []string{"127.0.0.1"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Navigate(context.Background(), site.URL("/shop")); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Navigate(context.Background(), foreign); !errors.Is(err, browser.ErrNavigationBlocked) {
+		t.Fatalf("Navigate(%s) = %v, want ErrNavigationBlocked", foreign, err)
+	}
+
+	// A navigation started by the page is cancelled and reported.
+	ctx, cancel := b.Bind(context.Background())
+	defer cancel()
+	if err := chromedp.Run(ctx, chromedp.Evaluate(`location.href = `+jsQuote(foreign), nil)); err != nil {
+		t.Fatal(err)
+	}
+	var blocked []browser.BlockedNavigation
+	waitUntil(t, "blocked navigation reported", func() bool {
+		blocked = append(blocked, b.BlockedNavigations()...)
+		return len(blocked) > 0
+	})
+	if blocked[0].URL != foreign || !strings.Contains(blocked[0].Reason, "allowlist") {
+		t.Errorf("blocked = %+v", blocked)
+	}
+	time.Sleep(200 * time.Millisecond)
+	if url, err := b.Location(context.Background()); err != nil || url != site.URL("/shop") {
+		t.Errorf("location = %q, %v; want to stay on the shop", url, err)
+	}
+
+	// Deny also stops subresources and XHR.
+	if err := b.SetURLPolicy(context.Background(), browser.URLPolicy{Deny: []string{"localhost"}}); err != nil {
+		t.Fatal(err)
+	}
+	var result string
+	err := chromedp.Run(ctx, chromedp.Evaluate(
+		`fetch(`+jsQuote(foreign)+`, {mode: "no-cors"}).then(() => "loaded", () => "failed")`,
+		&result,
+		func(p *runtime.EvaluateParams) *runtime.EvaluateParams { return p.WithAwaitPromise(true) },
+	))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result != "failed" {
+		t.Errorf("denied fetch %s", result)
+	}
+
+	// Lifting the policy lets the page through again.
+	if err := b.SetURLPolicy(context.Background(), browser.URLPolicy{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Navigate(context.Background(), foreign); err != nil {
+		t.Errorf("Navigate after lifting the policy: %v", err)
+	}
+}
+
+func jsQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `\"`) + `"`
+}
+
+func TestBlocking(t *testing.T) {
+	site := fixture.NewSite()
+	defer site.Close()
+	b := fixture.NewBrowser(t)
+	err := b.SetBlocking(context.Background(), browser.BlockOptions{
+		ResourceTypes: []string{"image"},
+		Patterns:      []string{"*ad=1*"},
+		Trackers:      true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Navigate(context.Background(), site.URL("/shop")); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := b.Bind(context.Background())
+	defer cancel()
+	load := func(expr string) string {
+		t.Helper()
+		var result string
+		err := chromedp.Run(ctx, chromedp.Evaluate(expr, &result,
+			func(p *runtime.EvaluateParams) *runtime.EvaluateParams { return p.WithAwaitPromise(true) }))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+	img := `new Promise(ok => { const i = new Image(); i.onload = () => ok("loaded"); i.onerror = () => ok("failed"); i.src = ` + jsQuote(site.URL("/help?img")) + ` })`
+	get := func(url string) string {
+		return `fetch(` + jsQuote(url) + `).then(() => "loaded", () => "failed")`
+	}
+
+	if got := load(img); got != "failed" {
+		t.Errorf("image %s", got)
+	}
+	if got := load(get(site.URL("/help?ad=1"))); got != "failed" {
+		t.Errorf("pattern fetch %s", got)
+	}
+	if got := load(get(site.URL("/help"))); got != "loaded" {
+		t.Errorf("plain fetch %s", got)
+	}
+	load(get("https://www.google-analytics.com/g/collect"))
+
+	stats := b.BlockStats()
+	if stats.Requests != 3 || stats.ByType["image"] != 1 || stats.ByType["xhr"] != 2 {
+		t.Errorf("stats = %+v", stats)
+	}
+
+	if err := b.SetBlocking(context.Background(), browser.BlockOptions{ResourceTypes: []string{"document"}}); err == nil {
+		t.Error("blocking documents accepted")
+	}
+	if err := b.SetBlocking(context.Background(), browser.BlockOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := load(get(site.URL("/help?ad=1"))); got != "loaded" {
+		t.Errorf("fetch after lifting %s", got)
+	}
+	if b.BlockStats().Requests != 3 {
+		t.Errorf("requests counted after blocking stopped: %+v", b.BlockStats())
+	}
+}
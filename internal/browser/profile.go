@@ -0,0 +1,208 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// ErrProfileInUse is returned by NewManager when another agent or a Chrome
+// instance already uses the profile directory.
+var ErrProfileInUse = errors.New("profile is already in use")
+
+const (
+	tempProfilePattern = "go-browser-ai-agent-profile-*"
+	lockFileName       = ".go-browser-ai-agent.lock"
+)
+
+// DefaultProfileRoot is the directory holding named profiles.
+func DefaultProfileRoot() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, "go-browser-ai-agent", "profiles")
+}
+
+// ProfilePath returns the directory of the named profile under root; an
+// empty root means DefaultProfileRoot.
+func ProfilePath(root, name string) (string, error) {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return "", fmt.Errorf("invalid profile name %q", name)
+	}
+	if root == "" {
+		root = DefaultProfileRoot()
+	}
+	return filepath.Join(root, name), nil
+}
+
+// resolveProfile returns the profile directory for opts, creating a
+// temporary one for ephemeral profiles; temp reports whether the
+// directory must be removed on Close.
+func resolveProfile(opts Options) (dir string, temp bool, err error) {
+	switch {
+	case opts.Ephemeral && opts.Profile != "":
+		return "", false, errors.New("a named profile cannot be ephemeral; use ProfileTemplate to start from it")
+	case opts.ProfileTemplate != "" && !opts.Ephemeral:
+		return "", false, errors.New("ProfileTemplate requires an ephemeral profile")
+	case opts.Ephemeral:
+		dir, err := os.MkdirTemp("", tempProfilePattern)
+		if err != nil {
+			return "", false, fmt.Errorf("create temporary profile: %w", err)
+		}
+		if opts.ProfileTemplate != "" {
+			if err := copyProfile(opts.ProfileTemplate, dir); err != nil {
+				_ = os.RemoveAll(dir)
+				return "", false, fmt.Errorf("copy profile template %s: %w", opts.ProfileTemplate, err)
+			}
+		}
+		return dir, true, nil
+	case opts.Profile != "":
+		dir, err := ProfilePath(opts.ProfileRoot, opts.Profile)
+		return dir, false, err
+	default:
+		return opts.UserDataDir, false, nil
+	}
+}
+
+// skipInClone lists profile entries that are not copied from a template:
+// locks of the running browser and caches.
+var skipInClone = map[string]bool{
+	"SingletonLock":   true,
+	"SingletonSocket": true,
+	"SingletonCookie": true,
+	lockFileName:      true,
+	"Cache":           true,
+	"Code Cache":      true,
+	"GPUCache":        true,
+	"ShaderCache":     true,
+	"GrShaderCache":   true,
+	"Crashpad":        true,
+}
+
+func copyProfile(src, dst string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", src)
+	}
+
+	return filepath.WalkDir(src, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		if skipInClone[d.Name()] {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		target := filepath.Join(dst, rel)
+		switch {
+		case d.IsDir():
+			return os.MkdirAll(target, 0o700)
+		case d.Type().IsRegular():
+			return copyFile(path, target)
+		default:
+			// Symlinks and sockets belong to a running browser.
+			return nil
+		}
+	})
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// lockProfile takes the agent lock of dir, creating the directory, and
+// fails with ErrProfileInUse when another agent holds it or a Chrome
+// instance runs on it.
+func lockProfile(dir string) (*profileLock, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create profile %s: %w", dir, err)
+	}
+
+	lock, err := acquireLock(filepath.Join(dir, lockFileName))
+	if err != nil {
+		return nil, fmt.Errorf("profile %s: %w", dir, err)
+	}
+
+	// Chrome started by hand on the profile does not know our lock, but
+	// leaves a SingletonLock link to "<host>-<pid>".
+	if owner, err := os.Readlink(filepath.Join(dir, "SingletonLock")); err == nil {
+		if pid, ok := singletonPID(owner); ok && processAlive(pid) {
+			lock.release()
+			return nil, fmt.Errorf("profile %s: %w by Chrome (pid %d)", dir, ErrProfileInUse, pid)
+		}
+	}
+	return lock, nil
+}
+
+func singletonPID(owner string) (int, bool) {
+	i := strings.LastIndex(owner, "-")
+	if i < 0 {
+		return 0, false
+	}
+	var pid int
+	if _, err := fmt.Sscanf(owner[i+1:], "%d", &pid); err != nil || pid <= 0 {
+		return 0, false
+	}
+	return pid, true
+}
+
+// CleanupTempProfiles removes temporary profiles left behind by agents that
+// did not exit cleanly. Profiles in use and profiles modified within
+// minAge are kept.
+func CleanupTempProfiles(minAge time.Duration) (removed []string, err error) {
+	dirs, err := filepath.Glob(filepath.Join(os.TempDir(), tempProfilePattern))
+	if err != nil {
+		return nil, err
+	}
+
+	var errs []error
+	for _, dir := range dirs {
+		info, err := os.Stat(dir)
+		if err != nil || !info.IsDir() || time.Since(info.ModTime()) < minAge {
+			continue
+		}
+
+		lock, err := acquireLock(filepath.Join(dir, lockFileName))
+		if err != nil {
+			continue
+		}
+		err = os.RemoveAll(dir)
+		lock.release()
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		removed = append(removed, dir)
+	}
+	return removed, errors.Join(errs...)
+}
@@ -0,0 +1,191 @@
+package browser
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestProfilePath(t *testing.T) {
+	if dir, err := ProfilePath("/profiles", "work"); err != nil || dir != filepath.Join("/profiles", "work") {
+		t.Errorf("ProfilePath = %q, %v", dir, err)
+	}
+	for _, name := range []string{"", ".", "..", "a/b", `a\b`} {
+		if _, err := ProfilePath("/profiles", name); err == nil {
+			t.Errorf("ProfilePath accepted %q", name)
+		}
+	}
+}
+
+func TestLockProfile(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "profile")
+
+	first, err := lockProfile(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lockProfile(dir); !errors.Is(err, ErrProfileInUse) {
+		t.Fatalf("second lock: err = %v, want ErrProfileInUse", err)
+	}
+
+	first.release()
+	second, err := lockProfile(dir)
+	if err != nil {
+		t.Fatalf("lock after release: %v", err)
+	}
+	second.release()
+}
+
+func TestLockProfileRunningChrome(t *testing.T) {
+	dir := t.TempDir()
+
+	// A dead owner is a stale lock left by a crash.
+	if err := os.Symlink("host-999999999", filepath.Join(dir, "SingletonLock")); err != nil {
+		t.Skip("symlinks not supported:", err)
+	}
+	lock, err := lockProfile(dir)
+	if err != nil {
+		t.Fatalf("stale SingletonLock: %v", err)
+	}
+	lock.release()
+
+	_ = os.Remove(filepath.Join(dir, "SingletonLock"))
+	if err := os.Symlink(fmt.Sprintf("host-%d", os.Getpid()), filepath.Join(dir, "SingletonLock")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lockProfile(dir); !errors.Is(err, ErrProfileInUse) {
+		t.Errorf("err = %v, want ErrProfileInUse", err)
+	}
+}
+
+func TestWaitProfileFree(t *testing.T) {
+	dir := t.TempDir()
+	lockLink := filepath.Join(dir, "SingletonLock")
+	if err := waitProfileFree(dir, time.Now()); err != nil {
+		t.Fatalf("profile without a lock: %v", err)
+	}
+
+	if err := os.Symlink(fmt.Sprintf("host-%d", os.Getpid()), lockLink); err != nil {
+		t.Skip("symlinks not supported:", err)
+	}
+	if err := waitProfileFree(dir, time.Now().Add(100*time.Millisecond)); !errors.Is(err, ErrProfileInUse) {
+		t.Errorf("err = %v, want ErrProfileInUse while the owner runs", err)
+	}
+
+	// The owner exits while relaunch waits.
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		_ = os.Remove(lockLink)
+		_ = os.Symlink("host-999999999", lockLink)
+	}()
+	if err := waitProfileFree(dir, time.Now().Add(5*time.Second)); err != nil {
+		t.Errorf("err = %v after the owner exited", err)
+	}
+}
+
+func TestResolveProfileTemplate(t *testing.T) {
+	tmpl := t.TempDir()
+	for name, content := range map[string]string{
+		"Local State":         "{}",
+		"Default/Cookies":     "cookies",
+		"Default/Cache/data":  "cached",
+		"Default/Preferences": "{}",
+	} {
+		path := filepath.Join(tmpl, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	_ = os.Symlink("host-1", filepath.Join(tmpl, "SingletonLock"))
+
+	dir, temp, err := resolveProfile(Options{Ephemeral: true, ProfileTemplate: tmpl})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if !temp {
+		t.Error("template clone is not temporary")
+	}
+
+	if raw, err := os.ReadFile(filepath.Join(dir, "Default", "Cookies")); err != nil || string(raw) != "cookies" {
+		t.Errorf("cookies not copied: %q, %v", raw, err)
+	}
+	for _, skipped := range []string{"Default/Cache", "SingletonLock"} {
+		if _, err := os.Lstat(filepath.Join(dir, skipped)); !os.IsNotExist(err) {
+			t.Errorf("%s copied from the template", skipped)
+		}
+	}
+}
+
+func TestResolveProfileConflicts(t *testing.T) {
+	for _, opts := range []Options{
+		{Ephemeral: true, Profile: "work"},
+		{ProfileTemplate: "/tmp/template"},
+	} {
+		if _, _, err := resolveProfile(opts); err == nil {
+			t.Errorf("resolveProfile(%+v) succeeded", opts)
+		}
+	}
+}
+
+func TestCleanupTempProfiles(t *testing.T) {
+	old := func() string {
+		dir, err := os.MkdirTemp("", tempProfilePattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { os.RemoveAll(dir) })
+		past := time.Now().Add(-2 * time.Hour)
+		if err := os.Chtimes(dir, past, past); err != nil {
+			t.Fatal(err)
+		}
+		return dir
+	}
+
+	stale, inUse := old(), old()
+	fresh, err := os.MkdirTemp("", tempProfilePattern)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(fresh)
+
+	lock, err := acquireLock(filepath.Join(inUse, lockFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer lock.release()
+	past := time.Now().Add(-2 * time.Hour)
+	_ = os.Chtimes(inUse, past, past)
+
+	removed, err := CleanupTempProfiles(time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !contains(removed, stale) {
+		t.Errorf("stale profile not removed: %v", removed)
+	}
+	for _, kept := range []string{inUse, fresh} {
+		if contains(removed, kept) {
+			t.Errorf("%s removed", kept)
+		}
+		if _, err := os.Stat(kept); err != nil {
+			t.Errorf("%s: %v", kept, err)
+		}
+	}
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,170 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+	"sync"
+
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/chromedp"
+)
+
+// Proxy routes the browser's traffic through a proxy server.
+type Proxy struct {
+	// Server is the proxy URL: http://host:port, https://host:port or
+	// socks5://host:port. Credentials may be given as user:password@ in an
+	// http(s) URL instead of Username and Password.
+	Server string
+	// Bypass lists hosts reached directly, in Chrome's --proxy-bypass-list
+	// syntax: "*.corp.local", "10.0.0.0/8", "<local>". Loopback addresses
+	// are always bypassed unless "<-loopback>" is listed.
+	Bypass []string
+	// Username and Password answer the proxy's authentication challenge.
+	// Chrome does not support authenticated SOCKS proxies. Without Server
+	// they answer the proxy a remote browser was started with.
+	Username string
+	Password string
+}
+
+// parse splits the credentials off the server URL and checks the scheme.
+func (p Proxy) parse() (server string, user, password string, err error) {
+	raw := strings.TrimSpace(p.Server)
+	if raw == "" {
+		return "", p.Username, p.Password, nil
+	}
+	if !strings.Contains(raw, "://") {
+		raw = "http://" + raw
+	}
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return "", "", "", fmt.Errorf("invalid proxy %q", p.Server)
+	}
+	user, password = p.Username, p.Password
+	if u.User != nil && user == "" {
+		user = u.User.Username()
+		password, _ = u.User.Password()
+	}
+	switch u.Scheme {
+	case "http", "https":
+	case "socks4", "socks5":
+		if user != "" {
+			return "", "", "", fmt.Errorf("proxy %s: Chrome does not support SOCKS authentication", u.Host)
+		}
+	default:
+		return "", "", "", fmt.Errorf("proxy %q: unsupported scheme %q", p.Server, u.Scheme)
+	}
+	return u.Scheme + "://" + u.Host, user, password, nil
+}
+
+// SiteCredentials answer the HTTP authentication (basic or digest) of
+// sites matching Site, a rule in the URLPolicy syntax such as
+// "staging.example.com" or "https://*.internal.example.com:8443".
+type SiteCredentials struct {
+	Site     string
+	Username string
+	Password string
+}
+
+// setAuth installs the filter answering authentication challenges of the
+// proxy and of the sites in creds.
+func (m *Manager) setAuth(ctx context.Context, proxyUser, proxyPassword string, creds []SiteCredentials) error {
+	rules := make([]urlRule, len(creds))
+	for i, c := range creds {
+		r, err := parseURLRule(c.Site)
+		if err != nil {
+			return fmt.Errorf("credentials: %w", err)
+		}
+		rules[i] = r
+	}
+
+	// Chrome asks again when the credentials are rejected; the second
+	// challenge of a request is cancelled so the page shows the error
+	// instead of looping.
+	var mu sync.Mutex
+	type challenge struct {
+		request fetch.RequestID
+		source  fetch.AuthChallengeSource
+	}
+	answered := make(map[challenge]bool)
+	// inflight maps the requests passing the filter to their Fetch IDs, so
+	// that their challenges are forgotten once they finish.
+	inflight := make(map[network.RequestID][]fetch.RequestID)
+	cancel := &fetch.AuthChallengeResponse{Response: fetch.AuthChallengeResponseResponseCancelAuth}
+
+	return m.setRequestFilter(ctx, requestFilter{
+		name:     "auth",
+		patterns: authPatterns(proxyUser != "", rules),
+		handle: func(ev *fetch.EventRequestPaused) chromedp.Action {
+			if ev.NetworkID != "" {
+				mu.Lock()
+				inflight[ev.NetworkID] = append(inflight[ev.NetworkID], ev.RequestID)
+				mu.Unlock()
+			}
+			return nil
+		},
+		finished: func(id network.RequestID) {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, r := range inflight[id] {
+				delete(answered, challenge{r, fetch.AuthChallengeSourceProxy})
+				delete(answered, challenge{r, fetch.AuthChallengeSourceServer})
+			}
+			delete(inflight, id)
+		},
+		auth: func(ev *fetch.EventAuthRequired) *fetch.AuthChallengeResponse {
+			// A request may be challenged by the proxy and then by the
+			// site; only a second challenge of the same source is a retry.
+			c := challenge{ev.RequestID, ev.AuthChallenge.Source}
+			mu.Lock()
+			retry := answered[c]
+			answered[c] = true
+			mu.Unlock()
+			if retry {
+				return cancel
+			}
+
+			if ev.AuthChallenge.Source == fetch.AuthChallengeSourceProxy {
+				if proxyUser == "" {
+					return cancel
+				}
+				return provide(proxyUser, proxyPassword)
+			}
+			u, err := url.Parse(ev.AuthChallenge.Origin)
+			if err != nil {
+				return cancel
+			}
+			for i, r := range rules {
+				if r.matches(u) {
+					return provide(creds[i].Username, creds[i].Password)
+				}
+			}
+			return cancel
+		},
+	}, false)
+}
+
+// authPatterns returns the requests the auth filter pauses; only paused
+// requests raise authRequired. Any request may be challenged by the proxy,
+// so with proxy credentials all of them are paused; otherwise only those
+// whose URL mentions a credential host.
+func authPatterns(proxy bool, rules []urlRule) []*fetch.RequestPattern {
+	if proxy {
+		return []*fetch.RequestPattern{{URLPattern: "*"}}
+	}
+	patterns := make([]*fetch.RequestPattern, len(rules))
+	for i, r := range rules {
+		patterns[i] = &fetch.RequestPattern{URLPattern: "*" + r.host + "*"}
+	}
+	return patterns
+}
+
+func provide(user, password string) *fetch.AuthChallengeResponse {
+	return &fetch.AuthChallengeResponse{
+		Response: fetch.AuthChallengeResponseResponseProvideCredentials,
+		Username: user,
+		Password: password,
+	}
+}
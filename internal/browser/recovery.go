@@ -0,0 +1,210 @@
+package browser
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/chromedp/cdproto/inspector"
+	"github.com/chromedp/chromedp"
+)
+
+// ErrBrowserCrashed is reported by Crashed when the tab's renderer died or
+// the connection to Chrome was lost.
+var ErrBrowserCrashed = errors.New("browser crashed")
+
+// watch cancels m.live when the tab's renderer crashes. A lost connection
+// needs no listener: chromedp then cancels m.Ctx.
+func (m *Manager) watch() error {
+	m.revive()
+	chromedp.ListenTarget(m.Ctx, func(ev any) {
+		if _, ok := ev.(*inspector.EventTargetCrashed); ok {
+			m.liveMu.Lock()
+			m.liveCancel()
+			m.liveMu.Unlock()
+		}
+	})
+	return chromedp.Run(m.Ctx, inspector.Enable())
+}
+
+// liveCtx returns m.live, which revive replaces.
+func (m *Manager) liveCtx() context.Context {
+	m.liveMu.Lock()
+	defer m.liveMu.Unlock()
+	return m.live
+}
+
+// revive replaces m.live by a new context for the tab.
+func (m *Manager) revive() {
+	m.liveMu.Lock()
+	defer m.liveMu.Unlock()
+	m.live, m.liveCancel = context.WithCancel(m.Ctx)
+}
+
+// Crashed returns an error wrapping ErrBrowserCrashed when the tab can no
+// longer be used; Recover reopens it.
+func (m *Manager) Crashed() error {
+	if m.Ctx.Err() != nil {
+		return fmt.Errorf("%w: connection to Chrome lost", ErrBrowserCrashed)
+	}
+	if m.liveCtx().Err() != nil {
+		return fmt.Errorf("%w: the page renderer crashed", ErrBrowserCrashed)
+	}
+	return nil
+}
+
+// checkpoint records the page that Recover reloads. Snapshot calls it for
+// every page the agent sees.
+func (m *Manager) checkpoint(url string) {
+	if strings.HasPrefix(url, "http://") || strings.HasPrefix(url, "https://") {
+		m.lastURL = url
+	}
+}
+
+// checkpointInterval is how long Checkpoint keeps a storage state before
+// reading it again for the same origin.
+const checkpointInterval = 10 * time.Second
+
+// Checkpoint saves the cookies and web storage that Recover restores in a
+// relaunched browser. Reading them takes a round-trip per frame, so the
+// state is only read again when the last page is on another origin or the
+// saved one is older than checkpointInterval.
+func (m *Manager) Checkpoint(ctx context.Context) {
+	if m.lastURL == "" {
+		return
+	}
+	origin := m.lastURL
+	if u, err := url.Parse(m.lastURL); err == nil {
+		origin = u.Scheme + "://" + u.Host
+	}
+	if m.lastState != nil && origin == m.stateOrigin && time.Since(m.stateAt) < checkpointInterval {
+		return
+	}
+	if state, err := m.StorageState(ctx); err == nil {
+		m.lastState, m.stateOrigin, m.stateAt = state, origin, time.Now()
+	}
+}
+
+// Recover reopens the tab after a crash reported by Crashed and loads the
+// last page seen by Snapshot again, which it returns. A crashed renderer is
+// replaced in the same tab. A lost browser is relaunched with the same
+// profile, or reconnected for Options.RemoteURL, and gets back its request
+// filters, emulation, cookies and web storage. Page state that is not in
+// the URL or in storage is lost.
+func (m *Manager) Recover(ctx context.Context) (string, error) {
+	if m.Ctx.Err() != nil {
+		if err := m.reopen(); err != nil {
+			return "", err
+		}
+		if m.lastState != nil {
+			if err := m.SetStorageState(ctx, m.lastState); err != nil {
+				return "", fmt.Errorf("restore storage state: %w", err)
+			}
+		}
+	} else {
+		m.revive()
+	}
+
+	if m.lastURL == "" {
+		return "", nil
+	}
+	if err := m.Navigate(ctx, m.lastURL); err != nil {
+		return "", fmt.Errorf("reload %s: %w", m.lastURL, err)
+	}
+	return m.lastURL, nil
+}
+
+// reopen replaces the lost browser connection and sets the tab up again.
+func (m *Manager) reopen() error {
+	if m.attached {
+		m.detach()
+	}
+	m.Cancel()
+	if m.remote {
+		m.cancelAlloc()
+	} else if err := m.stopChrome(); err != nil {
+		return err
+	}
+
+	var err error
+	switch {
+	case m.remote && m.attached:
+		// The attached tab may be gone with the browser; a new tab is
+		// opened then.
+		if err = m.connect(m.opts.RemoteTab); err != nil {
+			m.cancelAlloc()
+			err = m.connect("")
+		}
+	case m.remote:
+		err = m.connect("")
+	default:
+		err = m.launch()
+	}
+	if err != nil {
+		return err
+	}
+
+	m.filterMu.Lock()
+	m.listening = false
+	if len(m.filters) > 0 {
+		m.listenFetch()
+	}
+	m.filterMu.Unlock()
+	if err := m.syncFetch(context.Background()); err != nil {
+		return err
+	}
+	return m.SetEmulation(context.Background(), m.emulation)
+}
+
+// stopTimeout bounds how long reopen waits for the old Chrome to exit and
+// leave the profile.
+const stopTimeout = 10 * time.Second
+
+// stopChrome kills the launched Chrome and waits until it has exited and
+// no Chrome holds the profile: a Chrome started on a profile whose
+// SingletonLock is held hands its window to the old process and exits.
+func (m *Manager) stopChrome() error {
+	deadline := time.Now().Add(stopTimeout)
+	if m.proc != nil {
+		_ = m.proc.Kill()
+	}
+	// cancelAlloc waits for the process it started.
+	done := make(chan struct{})
+	go func() {
+		m.cancelAlloc()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Until(deadline)):
+		return fmt.Errorf("%w: the old Chrome did not exit", ErrBrowserCrashed)
+	}
+	return waitProfileFree(m.profileDir, deadline)
+}
+
+// waitProfileFree waits until the SingletonLock of the profile at dir, if
+// any, names no running process.
+func waitProfileFree(dir string, deadline time.Time) error {
+	if dir == "" {
+		return nil
+	}
+	for {
+		owner, err := os.Readlink(filepath.Join(dir, "SingletonLock"))
+		if err != nil {
+			return nil
+		}
+		pid, ok := singletonPID(owner)
+		if !ok || !processAlive(pid) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("profile %s: %w by Chrome (pid %d)", dir, ErrProfileInUse, pid)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
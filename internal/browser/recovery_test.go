@@ -0,0 +1,133 @@
+package browser_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/chromedp"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/browser"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/fixture"
+)
+
+func TestRecoverRendererCrash(t *testing.T) {
+	site := fixture.NewSite()
+	defer site.Close()
+	b := fixture.NewBrowser(t)
+	if err := b.Navigate(context.Background(), site.URL("/shop")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Snapshot(context.Background(), 1, false); err != nil {
+		t.Fatal(err)
+	}
+
+	// Crash gets no reply: the renderer that would send it is gone.
+	ctx, cancel := b.WithTimeout(time.Second)
+	_ = chromedp.Run(ctx, page.Crash())
+	cancel()
+	waitUntil(t, "renderer crash detected", func() bool { return b.Crashed() != nil })
+	if err := b.Crashed(); !errors.Is(err, browser.ErrBrowserCrashed) {
+		t.Fatalf("Crashed() = %v", err)
+	}
+
+	url, err := b.Recover(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != site.URL("/shop") || b.Crashed() != nil {
+		t.Errorf("Recover = %q, crashed: %v", url, b.Crashed())
+	}
+	if _, err := b.Snapshot(context.Background(), 2, false); err != nil {
+		t.Errorf("snapshot after recovery: %v", err)
+	}
+}
+
+func TestRecoverBrowserCrash(t *testing.T) {
+	site := fixture.NewSite()
+	defer site.Close()
+	b := fixture.NewBrowser(t)
+	if err := b.SetBlocking(context.Background(), browser.BlockOptions{ResourceTypes: []string{"image"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Navigate(context.Background(), site.URL("/shop")); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := b.Bind(context.Background())
+	err := chromedp.Run(ctx, chromedp.Evaluate(`document.cookie = "cart=42; max-age=3600"; localStorage.setItem("theme", "dark")`, nil))
+	cancel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Snapshot(context.Background(), 1, false); err != nil {
+		t.Fatal(err)
+	}
+	b.Checkpoint(context.Background())
+
+	if err := chromedp.FromContext(b.Ctx).Browser.Process().Kill(); err != nil {
+		t.Fatal(err)
+	}
+	waitUntil(t, "lost browser detected", func() bool { return b.Crashed() != nil })
+
+	url, err := b.Recover(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url != site.URL("/shop") {
+		t.Errorf("Recover = %q", url)
+	}
+	ctx, cancel = b.Bind(context.Background())
+	defer cancel()
+	var state string
+	if err := chromedp.Run(ctx, chromedp.Evaluate(`document.cookie + " " + localStorage.getItem("theme")`, &state)); err != nil {
+		t.Fatal(err)
+	}
+	if state != "cart=42 dark" {
+		t.Errorf("restored state = %q", state)
+	}
+
+	// Request blocking is installed in the new browser too.
+	before := b.BlockStats().Requests
+	if err := chromedp.Run(ctx, chromedp.Evaluate(`new Image().src = "/logo.png?after-crash"`, nil)); err != nil {
+		t.Fatal(err)
+	}
+	waitUntil(t, "image blocked after recovery", func() bool { return b.BlockStats().Requests > before })
+}
+
+func TestRecoverKeepsURLPolicy(t *testing.T) {
+	site := fixture.NewSite()
+	defer site.Close()
+	b := fixture.NewBrowser(t)
+	if err := b.SetURLPolicy(context.Background(), browser.URLPolicy{Allow: []string{"127.0.0.1/shop"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Navigate(context.Background(), site.URL("/shop")); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := b.Snapshot(context.Background(), 1, false); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := chromedp.FromContext(b.Ctx).Browser.Process().Kill(); err != nil {
+		t.Fatal(err)
+	}
+	waitUntil(t, "lost browser detected", func() bool { return b.Crashed() != nil })
+	if _, err := b.Recover(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The new tab has a new main frame; the allowlist must still apply to
+	// navigations started by the page.
+	ctx, cancel := b.Bind(context.Background())
+	defer cancel()
+	if err := chromedp.Run(ctx, chromedp.Evaluate(`location.href = "/help"`, nil)); err != nil {
+		t.Fatal(err)
+	}
+	waitUntil(t, "blocked navigation reported", func() bool { return len(b.BlockedNavigations()) > 0 })
+	time.Sleep(200 * time.Millisecond)
+	if url, err := b.Location(context.Background()); err != nil || url != site.URL("/shop") {
+		t.Errorf("location = %q, %v; want to stay on the shop", url, err)
+	}
+}
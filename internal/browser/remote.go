@@ -0,0 +1,88 @@
+package browser
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/chromedp/cdproto/target"
+	"github.com/chromedp/chromedp"
+)
+
+// newRemoteManager connects to the Chrome at opts.RemoteURL and attaches to
+// an existing tab or opens a new one. Close detaches from the browser and
+// only closes the tab if it was opened here.
+func newRemoteManager(opts Options) (*Manager, error) {
+	if opts.Proxy.Server != "" {
+		return nil, fmt.Errorf("a proxy cannot be set on a remote browser; start it with --proxy-server")
+	}
+	m := &Manager{remote: true, opts: opts}
+	if err := m.connect(opts.RemoteTab); err != nil {
+		m.Close()
+		return nil, err
+	}
+	if err := m.applyOptions(opts); err != nil {
+		m.Close()
+		return nil, err
+	}
+	return m, nil
+}
+
+// connect attaches to the tab of the remote browser selected by tab, or
+// opens a new one when tab is empty.
+func (m *Manager) connect(tab string) error {
+	allocCtx, cancelAlloc := chromedp.NewRemoteAllocator(context.Background(), m.opts.RemoteURL)
+	browserCtx, cancelBrowser := chromedp.NewContext(allocCtx)
+	m.cancelAlloc = func() {
+		cancelBrowser()
+		cancelAlloc()
+	}
+
+	// Targets connects to the browser without opening a tab.
+	targets, err := chromedp.Targets(browserCtx)
+	if err != nil {
+		return fmt.Errorf("connect to %s: %w", m.opts.RemoteURL, err)
+	}
+
+	m.attached = false
+	if tab == "" {
+		m.Ctx, m.Cancel = chromedp.NewContext(browserCtx)
+	} else {
+		id, err := findTab(targets, tab)
+		if err != nil {
+			return err
+		}
+		m.Ctx, m.Cancel = chromedp.NewContext(browserCtx, chromedp.WithTargetID(id))
+		m.attached = true
+	}
+
+	if err := chromedp.Run(m.Ctx); err != nil {
+		return fmt.Errorf("attach to tab: %w", err)
+	}
+	return m.watch()
+}
+
+// findTab returns the page target whose ID is tab or whose URL contains
+// tab.
+func findTab(targets []*target.Info, tab string) (target.ID, error) {
+	var urls []string
+	for _, t := range targets {
+		if t.Type != "page" {
+			continue
+		}
+		if string(t.TargetID) == tab || strings.Contains(t.URL, tab) {
+			return t.TargetID, nil
+		}
+		urls = append(urls, t.URL)
+	}
+	return "", fmt.Errorf("no tab matching %q; open tabs: %s", tab, strings.Join(urls, ", "))
+}
+
+// detach makes cancelling the tab context release the tab instead of
+// closing it: chromedp closes every tab of a remote browser whose context
+// ends, unless it no longer knows the target ID.
+func (m *Manager) detach() {
+	if c := chromedp.FromContext(m.Ctx); c != nil && c.Target != nil {
+		c.Target.TargetID = ""
+	}
+}
@@ -0,0 +1,169 @@
+package browser_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/chromedp/chromedp"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/browser"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/fixture"
+)
+
+// startDebugChrome starts a Chrome with remote debugging that is not owned
+// by a Manager, like a user's browser, and returns its HTTP endpoint.
+func startDebugChrome(t *testing.T, startURL string) string {
+	t.Helper()
+
+	path := fixture.ChromePath()
+	if path == "" {
+		t.Skip("Chrome not found; set CHROME_PATH to run browser tests")
+	}
+
+	dir := t.TempDir()
+	cmd := exec.Command(path, "--headless", "--no-sandbox", "--remote-debugging-port=0",
+		"--user-data-dir="+dir, "--no-first-run", startURL)
+	if err := cmd.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+	})
+
+	// Chrome writes the port it picked to DevToolsActivePort.
+	var endpoint string
+	waitUntil(t, "Chrome to report its debugging port", func() bool {
+		raw, err := os.ReadFile(filepath.Join(dir, "DevToolsActivePort"))
+		port, _, ok := strings.Cut(string(raw), "\n")
+		endpoint = "http://127.0.0.1:" + port
+		return err == nil && ok && port != ""
+	})
+	waitUntil(t, "the start page to load", func() bool {
+		return loaded(endpoint, startURL)
+	})
+	return endpoint
+}
+
+func waitUntil(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(10 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+}
+
+// loaded reports whether the page at url finished loading in the browser.
+func loaded(endpoint, url string) bool {
+	b, err := browser.NewManager(browser.Options{RemoteURL: endpoint, RemoteTab: url})
+	if err != nil {
+		return false
+	}
+	defer b.Close()
+	var state string
+	ctx, cancel := b.Bind(context.Background())
+	defer cancel()
+	err = chromedp.Run(ctx, chromedp.Evaluate("location.href === "+strconv.Quote(url)+" ? document.readyState : ''", &state))
+	return err == nil && state == "complete"
+}
+
+type debugTarget struct {
+	Type string `json:"type"`
+	URL  string `json:"url"`
+}
+
+func pageURLs(t *testing.T, endpoint string) []string {
+	t.Helper()
+	resp, err := http.Get(endpoint + "/json/list")
+	if err != nil {
+		t.Fatalf("browser not reachable: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var targets []debugTarget
+	if err := json.NewDecoder(resp.Body).Decode(&targets); err != nil {
+		t.Fatal(err)
+	}
+	var urls []string
+	for _, tt := range targets {
+		if tt.Type == "page" {
+			urls = append(urls, tt.URL)
+		}
+	}
+	return urls
+}
+
+func TestRemoteAttachToTab(t *testing.T) {
+	site := fixture.NewSite()
+	defer site.Close()
+	endpoint := startDebugChrome(t, site.URL("/tabs"))
+
+	b, err := browser.NewManager(browser.Options{RemoteURL: endpoint, RemoteTab: "/tabs"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !b.Remote() {
+		t.Error("Remote() = false")
+	}
+
+	if u, err := b.Location(context.Background()); err != nil || u != site.URL("/tabs") {
+		t.Fatalf("attached to %q (%v)", u, err)
+	}
+	if err := b.Navigate(context.Background(), site.URL("/help")); err != nil {
+		t.Fatal(err)
+	}
+
+	b.Close()
+
+	urls := pageURLs(t, endpoint)
+	if len(urls) != 1 || urls[0] != site.URL("/help") {
+		t.Errorf("after Close the user's tab should stay open on /help, pages = %v", urls)
+	}
+}
+
+func TestRemoteNewTab(t *testing.T) {
+	site := fixture.NewSite()
+	defer site.Close()
+	endpoint := startDebugChrome(t, site.URL("/"))
+
+	b, err := browser.NewManager(browser.Options{RemoteURL: endpoint})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := b.Navigate(context.Background(), site.URL("/shop")); err != nil {
+		t.Fatal(err)
+	}
+	if urls := pageURLs(t, endpoint); len(urls) != 2 {
+		t.Errorf("pages while connected = %v, want the user's tab and ours", urls)
+	}
+
+	b.Close()
+
+	// Chrome closes the tab asynchronously.
+	waitUntil(t, "our tab to close", func() bool {
+		urls := pageURLs(t, endpoint)
+		return len(urls) == 1 && urls[0] == site.URL("/")
+	})
+}
+
+func TestRemoteTabNotFound(t *testing.T) {
+	site := fixture.NewSite()
+	defer site.Close()
+	endpoint := startDebugChrome(t, site.URL("/"))
+
+	_, err := browser.NewManager(browser.Options{RemoteURL: endpoint, RemoteTab: "no-such-tab"})
+	if err == nil || !strings.Contains(err.Error(), `no tab matching "no-such-tab"`) {
+		t.Errorf("err = %v", err)
+	}
+}
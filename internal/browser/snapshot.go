@@ -5,14 +5,26 @@ import (
 	"encoding/base64"
 	"fmt"
 	"log"
+	"sort"
 	"strings"
 
 	"github.com/chromedp/cdproto/cdp"
-	"github.com/chromedp/cdproto/page"
 	"github.com/chromedp/chromedp"
 )
 
-type ElementMap map[int]cdp.BackendNodeID
+// ElementMap maps the element numbers of a snapshot to the driver's
+// references; Manager stores DOM backend node IDs.
+type ElementMap map[int]Element
+
+// IDs returns the element IDs of the snapshot in ascending order.
+func (m ElementMap) IDs() []int {
+	ids := make([]int, 0, len(m))
+	for id := range m {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
 
 type PageSnapshot struct {
 	URL              string
@@ -39,7 +51,10 @@ type axTreeResult struct {
 	Nodes []AXNode `json:"nodes"`
 }
 
-func (m *Manager) Snapshot(step int) (*PageSnapshot, error) {
+func (m *Manager) Snapshot(ctx context.Context, step int, screenshot bool) (*PageSnapshot, error) {
+	runCtx, cancel := m.Bind(ctx)
+	defer cancel()
+
 	var (
 		axNodes []AXNode
 		axErr   error
@@ -49,7 +64,7 @@ func (m *Manager) Snapshot(step int) (*PageSnapshot, error) {
 	)
 
 	err := chromedp.Run(
-		m.Ctx,
+		runCtx,
 		chromedp.Location(&url),
 		chromedp.Title(&title),
 
@@ -75,11 +90,11 @@ func (m *Manager) Snapshot(step int) (*PageSnapshot, error) {
 		}),
 
 		chromedp.ActionFunc(func(ctx context.Context) error {
+			if !screenshot {
+				return nil
+			}
 			var err error
-			buf, err = page.CaptureScreenshot().
-				WithFormat(page.CaptureScreenshotFormatJpeg).
-				WithQuality(50).
-				Do(ctx)
+			buf, err = captureScreenshot().Do(ctx)
 			return err
 		}),
 	)
@@ -87,6 +102,7 @@ func (m *Manager) Snapshot(step int) (*PageSnapshot, error) {
 	if err != nil {
 		return nil, fmt.Errorf("chromedp snapshot failed: %w", err)
 	}
+	m.checkpoint(url)
 
 	elements := make(ElementMap)
 	idCounter := 1
@@ -98,7 +114,7 @@ func (m *Manager) Snapshot(step int) (*PageSnapshot, error) {
 		if axErr != nil {
 			log.Printf("⚠️ Accessibility.getFullAXTree failed (%v), fallback to DOM", axErr)
 		}
-		treeStr = buildDOMFallback(m.Ctx, &idCounter, elements)
+		treeStr = buildDOMFallback(runCtx, &idCounter, elements)
 	}
 
 	screenshotB64 := ""
@@ -130,47 +146,48 @@ func serializeAXNodes(nodes []AXNode, idCounter *int, elements ElementMap) strin
 		}
 
 		role := axValueString(node.Role)
-		name := axValueString(node.Name)
-
-		isInteractive := isInteractiveRole(role)
-
-		if isInteractive {
-			currentID := *idCounter
+		id := 0
+		if isInteractiveRole(role) {
+			id = *idCounter
 			*idCounter++
 
 			if node.BackendDOMNodeID != 0 {
-				elements[currentID] = node.BackendDOMNodeID
+				elements[id] = node.BackendDOMNodeID
 			}
-
-			sb.WriteString(fmt.Sprintf("[%d] ", currentID))
-		} else {
-			sb.WriteString("- ")
 		}
+		writeTreeLine(&sb, id, role, axValueString(node.Name), axValueString(node.Value))
+	}
 
-		if role == "" {
-			role = "unknown"
-		}
-		sb.WriteString(fmt.Sprintf("[%s]", role))
+	return sb.String()
+}
 
-		if name != "" {
-			cleanName := strings.ReplaceAll(name, "\n", " ")
-			if len(cleanName) > 80 {
-				cleanName = cleanName[:77] + "..."
-			}
-			sb.WriteString(fmt.Sprintf(" %q", cleanName))
-		}
+// writeTreeLine writes a node of the tree sent to the model: interactive
+// nodes are numbered with id, the others have id 0.
+func writeTreeLine(sb *strings.Builder, id int, role, name, value string) {
+	if id != 0 {
+		sb.WriteString(fmt.Sprintf("[%d] ", id))
+	} else {
+		sb.WriteString("- ")
+	}
 
-		if node.Value != nil && node.Value.Value != nil {
-			valStr := axValueString(node.Value)
-			if valStr != "" {
-				sb.WriteString(fmt.Sprintf(" (Val: %s)", valStr))
-			}
+	if role == "" {
+		role = "unknown"
+	}
+	sb.WriteString(fmt.Sprintf("[%s]", role))
+
+	if name != "" {
+		cleanName := strings.ReplaceAll(name, "\n", " ")
+		if len(cleanName) > 80 {
+			cleanName = cleanName[:77] + "..."
 		}
+		sb.WriteString(fmt.Sprintf(" %q", cleanName))
+	}
 
-		sb.WriteString("\n")
+	if value != "" {
+		sb.WriteString(fmt.Sprintf(" (Val: %s)", value))
 	}
 
-	return sb.String()
+	sb.WriteString("\n")
 }
 
 func axValueString(v *AXValue) string {
@@ -206,11 +223,14 @@ func shouldSkipAX(node *AXNode) bool {
 	return false
 }
 
+// isInteractiveRole reports whether elements with the AX role get an ID.
+// Chrome reports ARIA roles in lower case ("textbox"); the camel-case
+// variants are internal role names seen in older versions.
 func isInteractiveRole(role string) bool {
-	switch role {
-	case "button", "link", "checkbox", "radioButton",
-		"searchBox", "textBox", "comboBox",
-		"menuItem", "slider", "switch":
+	switch strings.ToLower(role) {
+	case "button", "link", "checkbox", "radio", "radiobutton",
+		"searchbox", "textbox", "combobox",
+		"menuitem", "slider", "switch":
 		return true
 	default:
 		return false
@@ -0,0 +1,190 @@
+package browser_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/browser"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/fixture"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/llm/llmtest"
+)
+
+func snapshotOf(t *testing.T, b *browser.Manager, url string, screenshot bool) *browser.PageSnapshot {
+	t.Helper()
+	if err := b.Navigate(context.Background(), url); err != nil {
+		t.Fatalf("navigate %s: %v", url, err)
+	}
+	snap, err := b.Snapshot(context.Background(), 1, screenshot)
+	if err != nil {
+		t.Fatalf("snapshot %s: %v", url, err)
+	}
+	return snap
+}
+
+func TestSnapshotFixturePages(t *testing.T) {
+	b := fixture.NewBrowser(t)
+	site := fixture.NewSite()
+	defer site.Close()
+
+	tests := []struct {
+		path  string
+		title string
+		want  []llmtest.Element
+		// absent are names that must not be listed as elements.
+		absent []string
+	}{
+		{
+			path:  "/login",
+			title: "Sign in",
+			want: []llmtest.Element{
+				{Role: "textbox", Name: "Username "},
+				{Role: "textbox", Name: "Password "},
+				{Role: "button", Name: "Sign in"},
+			},
+		},
+		{
+			path:  "/form/2",
+			title: "Sign up: step 2 of 3",
+			want: []llmtest.Element{
+				{Role: "textbox", Name: "Email "},
+				{Role: "radio", Name: " Free"},
+				{Role: "radio", Name: " Pro"},
+				{Role: "button", Name: "Next"},
+			},
+		},
+		{
+			path:   "/modal",
+			title:  "Newsletter",
+			want:   []llmtest.Element{{Role: "button", Name: "Subscribe"}},
+			absent: []string{"Confirm subscription"},
+		},
+		{
+			path:  "/shop",
+			title: "Shop",
+			want: []llmtest.Element{
+				{Role: "link", Name: "Cart (0)"},
+				{Role: "searchbox", Name: "Search products "},
+				{Role: "button", Name: "Add Cola to cart"},
+			},
+		},
+		{
+			path:  "/tabs",
+			title: "Help links",
+			want:  []llmtest.Element{{Role: "link", Name: "Open help in a new tab"}},
+		},
+		{
+			// Frame contents are not part of the snapshot yet.
+			path:   "/frame",
+			title:  "Embedded widget",
+			want:   []llmtest.Element{{Role: "button", Name: "Outside frame"}},
+			absent: []string{"Start chat"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			snap := snapshotOf(t, b, site.URL(tt.path), false)
+
+			if snap.URL != site.URL(tt.path) || snap.Title != tt.title {
+				t.Errorf("url, title = %q, %q", snap.URL, snap.Title)
+			}
+
+			elements := llmtest.Elements(snap.Tree)
+			if len(elements) != len(snap.Elements) {
+				t.Errorf("%d elements in tree, %d in map", len(elements), len(snap.Elements))
+			}
+			for _, el := range elements {
+				if _, ok := snap.Elements[el.ID]; !ok {
+					t.Errorf("element %d %q has no backend node", el.ID, el.Name)
+				}
+			}
+
+			for _, want := range tt.want {
+				if !hasElement(elements, want) {
+					t.Errorf("no [%s] %q in tree:\n%s", want.Role, want.Name, snap.Tree)
+				}
+			}
+			for _, name := range tt.absent {
+				if _, ok := llmtest.FindElement(snap.Tree, "^"+name+"$"); ok {
+					t.Errorf("hidden element %q listed in tree:\n%s", name, snap.Tree)
+				}
+			}
+		})
+	}
+}
+
+func TestSnapshotFormControlRoles(t *testing.T) {
+	b := fixture.NewBrowser(t)
+	// Chrome reports these roles in lower case; each control needs an ID.
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<title>Controls</title>
+			<label>Name <input type="text"></label>
+			<label>Find <input type="search"></label>
+			<label>Size <select><option>S</option><option>M</option></select></label>
+			<label><input type="radio" name="plan"> Basic</label>
+			<div role="menu"><div role="menuitem" tabindex="0">Settings</div></div>`))
+	}))
+	defer srv.Close()
+
+	snap := snapshotOf(t, b, srv.URL, false)
+	elements := llmtest.Elements(snap.Tree)
+	for _, role := range []string{"textbox", "searchbox", "combobox", "radio", "menuitem"} {
+		found := false
+		for _, el := range elements {
+			found = found || el.Role == role
+		}
+		if !found {
+			t.Errorf("no [%s] element in tree:\n%s", role, snap.Tree)
+		}
+	}
+}
+
+func hasElement(elements []llmtest.Element, want llmtest.Element) bool {
+	for _, el := range elements {
+		if el.Role == want.Role && el.Name == want.Name {
+			return true
+		}
+	}
+	return false
+}
+
+func TestSnapshotIDsAreSequential(t *testing.T) {
+	b := fixture.NewBrowser(t)
+	site := fixture.NewSite()
+	defer site.Close()
+
+	snap := snapshotOf(t, b, site.URL("/"), false)
+
+	ids := snap.Elements.IDs()
+	if len(ids) != 7 {
+		t.Fatalf("ids = %v, want 7 navigation links", ids)
+	}
+	for i, id := range ids {
+		if id != i+1 {
+			t.Fatalf("ids = %v, want 1..7", ids)
+		}
+	}
+	if !strings.HasPrefix(snap.Tree, "- [RootWebArea] \"Fixture Site\"") {
+		t.Errorf("tree does not start with the document:\n%s", snap.Tree)
+	}
+}
+
+func TestSnapshotScreenshot(t *testing.T) {
+	b := fixture.NewBrowser(t)
+	site := fixture.NewSite()
+	defer site.Close()
+
+	if snap := snapshotOf(t, b, site.URL("/shop"), false); snap.ScreenshotBase64 != "" {
+		t.Error("screenshot taken when disabled")
+	}
+
+	snap := snapshotOf(t, b, site.URL("/shop"), true)
+	// Base64 of the JPEG start-of-image marker.
+	if !strings.HasPrefix(snap.ScreenshotBase64, "/9j/") {
+		t.Errorf("screenshot is not a JPEG: %.20q", snap.ScreenshotBase64)
+	}
+}
@@ -0,0 +1,282 @@
+package browser
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/chromedp/cdproto/cdp"
+	"github.com/chromedp/cdproto/domstorage"
+	"github.com/chromedp/cdproto/fetch"
+	"github.com/chromedp/cdproto/network"
+	"github.com/chromedp/cdproto/page"
+	"github.com/chromedp/cdproto/storage"
+	"github.com/chromedp/chromedp"
+)
+
+// StorageState is a login saved by ExportStorageState: the cookies of the
+// browser and the web storage of the origins open in the tab. The file
+// layout follows Playwright's storageState.
+type StorageState struct {
+	Cookies []Cookie      `json:"cookies"`
+	Origins []OriginState `json:"origins"`
+}
+
+// Cookie is a browser cookie. Expires is in Unix seconds, -1 for a
+// session cookie.
+type Cookie struct {
+	Name     string  `json:"name"`
+	Value    string  `json:"value"`
+	Domain   string  `json:"domain"`
+	Path     string  `json:"path"`
+	Expires  float64 `json:"expires"`
+	HTTPOnly bool    `json:"httpOnly"`
+	Secure   bool    `json:"secure"`
+	SameSite string  `json:"sameSite,omitempty"`
+}
+
+// OriginState is the localStorage and sessionStorage of one origin.
+type OriginState struct {
+	Origin         string        `json:"origin"`
+	LocalStorage   []StorageItem `json:"localStorage"`
+	SessionStorage []StorageItem `json:"sessionStorage,omitempty"`
+}
+
+type StorageItem struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// LoadStorageState reads a storage state file.
+func LoadStorageState(path string) (*StorageState, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s StorageState
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, fmt.Errorf("storage state %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+// Save writes the state to path, creating parent directories. The file
+// holds session cookies and is only readable by the owner.
+func (s *StorageState) Save(path string) error {
+	raw, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	return os.WriteFile(path, raw, 0o600)
+}
+
+// StorageState returns all cookies of the browser and the localStorage and
+// sessionStorage of every origin loaded in the tab, including frames.
+func (m *Manager) StorageState(ctx context.Context) (*StorageState, error) {
+	runCtx, cancel := m.Bind(ctx)
+	defer cancel()
+
+	state := &StorageState{Cookies: []Cookie{}, Origins: []OriginState{}}
+	err := chromedp.Run(runCtx, chromedp.ActionFunc(func(ctx context.Context) error {
+		// Storage.getCookies replaces Network.getAllCookies.
+		cookies, err := storage.GetCookies().Do(ctx)
+		if err != nil {
+			return fmt.Errorf("get cookies: %w", err)
+		}
+		for _, c := range cookies {
+			state.Cookies = append(state.Cookies, exportCookie(c))
+		}
+
+		tree, err := page.GetFrameTree().Do(ctx)
+		if err != nil {
+			return fmt.Errorf("get frames: %w", err)
+		}
+		seen := map[string]bool{}
+		for _, f := range frames(tree) {
+			origin := f.SecurityOrigin
+			if seen[origin] || !webOrigin(origin) {
+				continue
+			}
+			seen[origin] = true
+
+			o, err := originState(ctx, f.ID, origin)
+			if err != nil {
+				return fmt.Errorf("storage of %s: %w", origin, err)
+			}
+			if len(o.LocalStorage) > 0 || len(o.SessionStorage) > 0 {
+				state.Origins = append(state.Origins, o)
+			}
+		}
+		return nil
+	}))
+	if err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// ExportStorageState saves StorageState to path.
+func (m *Manager) ExportStorageState(ctx context.Context, path string) error {
+	state, err := m.StorageState(ctx)
+	if err != nil {
+		return err
+	}
+	return state.Save(path)
+}
+
+// ImportStorageState loads a file written by ExportStorageState into the
+// browser. Call it before the first Navigate: web storage is written by
+// opening each origin on a blank stub page, and the tab is left on
+// about:blank.
+func (m *Manager) ImportStorageState(ctx context.Context, path string) error {
+	state, err := LoadStorageState(path)
+	if err != nil {
+		return err
+	}
+	return m.SetStorageState(ctx, state)
+}
+
+// SetStorageState adds the cookies and web storage of state to the
+// browser; see ImportStorageState.
+func (m *Manager) SetStorageState(ctx context.Context, state *StorageState) error {
+	runCtx, cancel := m.Bind(ctx)
+	defer cancel()
+
+	if len(state.Cookies) > 0 {
+		params := make([]*network.CookieParam, 0, len(state.Cookies))
+		for _, c := range state.Cookies {
+			params = append(params, c.param())
+		}
+		if err := chromedp.Run(runCtx, storage.SetCookies(params)); err != nil {
+			return fmt.Errorf("set cookies: %w", err)
+		}
+	}
+	if len(state.Origins) == 0 {
+		return nil
+	}
+
+	// Requests are answered with an empty page, so restoring storage does
+	// not hit the sites.
+	stub := requestFilter{
+		name:     "storage-state",
+		patterns: []*fetch.RequestPattern{{URLPattern: "*"}},
+		handle: func(ev *fetch.EventRequestPaused) chromedp.Action {
+			return fetch.FulfillRequest(ev.RequestID, 200).
+				WithResponseHeaders([]*fetch.HeaderEntry{{Name: "Content-Type", Value: "text/html"}}).
+				WithBody(base64.StdEncoding.EncodeToString([]byte("<html></html>")))
+		},
+	}
+	if err := m.setRequestFilter(ctx, stub, true); err != nil {
+		return fmt.Errorf("intercept requests: %w", err)
+	}
+	defer m.removeRequestFilter(ctx, stub.name)
+
+	for _, o := range state.Origins {
+		if !webOrigin(o.Origin) {
+			continue
+		}
+		data, err := json.Marshal(o)
+		if err != nil {
+			return err
+		}
+		err = chromedp.Run(runCtx,
+			chromedp.Navigate(o.Origin+"/"),
+			chromedp.Evaluate(fmt.Sprintf(restoreStorageJS, data), nil),
+		)
+		if err != nil {
+			return fmt.Errorf("restore storage of %s: %w", o.Origin, err)
+		}
+	}
+	if err := m.removeRequestFilter(ctx, stub.name); err != nil {
+		return err
+	}
+	return chromedp.Run(runCtx, chromedp.Navigate("about:blank"))
+}
+
+const restoreStorageJS = `(o => {
+	for (const {name, value} of o.localStorage || []) localStorage.setItem(name, value);
+	for (const {name, value} of o.sessionStorage || []) sessionStorage.setItem(name, value);
+})(%s)`
+
+func originState(ctx context.Context, frameID cdp.FrameID, origin string) (OriginState, error) {
+	o := OriginState{Origin: origin, LocalStorage: []StorageItem{}}
+	key, err := storage.GetStorageKeyForFrame(frameID).Do(ctx)
+	if err != nil {
+		return o, err
+	}
+	for _, local := range []bool{true, false} {
+		items, err := domstorage.GetDOMStorageItems(&domstorage.StorageID{StorageKey: domstorage.SerializedStorageKey(key), IsLocalStorage: local}).Do(ctx)
+		if err != nil {
+			return o, err
+		}
+		for _, item := range items {
+			if len(item) != 2 {
+				continue
+			}
+			if local {
+				o.LocalStorage = append(o.LocalStorage, StorageItem{Name: item[0], Value: item[1]})
+			} else {
+				o.SessionStorage = append(o.SessionStorage, StorageItem{Name: item[0], Value: item[1]})
+			}
+		}
+	}
+	return o, nil
+}
+
+func frames(tree *page.FrameTree) []*cdp.Frame {
+	if tree == nil {
+		return nil
+	}
+	list := []*cdp.Frame{tree.Frame}
+	for _, child := range tree.ChildFrames {
+		list = append(list, frames(child)...)
+	}
+	return list
+}
+
+func webOrigin(origin string) bool {
+	u, err := url.Parse(origin)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+func exportCookie(c *network.Cookie) Cookie {
+	expires := c.Expires
+	if c.Session {
+		expires = -1
+	}
+	return Cookie{
+		Name:     c.Name,
+		Value:    c.Value,
+		Domain:   c.Domain,
+		Path:     c.Path,
+		Expires:  expires,
+		HTTPOnly: c.HTTPOnly,
+		Secure:   c.Secure,
+		SameSite: c.SameSite.String(),
+	}
+}
+
+func (c Cookie) param() *network.CookieParam {
+	p := &network.CookieParam{
+		Name:     c.Name,
+		Value:    c.Value,
+		Domain:   c.Domain,
+		Path:     c.Path,
+		HTTPOnly: c.HTTPOnly,
+		Secure:   c.Secure,
+		SameSite: network.CookieSameSite(c.SameSite),
+	}
+	if c.Expires > 0 {
+		t := cdp.TimeSinceEpoch(time.Unix(0, int64(c.Expires*float64(time.Second))))
+		p.Expires = &t
+	}
+	return p
+}
@@ -0,0 +1,74 @@
+package browser_test
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/chromedp/chromedp"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/browser"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/fixture"
+)
+
+func TestStorageStateRoundTrip(t *testing.T) {
+	site := fixture.NewSite()
+	defer site.Close()
+	path := filepath.Join(t.TempDir(), "state.json")
+
+	// Log in and leave something in web storage.
+	src := fixture.NewBrowser(t)
+	ctx, cancel := src.Bind(context.Background())
+	defer cancel()
+	err := chromedp.Run(ctx,
+		chromedp.Navigate(site.URL("/login")),
+		chromedp.SendKeys(`input[name=username]`, fixture.Username, chromedp.ByQuery),
+		chromedp.SendKeys(`input[name=password]`, fixture.Password, chromedp.ByQuery),
+		chromedp.Click(`button[type=submit]`, chromedp.ByQuery),
+		chromedp.WaitVisible(`//h1[starts-with(., "Welcome")]`),
+		chromedp.Evaluate(`localStorage.setItem("theme", "dark"); sessionStorage.setItem("draft", "hello")`, nil),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := src.ExportStorageState(context.Background(), path); err != nil {
+		t.Fatal(err)
+	}
+
+	state, err := browser.LoadStorageState(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(state.Cookies) != 1 || state.Cookies[0].Name != "session" || state.Cookies[0].Expires != -1 {
+		t.Errorf("cookies = %+v", state.Cookies)
+	}
+	if len(state.Origins) != 1 || state.Origins[0].Origin != site.URL("") {
+		t.Fatalf("origins = %+v", state.Origins)
+	}
+
+	dst := fixture.NewBrowser(t)
+	if err := dst.ImportStorageState(context.Background(), path); err != nil {
+		t.Fatal(err)
+	}
+	if err := dst.Navigate(context.Background(), site.URL("/account")); err != nil {
+		t.Fatal(err)
+	}
+	text, err := dst.Text(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(text, fixture.Username) {
+		t.Errorf("not logged in after import; page:\n%s", text)
+	}
+
+	ctx2, cancel2 := dst.Bind(context.Background())
+	defer cancel2()
+	var stored []string
+	if err := chromedp.Run(ctx2, chromedp.Evaluate(`[localStorage.getItem("theme"), sessionStorage.getItem("draft")]`, &stored)); err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 2 || stored[0] != "dark" || stored[1] != "hello" {
+		t.Errorf("web storage = %q", stored)
+	}
+}
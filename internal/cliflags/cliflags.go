@@ -0,0 +1,89 @@
+// Package cliflags holds the flag.Value types shared by the commands.
+package cliflags
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/browser"
+)
+
+// List collects a repeatable flag; a value may also hold a
+// comma-separated list.
+type List []string
+
+func (l *List) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *List) Set(v string) error {
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*l = append(*l, item)
+		}
+	}
+	return nil
+}
+
+// Headers collects -header values, 'Name: value'.
+type Headers map[string]string
+
+func (h *Headers) String() string {
+	parts := make([]string, 0, len(*h))
+	for k, v := range *h {
+		parts = append(parts, k+": "+v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (h *Headers) Set(v string) error {
+	name, value, ok := strings.Cut(v, ":")
+	if !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("header must look like 'Name: value', got %q", v)
+	}
+	if *h == nil {
+		*h = make(Headers)
+	}
+	(*h)[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	return nil
+}
+
+// Credentials collects -http-auth values, 'site=user:password'.
+type Credentials []browser.SiteCredentials
+
+func (c *Credentials) String() string {
+	sites := make([]string, len(*c))
+	for i, cred := range *c {
+		sites[i] = cred.Site + "=" + cred.Username + ":***"
+	}
+	return strings.Join(sites, ",")
+}
+
+func (c *Credentials) Set(v string) error {
+	site, login, ok := strings.Cut(v, "=")
+	user, password, ok2 := strings.Cut(login, ":")
+	if !ok || !ok2 || strings.TrimSpace(site) == "" || user == "" {
+		return fmt.Errorf("credentials must look like 'site=user:password', got %q", site+"=...")
+	}
+	*c = append(*c, browser.SiteCredentials{Site: strings.TrimSpace(site), Username: user, Password: password})
+	return nil
+}
+
+// Policy holds the -allow and -deny flags of the URL policy.
+type Policy struct {
+	Allow List
+	Deny  List
+}
+
+// Register adds -allow and -deny to fs.
+func (p *Policy) Register(fs *flag.FlagSet) {
+	fs.Var(&p.Allow, "allow", "only let the tab navigate to these sites: host, *.host or host/path (repeatable, comma-separated)")
+	fs.Var(&p.Deny, "deny", "block every request to these sites, e.g. a payment processor (repeatable, comma-separated)")
+}
+
+// URLPolicy returns the policy the flags describe.
+func (p *Policy) URLPolicy() browser.URLPolicy {
+	return browser.URLPolicy{Allow: slices.Clone(p.Allow), Deny: slices.Clone(p.Deny)}
+}
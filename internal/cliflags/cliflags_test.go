@@ -0,0 +1,82 @@
+package cliflags
+
+import (
+	"flag"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/browser"
+)
+
+func TestFlags(t *testing.T) {
+	var (
+		list  List
+		hdrs  Headers
+		creds Credentials
+	)
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(&list, "block", "")
+	fs.Var(&hdrs, "header", "")
+	fs.Var(&creds, "http-auth", "")
+
+	err := fs.Parse([]string{
+		"-block", "image, font", "-block", "media",
+		"-header", "X-Team: search", "-header", "Authorization: Bearer a:b",
+		"-http-auth", "staging.test=admin:p@ss:word",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"image", "font", "media"}; !slices.Equal(list, want) {
+		t.Errorf("list = %q, want %q", list, want)
+	}
+	if hdrs["X-Team"] != "search" || hdrs["Authorization"] != "Bearer a:b" {
+		t.Errorf("headers = %v", hdrs)
+	}
+	want := browser.SiteCredentials{Site: "staging.test", Username: "admin", Password: "p@ss:word"}
+	if len(creds) != 1 || creds[0] != want {
+		t.Errorf("credentials = %+v, want %+v", creds, want)
+	}
+	if s := creds.String(); strings.Contains(s, "p@ss") {
+		t.Errorf("String() = %q shows the password", s)
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	var p Policy
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	p.Register(fs)
+	if err := fs.Parse([]string{"-allow", "shop.test, *.cdn.test", "-deny", "pay.test"}); err != nil {
+		t.Fatal(err)
+	}
+	got := p.URLPolicy()
+	if !slices.Equal(got.Allow, []string{"shop.test", "*.cdn.test"}) || !slices.Equal(got.Deny, []string{"pay.test"}) {
+		t.Errorf("URLPolicy() = %+v", got)
+	}
+	got.Allow = append(got.Allow[:0], "other.test")
+	if p.Allow[0] != "shop.test" {
+		t.Error("URLPolicy() shares the flag slices")
+	}
+}
+
+func TestFlagErrors(t *testing.T) {
+	for _, args := range [][]string{
+		{"-header", "no colon"},
+		{"-http-auth", "staging.test=admin"},
+		{"-http-auth", "=admin:pw"},
+	} {
+		var (
+			hdrs  Headers
+			creds Credentials
+		)
+		fs := flag.NewFlagSet("test", flag.ContinueOnError)
+		fs.SetOutput(io.Discard)
+		fs.Var(&hdrs, "header", "")
+		fs.Var(&creds, "http-auth", "")
+		if err := fs.Parse(args); err == nil {
+			t.Errorf("%q: expected an error", args)
+		}
+	}
+}
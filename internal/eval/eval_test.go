@@ -0,0 +1,235 @@
+package eval
+
+import (
+	"bytes"
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/agent"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/browser"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/fixture"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/llm"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/llm/llmtest"
+)
+
+func TestLoadSuite(t *testing.T) {
+	s, err := LoadSuite("testdata/fixture_suite.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Name != "fixture" || len(s.Tasks) != 6 {
+		t.Errorf("suite = %s with %d tasks", s.Name, len(s.Tasks))
+	}
+}
+
+func TestSuiteValidate(t *testing.T) {
+	valid := func() Task {
+		return Task{Name: "a", URL: "/", Task: "t", Checks: []Check{{URLContains: "/"}}}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(*Suite)
+		want   string
+	}{
+		{"no tasks", func(s *Suite) { s.Tasks = nil }, "no tasks"},
+		{"duplicate", func(s *Suite) { s.Tasks = append(s.Tasks, valid()) }, "duplicate"},
+		{"no checks", func(s *Suite) { s.Tasks[0].Checks = nil }, "no checks"},
+		{"missing task", func(s *Suite) { s.Tasks[0].Task = "" }, "required"},
+		{"two conditions", func(s *Suite) { s.Tasks[0].Checks[0].PageContains = "x" }, "exactly one"},
+		{"bad regexp", func(s *Suite) { s.Tasks[0].Checks = []Check{{AnswerMatches: "("}} }, "missing closing"},
+		{"bad schema", func(s *Suite) { s.Tasks[0].Schema = []byte(`{"type": 1}`) }, "task \"a\""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Suite{Tasks: []Task{valid()}}
+			tt.modify(s)
+			err := s.Validate()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("err = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckEval(t *testing.T) {
+	out := Outcome{
+		URL:           "http://site.test/account",
+		Page:          "Welcome, demo\nYou are signed in.",
+		Answer:        "The total is $11",
+		AnswerSuccess: llm.FinishSuccess,
+	}
+
+	tests := []struct {
+		check Check
+		pass  bool
+	}{
+		{Check{URLContains: "/account"}, true},
+		{Check{URLContains: "/login"}, false},
+		{Check{URLMatches: `/account$`}, true},
+		{Check{PageContains: "Welcome, demo"}, true},
+		{Check{PageContains: "Welcome, admin"}, false},
+		{Check{PageNotContains: "Sign in"}, true},
+		{Check{PageNotContains: "signed in"}, false},
+		{Check{AnswerContains: "TOTAL IS"}, true},
+		{Check{AnswerMatches: `\$1[0-9]\b`}, true},
+		{Check{AnswerMatches: `\$9\b`}, false},
+		{Check{AnswerSuccess: llm.FinishSuccess}, true},
+		{Check{AnswerSuccess: llm.FinishPartial}, false},
+		// A bad pattern fails the check instead of panicking.
+		{Check{URLMatches: "("}, false},
+		{Check{AnswerMatches: "[a-"}, false},
+	}
+	for _, tt := range tests {
+		msg := tt.check.Eval(out)
+		if (msg == "") != tt.pass {
+			t.Errorf("%s: pass = %v (%s), want %v", tt.check, msg == "", msg, tt.pass)
+		}
+	}
+}
+
+func TestCheckString(t *testing.T) {
+	c := Check{URLContains: "/account", AnswerMatches: "total", PageContains: "Welcome"}
+	want := `url_contains "/account", page_contains "Welcome", answer_matches "total"`
+	for range 10 {
+		if got := c.String(); got != want {
+			t.Fatalf("String() = %s, want %s", got, want)
+		}
+	}
+}
+
+func TestRunRejectsInvalidSuite(t *testing.T) {
+	suite := &Suite{Name: "bad", Tasks: []Task{{Name: "a", URL: "/", Task: "t", Checks: []Check{{URLMatches: "("}}}}}
+	_, err := Run(context.Background(), suite, Config{
+		NewBrowser: func() (browser.Browser, error) {
+			t.Fatal("browser opened for an invalid suite")
+			return nil, nil
+		},
+	})
+	if err == nil || !strings.Contains(err.Error(), "missing closing") {
+		t.Errorf("err = %v, want the pattern error", err)
+	}
+}
+
+func result(name string, ok bool, steps, tokens int, d time.Duration) TaskResult {
+	return TaskResult{Name: name, Success: ok, Steps: steps, Usage: llm.Usage{Calls: steps, PromptTokens: tokens}, Duration: d}
+}
+
+func TestCompare(t *testing.T) {
+	baseline := &Report{Tasks: []TaskResult{
+		result("login", true, 3, 300, time.Second),
+		result("signup", false, 12, 1200, 4*time.Second),
+		result("feed", true, 4, 400, time.Second),
+		result("old", true, 1, 100, time.Second),
+	}}
+	current := &Report{Tasks: []TaskResult{
+		result("login", true, 3, 300, time.Second),
+		result("signup", true, 8, 800, 2*time.Second),
+		result("feed", false, 10, 1000, 3*time.Second),
+		result("new", true, 2, 200, time.Second),
+	}}
+
+	d := Compare(baseline, current)
+
+	var regressed []string
+	for _, c := range d.Regressions() {
+		regressed = append(regressed, c.Name)
+	}
+	if strings.Join(regressed, ",") != "feed,old" {
+		t.Errorf("regressions = %v, want feed and old", regressed)
+	}
+	if d.Baseline.SuccessRate() != 0.75 || d.Current.SuccessRate() != 0.75 {
+		t.Errorf("success rates = %v, %v", d.Baseline.SuccessRate(), d.Current.SuccessRate())
+	}
+
+	var buf bytes.Buffer
+	if err := d.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	text := buf.String()
+	for _, want := range []string{"REGRESSED", "fixed", "removed", "new (pass)", "8 (-4)", "2 regression(s): feed, old"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("diff output lacks %q:\n%s", want, text)
+		}
+	}
+}
+
+func TestReportSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reports", "base.json")
+	rep := &Report{Suite: "s", Tasks: []TaskResult{result("login", true, 3, 300, time.Second)}}
+	if err := rep.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := LoadReport(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Tasks) != 1 || got.Tasks[0].Usage.TotalTokens() != 300 || got.Tasks[0].Duration != time.Second {
+		t.Errorf("loaded report = %+v", got)
+	}
+
+	var buf bytes.Buffer
+	if err := got.WriteText(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(buf.String(), "1/1 (100%)") {
+		t.Errorf("report output:\n%s", buf.String())
+	}
+}
+
+func TestRunFixture(t *testing.T) {
+	site := fixture.NewSite()
+	defer site.Close()
+
+	suite := &Suite{Name: "e2e", Tasks: []Task{
+		{
+			Name: "login", URL: "/login", Task: "sign in",
+			Checks: []Check{{URLContains: "/account"}, {PageContains: "Welcome, demo"}},
+		},
+		{
+			Name: "total", URL: "/shop", Task: "what is in the cart?",
+			Checks: []Check{{AnswerContains: "pizza"}},
+		},
+	}}
+
+	fake := llmtest.NewScripted(
+		llmtest.TypeMatching("^Username", fixture.Username, false),
+		llmtest.TypeMatching("^Password", fixture.Password, true),
+		llmtest.Finish("signed in"),
+		llmtest.Finish("the cart is empty"),
+	)
+
+	rep, err := Run(context.Background(), suite, Config{
+		Client: fake,
+		Options: agent.Options{
+			MaxSteps:           5,
+			StepDelay:          300 * time.Millisecond,
+			DisableScreenshots: true,
+			Observers:          []agent.Observer{},
+			Approve:            func(context.Context, llm.Action) bool { return false },
+		},
+		NewBrowser: func() (browser.Browser, error) { return fixture.NewBrowser(t), nil },
+		BaseURL:    site.URL("/"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(rep.Tasks) != 2 {
+		t.Fatalf("tasks = %+v", rep.Tasks)
+	}
+	login, total := rep.Tasks[0], rep.Tasks[1]
+	if !login.Success || login.Steps != 3 || login.ExitReason != agent.ExitFinished {
+		t.Errorf("login = %+v", login)
+	}
+	if total.Success || len(total.Failures) != 1 || !strings.Contains(total.Failures[0], "does not contain \"pizza\"") {
+		t.Errorf("total = %+v", total)
+	}
+	if got := rep.Totals().SuccessRate(); got != 0.5 {
+		t.Errorf("success rate = %v", got)
+	}
+}
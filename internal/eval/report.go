@@ -0,0 +1,268 @@
+package eval
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/agent"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/llm"
+)
+
+// TaskResult is the score of one task.
+type TaskResult struct {
+	Name       string           `json:"name"`
+	Success    bool             `json:"success"`
+	Failures   []string         `json:"failures,omitempty"`
+	Error      string           `json:"error,omitempty"`
+	ExitReason agent.ExitReason `json:"exit_reason,omitempty"`
+	Steps      int              `json:"steps"`
+	Usage      llm.Usage        `json:"usage"`
+	Cost       float64          `json:"cost_usd,omitempty"`
+	Duration   time.Duration    `json:"duration"`
+	Blocked    int              `json:"blocked_requests,omitempty"`
+}
+
+// Report is the result of running a suite; saved reports serve as
+// baselines.
+type Report struct {
+	Suite     string        `json:"suite"`
+	StartedAt time.Time     `json:"started_at"`
+	Duration  time.Duration `json:"duration"`
+	Tasks     []TaskResult  `json:"tasks"`
+}
+
+// Totals aggregates the tasks of a report.
+type Totals struct {
+	Tasks     int
+	Succeeded int
+	Steps     int
+	Usage     llm.Usage
+	Cost      float64
+	Duration  time.Duration
+	Blocked   int
+}
+
+func (t Totals) SuccessRate() float64 {
+	if t.Tasks == 0 {
+		return 0
+	}
+	return float64(t.Succeeded) / float64(t.Tasks)
+}
+
+func (r *Report) Totals() Totals {
+	var t Totals
+	for _, res := range r.Tasks {
+		t.Tasks++
+		if res.Success {
+			t.Succeeded++
+		}
+		t.Steps += res.Steps
+		t.Usage = t.Usage.Add(res.Usage)
+		t.Cost += res.Cost
+		t.Duration += res.Duration
+		t.Blocked += res.Blocked
+	}
+	return t
+}
+
+func (r *Report) task(name string) *TaskResult {
+	for i := range r.Tasks {
+		if r.Tasks[i].Name == name {
+			return &r.Tasks[i]
+		}
+	}
+	return nil
+}
+
+// LoadReport reads a report written by Save.
+func LoadReport(path string) (*Report, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var r Report
+	if err := json.Unmarshal(raw, &r); err != nil {
+		return nil, fmt.Errorf("report %s: %w", path, err)
+	}
+	return &r, nil
+}
+
+// Save writes the report to path, creating parent directories.
+func (r *Report) Save(path string) error {
+	raw, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, raw, 0o644)
+}
+
+// WriteText prints a table with one row per task and a total row.
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TASK\tRESULT\tSTEPS\tTOKENS\tCOST\tTIME\tBLOCKED")
+	for _, res := range r.Tasks {
+		fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%s\t%s\t%d\n",
+			res.Name, status(res.Success), res.Steps, res.Usage.TotalTokens(), formatCost(res.Cost), formatDuration(res.Duration), res.Blocked)
+	}
+	t := r.Totals()
+	fmt.Fprintf(tw, "TOTAL\t%d/%d (%.0f%%)\t%d\t%d\t%s\t%s\t%d\n",
+		t.Succeeded, t.Tasks, 100*t.SuccessRate(), t.Steps, t.Usage.TotalTokens(), formatCost(t.Cost), formatDuration(t.Duration), t.Blocked)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, res := range r.Tasks {
+		if res.Success {
+			continue
+		}
+		fmt.Fprintf(w, "\n✗ %s\n", res.Name)
+		if res.Error != "" {
+			fmt.Fprintf(w, "  error: %s\n", res.Error)
+		}
+		for _, f := range res.Failures {
+			fmt.Fprintf(w, "  - %s\n", f)
+		}
+	}
+	return nil
+}
+
+func status(ok bool) string {
+	if ok {
+		return "pass"
+	}
+	return "FAIL"
+}
+
+func formatCost(c float64) string {
+	if c == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("$%.4f", c)
+}
+
+func formatDuration(d time.Duration) string {
+	return d.Round(100 * time.Millisecond).String()
+}
+
+// Change is the comparison of one task between a baseline and a report.
+type Change struct {
+	Name string
+	// Baseline or Current is nil for tasks only present on one side.
+	Baseline *TaskResult
+	Current  *TaskResult
+}
+
+func (c Change) Regressed() bool {
+	return c.Baseline != nil && c.Baseline.Success && (c.Current == nil || !c.Current.Success)
+}
+
+func (c Change) Fixed() bool {
+	return c.Current != nil && c.Current.Success && (c.Baseline == nil || !c.Baseline.Success)
+}
+
+// Diff compares a report with a baseline.
+type Diff struct {
+	Baseline Totals
+	Current  Totals
+	Changes  []Change
+}
+
+// Compare matches the tasks of current and baseline by name.
+func Compare(baseline, current *Report) *Diff {
+	d := &Diff{Baseline: baseline.Totals(), Current: current.Totals()}
+	for i := range current.Tasks {
+		cur := &current.Tasks[i]
+		d.Changes = append(d.Changes, Change{Name: cur.Name, Baseline: baseline.task(cur.Name), Current: cur})
+	}
+	for i := range baseline.Tasks {
+		base := &baseline.Tasks[i]
+		if current.task(base.Name) == nil {
+			d.Changes = append(d.Changes, Change{Name: base.Name, Baseline: base})
+		}
+	}
+	return d
+}
+
+// Regressions returns the tasks that passed in the baseline and fail now.
+func (d *Diff) Regressions() []Change {
+	var out []Change
+	for _, c := range d.Changes {
+		if c.Regressed() {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// WriteText prints the per-task changes and the totals of both reports.
+func (d *Diff) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TASK\tRESULT\tSTEPS\tTOKENS\tTIME")
+	for _, c := range d.Changes {
+		var result string
+		switch {
+		case c.Current == nil:
+			result = "removed"
+		case c.Baseline == nil:
+			result = "new (" + status(c.Current.Success) + ")"
+		case c.Regressed():
+			result = "REGRESSED"
+		case c.Fixed():
+			result = "fixed"
+		default:
+			result = status(c.Current.Success)
+		}
+
+		var base, cur TaskResult
+		if c.Baseline != nil {
+			base = *c.Baseline
+		}
+		if c.Current != nil {
+			cur = *c.Current
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\n", c.Name, result,
+			delta(base.Steps, cur.Steps),
+			delta(base.Usage.TotalTokens(), cur.Usage.TotalTokens()),
+			durationDelta(base.Duration, cur.Duration))
+	}
+	fmt.Fprintf(tw, "TOTAL\t%.0f%% → %.0f%%\t%s\t%s\t%s\n",
+		100*d.Baseline.SuccessRate(), 100*d.Current.SuccessRate(),
+		delta(d.Baseline.Steps, d.Current.Steps),
+		delta(d.Baseline.Usage.TotalTokens(), d.Current.Usage.TotalTokens()),
+		durationDelta(d.Baseline.Duration, d.Current.Duration))
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	if regs := d.Regressions(); len(regs) > 0 {
+		names := make([]string, len(regs))
+		for i, c := range regs {
+			names[i] = c.Name
+		}
+		fmt.Fprintf(w, "\n%d regression(s): %s\n", len(regs), strings.Join(names, ", "))
+	}
+	return nil
+}
+
+func delta(base, cur int) string {
+	if base == cur {
+		return fmt.Sprint(cur)
+	}
+	return fmt.Sprintf("%d (%+d)", cur, cur-base)
+}
+
+func durationDelta(base, cur time.Duration) string {
+	if base == 0 {
+		return formatDuration(cur)
+	}
+	return fmt.Sprintf("%s (%+.0f%%)", formatDuration(cur), 100*(float64(cur)/float64(base)-1))
+}
@@ -0,0 +1,142 @@
+package eval
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/agent"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/browser"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/llm"
+)
+
+// Config describes how the tasks of a suite are run.
+type Config struct {
+	Client  llm.Client
+	Options agent.Options
+	// NewBrowser starts the browser for one task; it is closed when the
+	// task ends.
+	NewBrowser func() (browser.Browser, error)
+	// BaseURL resolves relative task URLs.
+	BaseURL string
+	// OnTask is called after each task.
+	OnTask func(TaskResult)
+}
+
+// Run validates the suite and runs every task in order. Task failures are
+// recorded in the report; an error is only returned for an invalid suite or
+// when ctx is done, together with the results so far.
+func Run(ctx context.Context, s *Suite, cfg Config) (*Report, error) {
+	rep := &Report{Suite: s.Name, StartedAt: time.Now()}
+	defer func() { rep.Duration = time.Since(rep.StartedAt) }()
+
+	if err := s.Validate(); err != nil {
+		return rep, fmt.Errorf("suite %s: %w", s.Name, err)
+	}
+
+	for _, t := range s.Tasks {
+		if err := ctx.Err(); err != nil {
+			return rep, err
+		}
+
+		res := runTask(ctx, t, cfg)
+		rep.Tasks = append(rep.Tasks, res)
+		if cfg.OnTask != nil {
+			cfg.OnTask(res)
+		}
+	}
+	return rep, ctx.Err()
+}
+
+func runTask(ctx context.Context, t Task, cfg Config) TaskResult {
+	start := time.Now()
+	res := TaskResult{Name: t.Name}
+	defer func() { res.Duration = time.Since(start) }()
+
+	startURL, err := resolveURL(cfg.BaseURL, t.URL)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	b, err := cfg.NewBrowser()
+	if err != nil {
+		res.Error = fmt.Sprintf("start browser: %v", err)
+		return res
+	}
+	defer b.Close()
+
+	if err := b.Navigate(ctx, startURL); err != nil {
+		res.Error = fmt.Sprintf("open %s: %v", startURL, err)
+		return res
+	}
+
+	ag := agent.NewAgentWithOptions(b, cfg.Client, cfg.Options)
+	task := agent.BuildTaskWithEnvironment(t.Task, startURL)
+
+	var run *agent.RunResult
+	if len(t.Schema) > 0 {
+		run, err = ag.RunWithSchema(ctx, task, t.Schema, t.MaxSteps)
+	} else {
+		run, err = ag.RunWithResult(ctx, task, t.MaxSteps)
+	}
+	if err != nil {
+		res.Error = err.Error()
+	}
+	if run == nil {
+		return res
+	}
+
+	res.ExitReason = run.ExitReason
+	res.Steps = len(run.Steps)
+	res.Usage = run.Usage
+	res.Cost = run.Cost
+	res.Blocked = run.Blocked.Requests
+
+	// Checks look at the browser, not the last snapshot of the run, which
+	// was taken before the final action.
+	out := Outcome{URL: run.FinalURL}
+	if u, err := b.Location(ctx); err == nil {
+		out.URL = u
+	}
+	if run.Answer != nil {
+		out.Answer = run.Answer.Answer
+		if len(run.Answer.Data) > 0 {
+			out.Answer += "\n" + string(run.Answer.Data)
+		}
+		out.AnswerSuccess = run.Answer.Success
+	}
+	if out.Page, err = b.Text(ctx); err != nil {
+		res.Failures = append(res.Failures, fmt.Sprintf("read final page: %v", err))
+	}
+
+	if run.ExitReason != agent.ExitFinished {
+		res.Failures = append(res.Failures, fmt.Sprintf("run ended with %s", run.ExitReason))
+	}
+	for _, c := range t.Checks {
+		if msg := c.Eval(out); msg != "" {
+			res.Failures = append(res.Failures, msg)
+		}
+	}
+	res.Success = len(res.Failures) == 0
+	return res
+}
+
+func resolveURL(base, ref string) (string, error) {
+	u, err := url.Parse(ref)
+	if err != nil {
+		return "", err
+	}
+	if u.IsAbs() {
+		return ref, nil
+	}
+	if base == "" {
+		return "", fmt.Errorf("relative task URL %q needs a base URL", ref)
+	}
+	b, err := url.Parse(base)
+	if err != nil {
+		return "", err
+	}
+	return b.ResolveReference(u).String(), nil
+}
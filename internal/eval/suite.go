@@ -0,0 +1,203 @@
+// Package eval runs the agent on a suite of tasks with known outcomes and
+// scores the runs, so that prompt and model changes can be compared.
+package eval
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/llm"
+)
+
+// Suite is a set of tasks, stored as JSON.
+type Suite struct {
+	Name  string `json:"name"`
+	Tasks []Task `json:"tasks"`
+}
+
+// Task is one agent run. A task succeeds when the agent finishes and every
+// check passes.
+type Task struct {
+	Name string `json:"name"`
+	// URL is the start page; a relative URL is resolved against the base
+	// URL of the run.
+	URL      string `json:"url"`
+	Task     string `json:"task"`
+	MaxSteps int    `json:"max_steps,omitempty"`
+	// Schema switches the run to structured extraction.
+	Schema json.RawMessage `json:"schema,omitempty"`
+	Checks []Check         `json:"checks"`
+}
+
+// Check is a condition on the end state of a run. Exactly one field is set.
+type Check struct {
+	URLContains     string           `json:"url_contains,omitempty"`
+	URLMatches      string           `json:"url_matches,omitempty"`
+	PageContains    string           `json:"page_contains,omitempty"`
+	PageNotContains string           `json:"page_not_contains,omitempty"`
+	AnswerContains  string           `json:"answer_contains,omitempty"`
+	AnswerMatches   string           `json:"answer_matches,omitempty"`
+	AnswerSuccess   llm.FinishStatus `json:"answer_success,omitempty"`
+}
+
+// Outcome is the end state of a run that checks are evaluated against.
+type Outcome struct {
+	URL  string
+	Page string
+	// Answer is the final answer text followed by the extracted data, if
+	// any.
+	Answer        string
+	AnswerSuccess llm.FinishStatus
+}
+
+// checkField is a condition of a Check by its JSON name.
+type checkField struct {
+	name, value string
+}
+
+// fields returns the conditions of the check in declaration order.
+func (c Check) fields() []checkField {
+	return []checkField{
+		{"url_contains", c.URLContains},
+		{"url_matches", c.URLMatches},
+		{"page_contains", c.PageContains},
+		{"page_not_contains", c.PageNotContains},
+		{"answer_contains", c.AnswerContains},
+		{"answer_matches", c.AnswerMatches},
+		{"answer_success", string(c.AnswerSuccess)},
+	}
+}
+
+func (c Check) validate() error {
+	var set []string
+	for _, f := range c.fields() {
+		if f.value != "" {
+			set = append(set, f.name)
+		}
+	}
+	if len(set) != 1 {
+		return fmt.Errorf("check must set exactly one condition, got %d", len(set))
+	}
+	for _, pattern := range []string{c.URLMatches, c.AnswerMatches} {
+		if pattern == "" {
+			continue
+		}
+		if _, err := regexp.Compile(pattern); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c Check) String() string {
+	var parts []string
+	for _, f := range c.fields() {
+		if f.value != "" {
+			parts = append(parts, fmt.Sprintf("%s %q", f.name, f.value))
+		}
+	}
+	if len(parts) == 0 {
+		return "empty check"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Eval returns an empty string when the check passes and the reason
+// otherwise. A pattern that does not compile fails the check; Validate
+// reports it before a run.
+func (c Check) Eval(o Outcome) string {
+	switch {
+	case c.URLContains != "":
+		if !strings.Contains(o.URL, c.URLContains) {
+			return fmt.Sprintf("final URL %s does not contain %q", o.URL, c.URLContains)
+		}
+	case c.URLMatches != "":
+		re, err := regexp.Compile(c.URLMatches)
+		if err != nil {
+			return fmt.Sprintf("url_matches: %v", err)
+		}
+		if !re.MatchString(o.URL) {
+			return fmt.Sprintf("final URL %s does not match %q", o.URL, c.URLMatches)
+		}
+	case c.PageContains != "":
+		if !strings.Contains(o.Page, c.PageContains) {
+			return fmt.Sprintf("final page does not contain %q", c.PageContains)
+		}
+	case c.PageNotContains != "":
+		if strings.Contains(o.Page, c.PageNotContains) {
+			return fmt.Sprintf("final page contains %q", c.PageNotContains)
+		}
+	case c.AnswerContains != "":
+		if !strings.Contains(strings.ToLower(o.Answer), strings.ToLower(c.AnswerContains)) {
+			return fmt.Sprintf("answer %q does not contain %q", o.Answer, c.AnswerContains)
+		}
+	case c.AnswerMatches != "":
+		re, err := regexp.Compile(c.AnswerMatches)
+		if err != nil {
+			return fmt.Sprintf("answer_matches: %v", err)
+		}
+		if !re.MatchString(o.Answer) {
+			return fmt.Sprintf("answer %q does not match %q", o.Answer, c.AnswerMatches)
+		}
+	case c.AnswerSuccess != "":
+		if o.AnswerSuccess != c.AnswerSuccess {
+			return fmt.Sprintf("answer success is %q, want %q", o.AnswerSuccess, c.AnswerSuccess)
+		}
+	}
+	return ""
+}
+
+// LoadSuite reads and validates a suite.
+func LoadSuite(path string) (*Suite, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var s Suite
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return nil, fmt.Errorf("suite %s: %w", path, err)
+	}
+	if err := s.Validate(); err != nil {
+		return nil, fmt.Errorf("suite %s: %w", path, err)
+	}
+	return &s, nil
+}
+
+func (s *Suite) Validate() error {
+	if len(s.Tasks) == 0 {
+		return errors.New("no tasks")
+	}
+
+	seen := make(map[string]bool)
+	for i, t := range s.Tasks {
+		if t.Name == "" {
+			return fmt.Errorf("task %d: missing name", i)
+		}
+		if seen[t.Name] {
+			return fmt.Errorf("task %q: duplicate name", t.Name)
+		}
+		seen[t.Name] = true
+
+		if t.URL == "" || t.Task == "" {
+			return fmt.Errorf("task %q: url and task are required", t.Name)
+		}
+		if len(t.Checks) == 0 {
+			return fmt.Errorf("task %q: no checks", t.Name)
+		}
+		for j, c := range t.Checks {
+			if err := c.validate(); err != nil {
+				return fmt.Errorf("task %q: check %d: %w", t.Name, j, err)
+			}
+		}
+		if len(t.Schema) > 0 {
+			if err := llm.ValidateSchema(t.Schema); err != nil {
+				return fmt.Errorf("task %q: %w", t.Name, err)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package fixture
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"testing"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/browser"
+)
+
+// ChromePath returns the Chrome binary used by browser tests: CHROME_PATH,
+// or the first well-known binary on PATH.
+func ChromePath() string {
+	if path := os.Getenv("CHROME_PATH"); path != "" {
+		return path
+	}
+	for _, name := range []string{"google-chrome", "google-chrome-stable", "chromium", "chromium-browser", "chrome"} {
+		if path, err := exec.LookPath(name); err == nil {
+			return path
+		}
+	}
+	return ""
+}
+
+// NewBrowser starts a headless Chrome that is closed when the test ends.
+// The test is skipped when no Chrome is found.
+func NewBrowser(t testing.TB) *browser.Manager {
+	t.Helper()
+
+	path := ChromePath()
+	if path == "" {
+		t.Skip("Chrome not found; set CHROME_PATH to run browser tests")
+	}
+
+	b, err := browser.NewManager(browser.Options{
+		Headless:  browser.Headless,
+		ExecPath:  path,
+		Ephemeral: true,
+		NoSandbox: true,
+	})
+	if err != nil {
+		t.Fatalf("start browser: %v", err)
+	}
+	t.Cleanup(b.Close)
+	return b
+}
+
+// NewPlaywright starts a headless Playwright browser that is closed when
+// the test ends; PLAYWRIGHT_ENGINE selects chromium (default), firefox or
+// webkit. The test is skipped when Playwright is not installed.
+func NewPlaywright(t testing.TB) *browser.PlaywrightDriver {
+	t.Helper()
+
+	d, err := browser.NewPlaywright(browser.Options{
+		Backend:  browser.BackendPlaywright,
+		Engine:   os.Getenv("PLAYWRIGHT_ENGINE"),
+		Headless: browser.Headless,
+	})
+	if errors.Is(err, browser.ErrNoPlaywright) {
+		t.Skip(err)
+	}
+	if err != nil {
+		t.Fatalf("start playwright browser: %v", err)
+	}
+	t.Cleanup(d.Close)
+	return d
+}
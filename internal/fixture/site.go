@@ -0,0 +1,233 @@
+// Package fixture serves an offline website for end-to-end tests of the
+// browser and the agent: login, a multi-step form, a modal, a shop with
+// cart and checkout, an infinite list, an iframe and a new-tab link.
+package fixture
+
+import (
+	"embed"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	Username = "demo"
+	Password = "secret"
+)
+
+// Product is an item sold in the fixture shop.
+type Product struct {
+	Name  string
+	Price int
+}
+
+var Products = []Product{
+	{"Margherita pizza", 9},
+	{"Pepperoni pizza", 11},
+	{"Cola", 2},
+}
+
+// Payment is an order paid on the checkout page.
+type Payment struct {
+	Items []string
+	Total int
+}
+
+//go:embed pages/*.html
+var pageFiles embed.FS
+
+var pages = template.Must(template.ParseFS(pageFiles, "pages/*.html"))
+
+// Site is a running fixture website.
+type Site struct {
+	*httptest.Server
+
+	mu          sync.Mutex
+	payments    []Payment
+	submissions []url.Values
+}
+
+// NewSite starts the site; call Close when done.
+func NewSite() *Site {
+	s := &Site{}
+	s.Server = httptest.NewServer(s.routes())
+	return s
+}
+
+// URL returns the absolute URL of path.
+func (s *Site) URL(path string) string {
+	return s.Server.URL + path
+}
+
+// Payments returns the orders paid so far.
+func (s *Site) Payments() []Payment {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Payment(nil), s.payments...)
+}
+
+// Submissions returns the completed multi-step forms.
+func (s *Site) Submissions() []url.Values {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]url.Values(nil), s.submissions...)
+}
+
+func (s *Site) routes() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.page("index", nil))
+	mux.HandleFunc("GET /login", s.page("login", nil))
+	mux.HandleFunc("POST /login", s.login)
+	mux.HandleFunc("GET /account", s.account)
+	mux.HandleFunc("GET /form/1", s.page("form1", nil))
+	mux.HandleFunc("GET /form/2", s.formStep("form2"))
+	mux.HandleFunc("GET /form/3", s.formStep("form3"))
+	mux.HandleFunc("POST /form/done", s.formDone)
+	mux.HandleFunc("GET /modal", s.page("modal", nil))
+	mux.HandleFunc("GET /shop", s.shop)
+	mux.HandleFunc("POST /cart/add", s.addToCart)
+	mux.HandleFunc("GET /cart", s.cartPage("cart"))
+	mux.HandleFunc("GET /checkout", s.cartPage("checkout"))
+	mux.HandleFunc("POST /pay", s.pay)
+	mux.HandleFunc("GET /feed", s.page("feed", nil))
+	mux.HandleFunc("GET /frame", s.page("frame", nil))
+	mux.HandleFunc("GET /frame/inner", s.page("frame_inner", nil))
+	mux.HandleFunc("GET /tabs", s.page("tabs", nil))
+	mux.HandleFunc("GET /help", s.page("help", nil))
+	return mux
+}
+
+func render(w http.ResponseWriter, name string, data any) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := pages.ExecuteTemplate(w, name+".html", data); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *Site) page(name string, data any) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		render(w, name, data)
+	}
+}
+
+func (s *Site) login(w http.ResponseWriter, r *http.Request) {
+	if r.FormValue("username") != Username || r.FormValue("password") != Password {
+		w.WriteHeader(http.StatusUnauthorized)
+		render(w, "login", map[string]string{"Error": "Invalid username or password"})
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: "session", Value: Username, Path: "/"})
+	http.Redirect(w, r, "/account", http.StatusSeeOther)
+}
+
+func (s *Site) account(w http.ResponseWriter, r *http.Request) {
+	c, err := r.Cookie("session")
+	if err != nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+	render(w, "account", map[string]string{"User": c.Value})
+}
+
+func (s *Site) formStep(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		render(w, name, map[string]string{
+			"Name":  r.FormValue("name"),
+			"Email": r.FormValue("email"),
+			"Plan":  r.FormValue("plan"),
+		})
+	}
+}
+
+func (s *Site) formDone(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.mu.Lock()
+	s.submissions = append(s.submissions, r.PostForm)
+	s.mu.Unlock()
+	render(w, "form_done", map[string]string{"Name": r.PostForm.Get("name")})
+}
+
+func (s *Site) shop(w http.ResponseWriter, r *http.Request) {
+	render(w, "shop", map[string]any{
+		"Products": Products,
+		"Added":    r.FormValue("added"),
+		"Count":    len(cartItems(r)),
+	})
+}
+
+func cartItems(r *http.Request) []string {
+	c, err := r.Cookie("cart")
+	if err != nil || c.Value == "" {
+		return nil
+	}
+	raw, err := url.QueryUnescape(c.Value)
+	if err != nil {
+		return nil
+	}
+	return strings.Split(raw, "|")
+}
+
+func setCart(w http.ResponseWriter, items []string) {
+	http.SetCookie(w, &http.Cookie{
+		Name:  "cart",
+		Value: url.QueryEscape(strings.Join(items, "|")),
+		Path:  "/",
+	})
+}
+
+func findProduct(name string) (Product, bool) {
+	for _, p := range Products {
+		if p.Name == name {
+			return p, true
+		}
+	}
+	return Product{}, false
+}
+
+func (s *Site) addToCart(w http.ResponseWriter, r *http.Request) {
+	item := r.FormValue("item")
+	if _, ok := findProduct(item); !ok {
+		http.Error(w, fmt.Sprintf("unknown product %q", item), http.StatusBadRequest)
+		return
+	}
+	setCart(w, append(cartItems(r), item))
+	http.Redirect(w, r, "/shop?added="+url.QueryEscape(item), http.StatusSeeOther)
+}
+
+func cartTotal(items []string) int {
+	total := 0
+	for _, item := range items {
+		p, _ := findProduct(item)
+		total += p.Price
+	}
+	return total
+}
+
+func (s *Site) cartPage(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		items := cartItems(r)
+		render(w, name, map[string]any{"Items": items, "Total": cartTotal(items)})
+	}
+}
+
+func (s *Site) pay(w http.ResponseWriter, r *http.Request) {
+	items := cartItems(r)
+	if len(items) == 0 {
+		http.Redirect(w, r, "/cart", http.StatusSeeOther)
+		return
+	}
+
+	s.mu.Lock()
+	s.payments = append(s.payments, Payment{Items: items, Total: cartTotal(items)})
+	s.mu.Unlock()
+
+	setCart(w, nil)
+	render(w, "paid", map[string]any{"Total": cartTotal(items)})
+}
@@ -0,0 +1,292 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	anthropicBaseURL = "https://api.anthropic.com"
+	anthropicVersion = "2023-06-01"
+)
+
+type AnthropicClient struct {
+	http         *http.Client
+	baseURL      string
+	apiKey       string
+	defaultModel string
+	retry        RetryPolicy
+}
+
+func newAnthropicClient(cfg ProviderConfig, apiKey string) *AnthropicClient {
+	baseURL := cfg.BaseURL
+	if baseURL == "" {
+		baseURL = anthropicBaseURL
+	}
+	return &AnthropicClient{
+		http:         cfg.httpClient(),
+		baseURL:      strings.TrimRight(baseURL, "/"),
+		apiKey:       apiKey,
+		defaultModel: cfg.Model,
+		retry:        cfg.Retry,
+	}
+}
+
+type anthropicImageSource struct {
+	Type      string `json:"type"`
+	MediaType string `json:"media_type"`
+	Data      string `json:"data"`
+}
+
+type anthropicContent struct {
+	Type   string                `json:"type"`
+	Text   string                `json:"text,omitempty"`
+	Source *anthropicImageSource `json:"source,omitempty"`
+
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+
+	ToolUseID string `json:"tool_use_id,omitempty"`
+	Content   string `json:"content,omitempty"`
+	IsError   bool   `json:"is_error,omitempty"`
+}
+
+type anthropicTool struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description,omitempty"`
+	InputSchema json.RawMessage `json:"input_schema"`
+}
+
+type anthropicToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+type anthropicMessage struct {
+	Role    string             `json:"role"`
+	Content []anthropicContent `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model       string             `json:"model"`
+	System      string             `json:"system,omitempty"`
+	Messages    []anthropicMessage `json:"messages"`
+	MaxTokens   int                `json:"max_tokens"`
+	Temperature float32            `json:"temperature"`
+
+	Tools      []anthropicTool      `json:"tools,omitempty"`
+	ToolChoice *anthropicToolChoice `json:"tool_choice,omitempty"`
+}
+
+type anthropicResponse struct {
+	Model   string             `json:"model"`
+	Content []anthropicContent `json:"content"`
+	Usage   struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+type anthropicErrorResponse struct {
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+func (c *AnthropicClient) DecideAction(ctx context.Context, input DecisionInput) (*DecisionOutput, error) {
+	prompt := buildDecisionPrompt(input)
+
+	content := []anthropicContent{{Type: "text", Text: prompt.text}}
+	if prompt.image != "" {
+		content = append(content, anthropicContent{
+			Type: "image",
+			Source: &anthropicImageSource{
+				Type:      "base64",
+				MediaType: "image/jpeg",
+				Data:      prompt.image,
+			},
+		})
+	}
+
+	schema, _ := decisionSchema(input.OutputSchema)
+	req := anthropicRequest{
+		Model:       c.model(input.Params),
+		System:      visionSystemPrompt + "\nAlways answer by calling the " + decisionSchemaName + " tool.",
+		Messages:    []anthropicMessage{{Role: "user", Content: content}},
+		MaxTokens:   prompt.maxTokens,
+		Temperature: input.Params.Temperature,
+		Tools: []anthropicTool{{
+			Name:        decisionSchemaName,
+			Description: "Report the observation, thought and the next browser action.",
+			InputSchema: schema,
+		}},
+		ToolChoice: &anthropicToolChoice{Type: "tool", Name: decisionSchemaName},
+	}
+
+	imageTokens := 0
+	if prompt.image != "" {
+		imageTokens = anthropicImageTokens(prompt.image)
+	}
+
+	var lastToolUse string
+	return decideWithRepair(ctx, input, func(ctx context.Context, feedback string) (string, Usage, error) {
+		if feedback != "" {
+			reply := anthropicContent{Type: "text", Text: feedback}
+			if lastToolUse != "" {
+				reply = anthropicContent{Type: "tool_result", ToolUseID: lastToolUse, Content: feedback, IsError: true}
+			}
+			req.Messages = append(req.Messages, anthropicMessage{Role: "user", Content: []anthropicContent{reply}})
+		}
+
+		resp, err := c.send(ctx, req)
+		if err != nil {
+			return "", Usage{}, err
+		}
+		req.Messages = append(req.Messages, anthropicMessage{Role: "assistant", Content: resp.Content})
+
+		usage := resp.usage(req.Model)
+		usage.ImageTokens = imageTokens
+
+		lastToolUse = ""
+		for _, part := range resp.Content {
+			if part.Type == "tool_use" && part.Name == decisionSchemaName {
+				lastToolUse = part.ID
+				return string(part.Input), usage, nil
+			}
+		}
+		text, err := resp.text()
+		return text, usage, err
+	})
+}
+
+func (c *AnthropicClient) SummarizeRun(ctx context.Context, input SummaryInput) (*SummaryOutput, error) {
+	model := c.model(ModelParams{Model: input.Model})
+	resp, err := c.send(ctx, anthropicRequest{
+		Model:  model,
+		System: summarySystemPrompt,
+		Messages: []anthropicMessage{{
+			Role:    "user",
+			Content: []anthropicContent{{Type: "text", Text: buildSummaryPrompt(input)}},
+		}},
+		MaxTokens:   defaultSummaryMaxTokens,
+		Temperature: defaultSummaryTemperature,
+	})
+	if err != nil {
+		return nil, err
+	}
+	text, err := resp.text()
+	if err != nil {
+		return nil, err
+	}
+	return &SummaryOutput{Text: text, Usage: resp.usage(model)}, nil
+}
+
+func (c *AnthropicClient) send(ctx context.Context, body anthropicRequest) (*anthropicResponse, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, err
+	}
+
+	var out *anthropicResponse
+	err = c.retry.Do(ctx, func(ctx context.Context) error {
+		var err error
+		out, err = c.post(ctx, payload)
+		return err
+	})
+	return out, err
+}
+
+func (c *AnthropicClient) post(ctx context.Context, payload []byte) (*anthropicResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/v1/messages", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("content-type", "application/json")
+	req.Header.Set("anthropic-version", anthropicVersion)
+	if c.apiKey != "" {
+		req.Header.Set("x-api-key", c.apiKey)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		return nil, &APIError{Provider: ProviderAnthropic, Kind: ErrorTransient, Err: err}
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode/100 != 2 {
+		return nil, anthropicError(resp, raw)
+	}
+
+	var out anthropicResponse
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("anthropic: decode response: %w", err)
+	}
+	return &out, nil
+}
+
+func anthropicError(resp *http.Response, raw []byte) *APIError {
+	apiErr := &APIError{
+		Provider:   ProviderAnthropic,
+		StatusCode: resp.StatusCode,
+		Kind:       kindForStatus(resp.StatusCode),
+		Message:    strings.TrimSpace(string(raw)),
+		RetryAfter: parseRetryAfter(resp.Header),
+	}
+
+	var body anthropicErrorResponse
+	if json.Unmarshal(raw, &body) == nil && body.Error.Message != "" {
+		apiErr.Message = body.Error.Type + ": " + body.Error.Message
+		if strings.Contains(strings.ToLower(body.Error.Message), "credit balance") {
+			apiErr.Kind = ErrorQuota
+		}
+	}
+	return apiErr
+}
+
+func (r *anthropicResponse) usage(requested string) Usage {
+	model := r.Model
+	if model == "" {
+		model = requested
+	}
+	return Usage{
+		Model:            model,
+		Calls:            1,
+		PromptTokens:     r.Usage.InputTokens,
+		CompletionTokens: r.Usage.OutputTokens,
+	}
+}
+
+func (r *anthropicResponse) text() (string, error) {
+	var sb strings.Builder
+	for _, part := range r.Content {
+		if part.Type == "text" {
+			sb.WriteString(part.Text)
+		}
+	}
+	if sb.Len() == 0 {
+		return "", fmt.Errorf("anthropic: empty response")
+	}
+	return sb.String(), nil
+}
+
+func (c *AnthropicClient) model(p ModelParams) string {
+	if p.Model != "" {
+		return p.Model
+	}
+	return c.defaultModel
+}
@@ -0,0 +1,213 @@
+package llm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const cassetteVersion = 1
+
+type interactionKind string
+
+const (
+	kindDecide    interactionKind = "decide"
+	kindSummarize interactionKind = "summarize"
+)
+
+// Cassette is a recorded sequence of LLM calls, stored as JSON.
+type Cassette struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Interaction is one recorded call. Request holds the fields the
+// fingerprint is computed from, so that mismatches can be explained.
+type Interaction struct {
+	Kind        interactionKind   `json:"kind"`
+	Fingerprint string            `json:"fingerprint"`
+	Request     map[string]string `json:"request"`
+	Decision    *DecisionOutput   `json:"decision,omitempty"`
+	Summary     string            `json:"summary,omitempty"`
+	Usage       Usage             `json:"usage"`
+	Error       *recordedError    `json:"error,omitempty"`
+}
+
+// recordedError is a failed call. A *DecisionError is stored with its
+// attempts and last answer, and Message is then the error it wraps.
+type recordedError struct {
+	Message    string    `json:"message"`
+	Kind       ErrorKind `json:"kind,omitempty"`
+	StatusCode int       `json:"status_code,omitempty"`
+	Attempts   int       `json:"attempts,omitempty"`
+	Raw        string    `json:"raw,omitempty"`
+}
+
+func newRecordedError(err error) *recordedError {
+	rec := &recordedError{Message: err.Error()}
+	var derr *DecisionError
+	if errors.As(err, &derr) {
+		rec.Message, rec.Attempts, rec.Raw = derr.Err.Error(), derr.Attempts, derr.Raw
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		rec.Kind = apiErr.Kind
+		rec.StatusCode = apiErr.StatusCode
+	}
+	return rec
+}
+
+// err rebuilds the recorded error; usage is the recorded usage of the call,
+// which a *DecisionError carries.
+func (e *recordedError) err(usage Usage) error {
+	var err error
+	if e.Kind != "" {
+		err = &APIError{Provider: "replay", StatusCode: e.StatusCode, Kind: e.Kind, Message: e.Message}
+	} else {
+		err = errors.New(e.Message)
+	}
+	if e.Attempts > 0 {
+		return &DecisionError{Attempts: e.Attempts, Raw: e.Raw, Err: err, Usage: usage}
+	}
+	return err
+}
+
+// decisionRequest returns the fingerprinted fields of a decision. The
+// screenshot is reduced to its presence: its bytes differ between runs of
+// the same page.
+func decisionRequest(in DecisionInput) map[string]string {
+	ids, _ := json.Marshal(in.TargetIDs)
+	return map[string]string{
+		"task":          in.Task,
+		"url":           in.CurrentURL,
+		"dom":           in.DOMTree,
+		"history":       in.History,
+		"output_schema": in.OutputSchema,
+		"target_ids":    string(ids),
+		"screenshot":    fmt.Sprint(in.ScreenshotBase64 != ""),
+	}
+}
+
+// summaryRequest returns the fingerprinted fields of a summary. Duration
+// and model are left out.
+func summaryRequest(in SummaryInput) map[string]string {
+	steps, _ := json.Marshal(in.Steps)
+	return map[string]string{
+		"task":        in.Task,
+		"exit_reason": in.ExitReason,
+		"final_url":   in.FinalURL,
+		"steps":       string(steps),
+	}
+}
+
+func fingerprint(kind interactionKind, req map[string]string) string {
+	// json.Marshal sorts map keys, so the encoding is canonical.
+	raw, _ := json.Marshal(struct {
+		Kind    interactionKind   `json:"kind"`
+		Request map[string]string `json:"request"`
+	}{kind, req})
+	sum := sha256.Sum256(raw)
+	return hex.EncodeToString(sum[:])
+}
+
+// LoadCassette reads a cassette written by Recorder.Save.
+func LoadCassette(path string) (*Cassette, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c Cassette
+	if err := json.Unmarshal(raw, &c); err != nil {
+		return nil, fmt.Errorf("cassette %s: %w", path, err)
+	}
+	if c.Version != cassetteVersion {
+		return nil, fmt.Errorf("cassette %s: unsupported version %d", path, c.Version)
+	}
+	return &c, nil
+}
+
+// Save writes the cassette to path, creating parent directories.
+func (c *Cassette) Save(path string) error {
+	raw, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, raw, 0o644)
+}
+
+// Recorder is a Client that forwards calls to another Client and records
+// them into a cassette.
+type Recorder struct {
+	inner Client
+	path  string
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder wraps inner; call Save to write the cassette to path.
+func NewRecorder(inner Client, path string) *Recorder {
+	return &Recorder{
+		inner:    inner,
+		path:     path,
+		cassette: Cassette{Version: cassetteVersion},
+	}
+}
+
+func (r *Recorder) DecideAction(ctx context.Context, input DecisionInput) (*DecisionOutput, error) {
+	out, err := r.inner.DecideAction(ctx, input)
+
+	req := decisionRequest(input)
+	it := Interaction{Kind: kindDecide, Fingerprint: fingerprint(kindDecide, req), Request: req}
+	if err != nil {
+		it.Error = newRecordedError(err)
+		var derr *DecisionError
+		if errors.As(err, &derr) {
+			it.Usage = derr.Usage
+		}
+	} else {
+		it.Decision = out
+		it.Usage = out.Usage
+	}
+	r.record(it)
+
+	return out, err
+}
+
+func (r *Recorder) SummarizeRun(ctx context.Context, input SummaryInput) (*SummaryOutput, error) {
+	out, err := r.inner.SummarizeRun(ctx, input)
+
+	req := summaryRequest(input)
+	it := Interaction{Kind: kindSummarize, Fingerprint: fingerprint(kindSummarize, req), Request: req}
+	if err != nil {
+		it.Error = newRecordedError(err)
+	} else {
+		it.Summary = out.Text
+		it.Usage = out.Usage
+	}
+	r.record(it)
+
+	return out, err
+}
+
+func (r *Recorder) record(it Interaction) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.cassette.Interactions = append(r.cassette.Interactions, it)
+}
+
+// Save writes everything recorded so far.
+func (r *Recorder) Save() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.cassette.Save(r.path)
+}
@@ -0,0 +1,139 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// echoClient clicks the first target and fails when the task says so.
+type echoClient struct{}
+
+func (echoClient) DecideAction(_ context.Context, in DecisionInput) (*DecisionOutput, error) {
+	switch in.Task {
+	case "fail":
+		return nil, &APIError{Provider: ProviderOpenAI, StatusCode: 401, Kind: ErrorAuth, Message: "bad key"}
+	case "invalid":
+		return nil, &DecisionError{Attempts: 3, Raw: `{"action":{"type":"hover"}}`, Err: errors.New("unknown action type"), Usage: Usage{Model: "m", Calls: 3, PromptTokens: 300}}
+	}
+	return &DecisionOutput{
+		Thought: "click " + in.CurrentURL,
+		Action:  Action{Type: ActionClick, TargetID: in.TargetIDs[0]},
+		Usage:   Usage{Model: "m", Calls: 1, PromptTokens: 10},
+	}, nil
+}
+
+func (echoClient) SummarizeRun(_ context.Context, in SummaryInput) (*SummaryOutput, error) {
+	return &SummaryOutput{Text: "summary of " + in.Task}, nil
+}
+
+func recordCassette(t *testing.T) string {
+	t.Helper()
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "run", "cassette.json")
+
+	rec := NewRecorder(echoClient{}, path)
+	if _, err := rec.DecideAction(ctx, DecisionInput{Task: "t", CurrentURL: "/a", DOMTree: "[1] [button] Buy", TargetIDs: []int{1}, ScreenshotBase64: "AAAA"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := rec.DecideAction(ctx, DecisionInput{Task: "fail"}); err == nil {
+		t.Fatal("expected recorded error")
+	}
+	if _, err := rec.SummarizeRun(ctx, SummaryInput{Task: "t", Duration: "1s"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRecordReplay(t *testing.T) {
+	ctx := context.Background()
+	replay, err := NewReplayerFromFile(recordCassette(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A different screenshot and summary duration still match.
+	out, err := replay.DecideAction(ctx, DecisionInput{Task: "t", CurrentURL: "/a", DOMTree: "[1] [button] Buy", TargetIDs: []int{1}, ScreenshotBase64: "BBBB"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Action.TargetID != 1 || out.Thought != "click /a" || out.Usage.PromptTokens != 10 {
+		t.Errorf("unexpected replayed decision: %+v", out)
+	}
+
+	_, err = replay.DecideAction(ctx, DecisionInput{Task: "fail"})
+	if !IsFatal(err) {
+		t.Errorf("replayed error lost its kind: %v", err)
+	}
+
+	sum, err := replay.SummarizeRun(ctx, SummaryInput{Task: "t", Duration: "7s"})
+	if err != nil || sum.Text != "summary of t" {
+		t.Errorf("summary = %+v, %v", sum, err)
+	}
+
+	if replay.Remaining() != 0 {
+		t.Errorf("remaining = %d", replay.Remaining())
+	}
+	if _, err := replay.SummarizeRun(ctx, SummaryInput{}); !errors.Is(err, ErrCassetteExhausted) {
+		t.Errorf("err = %v, want ErrCassetteExhausted", err)
+	}
+}
+
+func TestReplayKeepsDecisionErrorUsage(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	rec := NewRecorder(echoClient{}, path)
+	if _, err := rec.DecideAction(ctx, DecisionInput{Task: "invalid"}); err == nil {
+		t.Fatal("expected recorded error")
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatal(err)
+	}
+
+	replay, err := NewReplayerFromFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = replay.DecideAction(ctx, DecisionInput{Task: "invalid"})
+	var derr *DecisionError
+	if !errors.As(err, &derr) || !errors.Is(err, ErrInvalidDecision) {
+		t.Fatalf("err = %v, want a DecisionError", err)
+	}
+	want := Usage{Model: "m", Calls: 3, PromptTokens: 300}
+	if derr.Usage != want || derr.Attempts != 3 || !strings.Contains(derr.Raw, "hover") {
+		t.Errorf("replayed error = %+v, want usage %+v", derr, want)
+	}
+	if err.Error() != "invalid decision after 3 attempts: unknown action type" {
+		t.Errorf("message = %q", err.Error())
+	}
+}
+
+func TestReplayMismatch(t *testing.T) {
+	ctx := context.Background()
+	replay, err := NewReplayerFromFile(recordCassette(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = replay.DecideAction(ctx, DecisionInput{Task: "t", CurrentURL: "/a", DOMTree: "[1] [button] Sell", TargetIDs: []int{1}, ScreenshotBase64: "AAAA"})
+	var mm *MismatchError
+	if !errors.As(err, &mm) {
+		t.Fatalf("err = %v, want MismatchError", err)
+	}
+	if mm.Index != 0 || mm.Field != "dom" || mm.Offset != 13 {
+		t.Errorf("unexpected mismatch: %+v", mm)
+	}
+	if !strings.Contains(err.Error(), "Buy") || !strings.Contains(err.Error(), "Sell") {
+		t.Errorf("mismatch message does not show both values: %v", err)
+	}
+
+	_, err = replay.SummarizeRun(ctx, SummaryInput{Task: "t"})
+	if !errors.As(err, &mm) || mm.Field != "kind" {
+		t.Errorf("err = %v, want kind mismatch", err)
+	}
+}
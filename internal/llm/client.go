@@ -8,7 +8,11 @@ import (
 )
 
 type OpenAIClient struct {
-	client *openai.Client
+	client       *openai.Client
+	provider     Provider
+	defaultModel string
+	plainJSON    bool
+	retry        RetryPolicy
 }
 
 func NewOpenAIClient() (*OpenAIClient, error) {
@@ -16,7 +20,38 @@ func NewOpenAIClient() (*OpenAIClient, error) {
 	if apiKey == "" {
 		return nil, fmt.Errorf("OPENAI_API_KEY not set")
 	}
+	return newOpenAICompatibleClient(ProviderConfig{}, apiKey), nil
+}
+
+func newOpenAICompatibleClient(cfg ProviderConfig, apiKey string) *OpenAIClient {
+	oc := openai.DefaultConfig(apiKey)
+	if cfg.BaseURL != "" {
+		oc.BaseURL = cfg.BaseURL
+	}
+	oc.HTTPClient = cfg.httpClient()
+
+	provider := cfg.Provider
+	if provider == "" {
+		provider = ProviderOpenAI
+	}
+
+	model := cfg.Model
+	if model == "" {
+		model = DefaultModel
+	}
+
 	return &OpenAIClient{
-		client: openai.NewClient(apiKey),
-	}, nil
+		client:       openai.NewClientWithConfig(oc),
+		provider:     provider,
+		defaultModel: model,
+		plainJSON:    cfg.PlainJSON,
+		retry:        cfg.Retry,
+	}
+}
+
+func (c *OpenAIClient) model(p ModelParams) string {
+	if p.Model != "" {
+		return p.Model
+	}
+	return c.defaultModel
 }
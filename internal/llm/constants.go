@@ -1,3 +1,12 @@
 package llm
 
 const safeDOMLimit = 20000
+
+const (
+	DefaultModel = "gpt-4o"
+
+	defaultDecisionMaxTokens   = 500
+	defaultExtractionMaxTokens = 1500
+	defaultSummaryMaxTokens    = 600
+	defaultSummaryTemperature  = 0.2
+)
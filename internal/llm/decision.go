@@ -5,14 +5,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
-	"time"
 
 	openai "github.com/sashabaranov/go-openai"
 )
 
-func (c *OpenAIClient) DecideAction(input DecisionInput) (*DecisionOutput, error) {
-	ctx := context.Background()
+type decisionPrompt struct {
+	text      string
+	image     string
+	maxTokens int
+}
 
+func buildDecisionPrompt(input DecisionInput) decisionPrompt {
 	var sb strings.Builder
 	sb.WriteString("TASK: " + input.Task + "\n")
 	sb.WriteString("URL: " + input.CurrentURL + "\n")
@@ -27,73 +30,192 @@ func (c *OpenAIClient) DecideAction(input DecisionInput) (*DecisionOutput, error
 	}
 	sb.WriteString("\nDOM:\n" + dom)
 
+	maxTokens := defaultDecisionMaxTokens
+	if input.OutputSchema != "" {
+		sb.WriteString("\n" + extractionPrompt)
+		sb.WriteString("OUTPUT SCHEMA:\n" + input.OutputSchema + "\n")
+		maxTokens = defaultExtractionMaxTokens
+	}
+	if input.Params.MaxTokens > 0 {
+		maxTokens = input.Params.MaxTokens
+	}
+
+	return decisionPrompt{
+		text:      sb.String(),
+		image:     input.ScreenshotBase64,
+		maxTokens: maxTokens,
+	}
+}
+
+func parseDecision(content string) (*DecisionOutput, error) {
+	content = strings.TrimSpace(strings.Trim(content, "`"))
+	content = strings.TrimPrefix(content, "json")
+	if start, end := strings.Index(content, "{"), strings.LastIndex(content, "}"); start >= 0 && end > start {
+		content = content[start : end+1]
+	}
+
+	var out DecisionOutput
+	if err := json.Unmarshal([]byte(content), &out); err != nil {
+		return nil, fmt.Errorf("response is not a valid JSON decision: %w", err)
+	}
+
+	normalizeActionType(&out.Action)
+	return &out, nil
+}
+
+// decideWithRepair asks for a decision through turn, validates it and,
+// when parsing or validation fails, asks again with the error as feedback.
+// turn is called with an empty feedback on the first attempt; providers keep
+// the conversation so the model sees its previous answer.
+func decideWithRepair(
+	ctx context.Context,
+	input DecisionInput,
+	turn func(ctx context.Context, feedback string) (string, Usage, error),
+) (*DecisionOutput, error) {
+	var (
+		raw      string
+		feedback string
+		lastErr  error
+		total    Usage
+	)
+
+	for attempt := 0; attempt <= maxDecisionRepairs; attempt++ {
+		answer, usage, err := turn(ctx, feedback)
+		total = total.Add(usage)
+		if err != nil {
+			if attempt == 0 {
+				return nil, err
+			}
+			// The rejected answers were paid for; keep their usage.
+			return nil, &DecisionError{Attempts: attempt + 1, Raw: raw, Err: err, Usage: total}
+		}
+		raw = answer
+
+		out, err := parseDecision(raw)
+		if err == nil {
+			err = ValidateDecision(out, input)
+		}
+		if err == nil {
+			out.Usage = total
+			return out, nil
+		}
+
+		lastErr = err
+		feedback = repairPrompt(err)
+	}
+
+	return nil, &DecisionError{Attempts: maxDecisionRepairs + 1, Raw: raw, Err: lastErr, Usage: total}
+}
+
+func (c *OpenAIClient) DecideAction(ctx context.Context, input DecisionInput) (*DecisionOutput, error) {
+	prompt := buildDecisionPrompt(input)
+
 	parts := []openai.ChatMessagePart{
-		{Type: openai.ChatMessagePartTypeText, Text: sb.String()},
+		{Type: openai.ChatMessagePartTypeText, Text: prompt.text},
 	}
 
-	if input.ScreenshotBase64 != "" {
+	if prompt.image != "" {
 		parts = append(parts, openai.ChatMessagePart{
 			Type: openai.ChatMessagePartTypeImageURL,
 			ImageURL: &openai.ChatMessageImageURL{
-				URL: "data:image/jpeg;base64," + input.ScreenshotBase64,
+				URL: "data:image/jpeg;base64," + prompt.image,
 			},
 		})
 	}
 
-	var resp openai.ChatCompletionResponse
-	var err error
-
-	for attempt := 0; attempt < 5; attempt++ {
-		resp, err = c.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-			Model: "gpt-4o",
-			Messages: []openai.ChatCompletionMessage{
-				{Role: openai.ChatMessageRoleSystem, Content: visionSystemPrompt},
-				{Role: openai.ChatMessageRoleUser, MultiContent: parts},
-			},
-			ResponseFormat: &openai.ChatCompletionResponseFormat{
-				Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+	messages := []openai.ChatCompletionMessage{
+		{Role: openai.ChatMessageRoleSystem, Content: visionSystemPrompt},
+		{Role: openai.ChatMessageRoleUser, MultiContent: parts},
+	}
+
+	format := &openai.ChatCompletionResponseFormat{
+		Type: openai.ChatCompletionResponseFormatTypeJSONObject,
+	}
+	if !c.plainJSON {
+		schema, strict := decisionSchema(input.OutputSchema)
+		format = &openai.ChatCompletionResponseFormat{
+			Type: openai.ChatCompletionResponseFormatTypeJSONSchema,
+			JSONSchema: &openai.ChatCompletionResponseFormatJSONSchema{
+				Name:   decisionSchemaName,
+				Schema: schema,
+				Strict: strict,
 			},
-			Temperature: 0,
-			MaxTokens:   300,
-		})
+		}
+	}
 
-		if err == nil {
-			break
+	imageTokens := 0
+	if prompt.image != "" {
+		imageTokens = openAIImageTokens(prompt.image)
+	}
+
+	return decideWithRepair(ctx, input, func(ctx context.Context, feedback string) (string, Usage, error) {
+		if feedback != "" {
+			messages = append(messages, openai.ChatCompletionMessage{
+				Role:    openai.ChatMessageRoleUser,
+				Content: feedback,
+			})
 		}
 
-		if strings.Contains(err.Error(), "429") {
-			time.Sleep(time.Duration(3*(1<<attempt)) * time.Second)
-			continue
+		content, usage, err := c.complete(ctx, openai.ChatCompletionRequest{
+			Model:          c.model(input.Params),
+			Messages:       messages,
+			ResponseFormat: format,
+			Temperature:    input.Params.Temperature,
+			MaxTokens:      prompt.maxTokens,
+		})
+		if err != nil {
+			return "", usage, err
 		}
-		return nil, err
+		usage.ImageTokens = imageTokens
+
+		messages = append(messages, openai.ChatCompletionMessage{
+			Role:    openai.ChatMessageRoleAssistant,
+			Content: content,
+		})
+		return content, usage, nil
+	})
+}
+
+func (c *OpenAIClient) complete(ctx context.Context, req openai.ChatCompletionRequest) (string, Usage, error) {
+	var resp openai.ChatCompletionResponse
+	err := c.retry.Do(ctx, func(ctx context.Context) error {
+		var err error
+		resp, err = c.client.CreateChatCompletion(ctx, req)
+		return openAIError(c.provider, err)
+	})
+	if err != nil {
+		return "", Usage{}, err
 	}
 
+	usage := openAIUsage(req, resp)
 	if len(resp.Choices) == 0 {
-		return nil, fmt.Errorf("no choices")
+		return "", usage, fmt.Errorf("no choices")
 	}
+	return resp.Choices[0].Message.Content, usage, nil
+}
 
-	var out DecisionOutput
-	content := strings.Trim(resp.Choices[0].Message.Content, "`")
-
-	if err := json.Unmarshal([]byte(content), &out); err != nil {
-		return nil, err
+func openAIUsage(req openai.ChatCompletionRequest, resp openai.ChatCompletionResponse) Usage {
+	model := resp.Model
+	if model == "" {
+		model = req.Model
+	}
+	return Usage{
+		Model:            model,
+		Calls:            1,
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
 	}
-
-	normalizeActionType(&out.Action)
-	return &out, nil
 }
 
 func normalizeActionType(a *Action) {
-	switch strings.ToLower(string(a.Type)) {
+	switch strings.ToLower(strings.TrimSpace(string(a.Type))) {
 	case "click":
 		a.Type = ActionClick
 	case "type":
 		a.Type = ActionTypeInput
-	case "scroll_down":
+	case "scroll_down", "scroll":
 		a.Type = ActionScroll
-	case "finish":
+	case "finish", "extract":
 		a.Type = ActionFinish
-	default:
-		a.Type = ActionScroll
 	}
 }
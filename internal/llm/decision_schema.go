@@ -0,0 +1,120 @@
+package llm
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	decisionSchemaName = "browser_decision"
+	maxDecisionRepairs = 2
+)
+
+var ErrInvalidDecision = errors.New("invalid decision")
+
+// DecisionError reports a decision that was still invalid after the repair
+// attempts, or whose repair request failed; Err is then the request error.
+// Usage covers every attempt.
+type DecisionError struct {
+	Attempts int
+	Raw      string
+	Err      error
+	Usage    Usage
+}
+
+func (e *DecisionError) Error() string {
+	return fmt.Sprintf("%v after %d attempts: %v", ErrInvalidDecision, e.Attempts, e.Err)
+}
+
+func (e *DecisionError) Unwrap() []error {
+	return []error{ErrInvalidDecision, e.Err}
+}
+
+const decisionActionSchema = `{
+	"type": "object",
+	"additionalProperties": false,
+	"required": ["type", "target_id", "text", "submit", "is_destructive", "destructive_reason", "answer", "success", "evidence"],
+	"properties": {
+		"type": {"type": "string", "enum": ["click", "type", "scroll_down", "finish"]},
+		"target_id": {"type": "integer", "description": "ID from the DOM tree; 0 for scroll_down and finish"},
+		"text": {"type": "string"},
+		"submit": {"type": "boolean"},
+		"is_destructive": {"type": "boolean"},
+		"destructive_reason": {"type": "string"},
+		"answer": {"type": "string"},
+		"success": {"type": "string", "enum": ["true", "partial", "false"]},
+		"evidence": {"type": "array", "items": {"type": "string"}}%s
+	}
+}`
+
+const decisionOutputSchema = `{
+	"type": "object",
+	"additionalProperties": false,
+	"required": ["current_phase", "observation", "thought", "action"],
+	"properties": {
+		"current_phase": {"type": "string"},
+		"observation": {"type": "string"},
+		"thought": {"type": "string"},
+		"action": %s
+	}
+}`
+
+// decisionSchema returns the JSON Schema of DecisionOutput. Without an
+// extraction schema it satisfies the constraints of strict structured
+// outputs; with one, the caller's schema is embedded as action.data and
+// strict mode is not possible.
+func decisionSchema(outputSchema string) (json.RawMessage, bool) {
+	extra := ""
+	strict := true
+	if outputSchema != "" {
+		extra = `,
+		"data": ` + outputSchema
+		strict = false
+	}
+	action := fmt.Sprintf(decisionActionSchema, extra)
+	return json.RawMessage(fmt.Sprintf(decisionOutputSchema, action)), strict
+}
+
+func ValidateDecision(d *DecisionOutput, input DecisionInput) error {
+	a := d.Action
+
+	switch a.Type {
+	case ActionClick, ActionTypeInput:
+		if a.TargetID <= 0 {
+			return fmt.Errorf("action %q requires a positive target_id from the DOM", a.Type)
+		}
+		if input.TargetIDs != nil && !containsID(input.TargetIDs, a.TargetID) {
+			return fmt.Errorf("target_id %d is not in the DOM; use one of the [ID] values listed in DOM", a.TargetID)
+		}
+		if a.Type == ActionTypeInput && strings.TrimSpace(a.Text) == "" {
+			return fmt.Errorf(`action "type" requires non-empty text`)
+		}
+	case ActionScroll, ActionFinish:
+	default:
+		return fmt.Errorf("unknown action type %q; allowed: click, type, scroll_down, finish", a.Type)
+	}
+
+	if a.Type == ActionFinish && input.OutputSchema != "" && len(a.Data) == 0 {
+		return fmt.Errorf("finish in data extraction mode requires action.data matching OUTPUT SCHEMA")
+	}
+	return nil
+}
+
+func containsID(ids []int, id int) bool {
+	for _, v := range ids {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func repairPrompt(err error) string {
+	return fmt.Sprintf(
+		"Your previous response was rejected: %v.\n"+
+			"Respond again with a single corrected JSON decision that follows the required format.",
+		err,
+	)
+}
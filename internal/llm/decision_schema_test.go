@@ -0,0 +1,182 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDecisionSchemaIsValid(t *testing.T) {
+	for _, output := range []string{"", `{"type":"object","properties":{"title":{"type":"string"}}}`} {
+		schema, strict := decisionSchema(output)
+		if err := ValidateSchema(schema); err != nil {
+			t.Fatalf("output schema %q: %v", output, err)
+		}
+		if strict != (output == "") {
+			t.Errorf("output schema %q: strict = %v", output, strict)
+		}
+		if err := ValidateJSON(schema, json.RawMessage(`{
+			"current_phase":"search","observation":"o","thought":"t",
+			"action":{"type":"click","target_id":3,"text":"","submit":false,"is_destructive":false,
+				"destructive_reason":"","answer":"","success":"false","evidence":[]}
+		}`)); err != nil {
+			t.Errorf("output schema %q: valid decision rejected: %v", output, err)
+		}
+	}
+}
+
+func TestValidateDecision(t *testing.T) {
+	cases := []struct {
+		name   string
+		action Action
+		input  DecisionInput
+		ok     bool
+	}{
+		{"click known id", Action{Type: ActionClick, TargetID: 3}, DecisionInput{TargetIDs: []int{1, 3}}, true},
+		{"click without ids", Action{Type: ActionClick, TargetID: 7}, DecisionInput{}, true},
+		{"click unknown id", Action{Type: ActionClick, TargetID: 9}, DecisionInput{TargetIDs: []int{1, 3}}, false},
+		{"click zero id", Action{Type: ActionClick}, DecisionInput{}, false},
+		{"type empty text", Action{Type: ActionTypeInput, TargetID: 1}, DecisionInput{}, false},
+		{"scroll", Action{Type: ActionScroll}, DecisionInput{TargetIDs: []int{}}, true},
+		{"unknown type", Action{Type: "hover", TargetID: 1}, DecisionInput{}, false},
+		{"finish", Action{Type: ActionFinish, Answer: "done"}, DecisionInput{}, true},
+		{"finish without data", Action{Type: ActionFinish}, DecisionInput{OutputSchema: `{"type":"object"}`}, false},
+		{"finish with data", Action{Type: ActionFinish, Data: json.RawMessage(`{}`)}, DecisionInput{OutputSchema: `{"type":"object"}`}, true},
+	}
+
+	for _, tc := range cases {
+		err := ValidateDecision(&DecisionOutput{Action: tc.action}, tc.input)
+		if (err == nil) != tc.ok {
+			t.Errorf("%s: err = %v, want ok=%v", tc.name, err, tc.ok)
+		}
+	}
+}
+
+func TestParseDecisionKeepsUnknownType(t *testing.T) {
+	out, err := parseDecision(`{"action":{"type":"hover","target_id":2}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Action.Type != "hover" {
+		t.Errorf("type = %q, want hover", out.Action.Type)
+	}
+}
+
+// openAIStub answers chat completions with replies in order and records the
+// number of messages of each request.
+func openAIStub(t *testing.T, replies ...string) (*httptest.Server, *[]int) {
+	t.Helper()
+	var lens []int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Messages       []json.RawMessage `json:"messages"`
+			ResponseFormat struct {
+				Type string `json:"type"`
+			} `json:"response_format"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req.ResponseFormat.Type != "json_schema" {
+			t.Errorf("response_format = %q, want json_schema", req.ResponseFormat.Type)
+		}
+
+		reply := replies[min(len(lens), len(replies)-1)]
+		lens = append(lens, len(req.Messages))
+
+		content, _ := json.Marshal(reply)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"model":"gpt-4o-2024-08-06","choices":[{"index":0,"message":{"role":"assistant","content":`+string(content)+`}}],"usage":{"prompt_tokens":100,"completion_tokens":20}}`)
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &lens
+}
+
+func TestDecideActionRepairsInvalidTarget(t *testing.T) {
+	srv, lens := openAIStub(t,
+		`{"action":{"type":"click","target_id":42}}`,
+		`{"action":{"type":"click","target_id":3}}`,
+	)
+
+	client, err := NewClient(ProviderConfig{Provider: ProviderOpenAI, BaseURL: srv.URL, Model: "m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := client.DecideAction(context.Background(), DecisionInput{Task: "t", TargetIDs: []int{1, 3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Action.TargetID != 3 {
+		t.Errorf("target = %d, want 3", out.Action.TargetID)
+	}
+	// system + user, then the rejected answer and the repair feedback.
+	if got := *lens; len(got) != 2 || got[0] != 2 || got[1] != 4 {
+		t.Errorf("request message counts = %v, want [2 4]", got)
+	}
+}
+
+func TestDecideActionGivesUpAfterRepairs(t *testing.T) {
+	srv, lens := openAIStub(t, `{"action":{"type":"hover","target_id":1}}`)
+
+	client, err := NewClient(ProviderConfig{Provider: ProviderOpenAI, BaseURL: srv.URL, Model: "m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = client.DecideAction(context.Background(), DecisionInput{Task: "t"})
+	if !errors.Is(err, ErrInvalidDecision) {
+		t.Fatalf("err = %v, want ErrInvalidDecision", err)
+	}
+	var derr *DecisionError
+	if !errors.As(err, &derr) || derr.Attempts != maxDecisionRepairs+1 || !strings.Contains(derr.Raw, "hover") {
+		t.Errorf("unexpected error details: %+v", derr)
+	}
+	if len(*lens) != maxDecisionRepairs+1 {
+		t.Errorf("requests = %d, want %d", len(*lens), maxDecisionRepairs+1)
+	}
+}
+
+func TestDecideWithRepairKeepsUsageOnRequestError(t *testing.T) {
+	transport := errors.New("connection reset")
+	calls := 0
+	turn := func(ctx context.Context, feedback string) (string, Usage, error) {
+		calls++
+		if calls == 1 {
+			return `{"action":{"type":"hover","target_id":1}}`, Usage{Calls: 1, PromptTokens: 100, CompletionTokens: 10}, nil
+		}
+		return "", Usage{}, transport
+	}
+
+	_, err := decideWithRepair(context.Background(), DecisionInput{Task: "t"}, turn)
+	if !errors.Is(err, transport) {
+		t.Fatalf("err = %v, want the request error", err)
+	}
+	var derr *DecisionError
+	if !errors.As(err, &derr) {
+		t.Fatalf("err = %v, want a DecisionError", err)
+	}
+	if derr.Attempts != 2 || !strings.Contains(derr.Raw, "hover") {
+		t.Errorf("unexpected error details: %+v", derr)
+	}
+	if derr.Usage.Calls != 1 || derr.Usage.PromptTokens != 100 || derr.Usage.CompletionTokens != 10 {
+		t.Errorf("usage = %+v, want the first attempt's", derr.Usage)
+	}
+}
+
+func TestPromptExampleMatchesSchema(t *testing.T) {
+	_, example, ok := strings.Cut(visionSystemPrompt, "RESPONSE JSON FORMAT:\n")
+	if !ok {
+		t.Fatal("no response example in the system prompt")
+	}
+	// The example leaves the action type open; the model fills it in.
+	example = strings.Replace(example, `"type": "..."`, `"type": "click"`, 1)
+
+	schema, _ := decisionSchema("")
+	if err := ValidateJSON(schema, json.RawMessage(example)); err != nil {
+		t.Errorf("system prompt example does not match the decision schema: %v", err)
+	}
+}
@@ -0,0 +1,185 @@
+// Package llmtest provides a scripted llm.Client for tests.
+package llmtest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/llm"
+)
+
+var ErrScriptExhausted = errors.New("llmtest: script exhausted")
+
+// DecideFunc computes the reply to one decision request.
+type DecideFunc func(in llm.DecisionInput) (*llm.DecisionOutput, error)
+
+// Client is a fake llm.Client. Decisions come from a script or a function;
+// every request is recorded for assertions.
+type Client struct {
+	mu     sync.Mutex
+	decide DecideFunc
+
+	inputs    []llm.DecisionInput
+	summaries []llm.SummaryInput
+
+	// Summary and SummaryErr are returned by SummarizeRun.
+	Summary    string
+	SummaryErr error
+}
+
+// NewScripted returns a Client that answers the n-th decision request with
+// the n-th reply and fails with ErrScriptExhausted afterwards.
+func NewScripted(replies ...DecideFunc) *Client {
+	var next int
+	return NewFunc(func(in llm.DecisionInput) (*llm.DecisionOutput, error) {
+		if next >= len(replies) {
+			return nil, fmt.Errorf("%w after %d decisions", ErrScriptExhausted, len(replies))
+		}
+		next++
+		return replies[next-1](in)
+	})
+}
+
+// NewFunc returns a Client that answers every decision request with fn.
+func NewFunc(fn DecideFunc) *Client {
+	return &Client{decide: fn, Summary: "test summary"}
+}
+
+func (c *Client) DecideAction(ctx context.Context, in llm.DecisionInput) (*llm.DecisionOutput, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.inputs = append(c.inputs, in)
+	return c.decide(in)
+}
+
+func (c *Client) SummarizeRun(ctx context.Context, in llm.SummaryInput) (*llm.SummaryOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.summaries = append(c.summaries, in)
+	if c.SummaryErr != nil {
+		return nil, c.SummaryErr
+	}
+	return &llm.SummaryOutput{Text: c.Summary}, nil
+}
+
+// Inputs returns the decision requests received so far.
+func (c *Client) Inputs() []llm.DecisionInput {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]llm.DecisionInput(nil), c.inputs...)
+}
+
+// Summaries returns the summary requests received so far.
+func (c *Client) Summaries() []llm.SummaryInput {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]llm.SummaryInput(nil), c.summaries...)
+}
+
+// Reply returns a DecideFunc that always answers with action.
+func Reply(action llm.Action) DecideFunc {
+	return func(llm.DecisionInput) (*llm.DecisionOutput, error) {
+		return &llm.DecisionOutput{Thought: "scripted", Action: action}, nil
+	}
+}
+
+// Fail returns a DecideFunc that always fails with err.
+func Fail(err error) DecideFunc {
+	return func(llm.DecisionInput) (*llm.DecisionOutput, error) {
+		return nil, err
+	}
+}
+
+func Click(id int) DecideFunc {
+	return Reply(llm.Action{Type: llm.ActionClick, TargetID: id})
+}
+
+func Type(id int, text string, submit bool) DecideFunc {
+	return Reply(llm.Action{Type: llm.ActionTypeInput, TargetID: id, Text: text, Submit: submit})
+}
+
+func Scroll() DecideFunc {
+	return Reply(llm.Action{Type: llm.ActionScroll})
+}
+
+func Finish(answer string) DecideFunc {
+	return Reply(llm.Action{Type: llm.ActionFinish, Answer: answer, Success: llm.FinishSuccess})
+}
+
+// FinishWithData finishes an extraction run with data.
+func FinishWithData(data string) DecideFunc {
+	return Reply(llm.Action{Type: llm.ActionFinish, Success: llm.FinishSuccess, Data: []byte(data)})
+}
+
+var domLine = regexp.MustCompile(`^\[(\d+)\] \[([^\]]*)\](?: "((?:[^"\\]|\\.)*)")?`)
+
+// Element is an interactive element listed in a DOM tree.
+type Element struct {
+	ID   int
+	Role string
+	Name string
+}
+
+// Elements parses the interactive elements of a DOM tree.
+func Elements(dom string) []Element {
+	var out []Element
+	for _, line := range strings.Split(dom, "\n") {
+		m := domLine.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		id, _ := strconv.Atoi(m[1])
+		name := m[3]
+		if unquoted, err := strconv.Unquote(`"` + name + `"`); err == nil {
+			name = unquoted
+		}
+		out = append(out, Element{ID: id, Role: m[2], Name: name})
+	}
+	return out
+}
+
+// FindElement returns the first element of dom whose name matches pattern.
+func FindElement(dom, pattern string) (Element, bool) {
+	re := regexp.MustCompile(pattern)
+	for _, el := range Elements(dom) {
+		if re.MatchString(el.Name) {
+			return el, true
+		}
+	}
+	return Element{}, false
+}
+
+// ClickMatching returns a DecideFunc that clicks the first element whose
+// name matches pattern, and fails when there is none.
+func ClickMatching(pattern string) DecideFunc {
+	return func(in llm.DecisionInput) (*llm.DecisionOutput, error) {
+		el, ok := FindElement(in.DOMTree, pattern)
+		if !ok {
+			return nil, fmt.Errorf("llmtest: no element matching %q in DOM", pattern)
+		}
+		return Click(el.ID)(in)
+	}
+}
+
+// TypeMatching returns a DecideFunc that types text into the first element
+// whose name matches pattern.
+func TypeMatching(pattern, text string, submit bool) DecideFunc {
+	return func(in llm.DecisionInput) (*llm.DecisionOutput, error) {
+		el, ok := FindElement(in.DOMTree, pattern)
+		if !ok {
+			return nil, fmt.Errorf("llmtest: no element matching %q in DOM", pattern)
+		}
+		return Type(el.ID, text, submit)(in)
+	}
+}
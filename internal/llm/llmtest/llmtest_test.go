@@ -0,0 +1,57 @@
+package llmtest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/llm"
+)
+
+const dom = `- [main]
+[1] [textbox] "Search" (Val: pizza)
+[2] [button] "Add \"Margherita\" to cart"
+- [heading] "Menu"
+[3] [link]
+`
+
+func TestElements(t *testing.T) {
+	got := Elements(dom)
+	want := []Element{
+		{1, "textbox", "Search"},
+		{2, "button", `Add "Margherita" to cart`},
+		{3, "link", ""},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("elements = %+v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("element %d = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestScriptedClient(t *testing.T) {
+	ctx := context.Background()
+	c := NewScripted(ClickMatching("Margherita"), Finish("ok"))
+
+	out, err := c.DecideAction(ctx, llm.DecisionInput{DOMTree: dom})
+	if err != nil || out.Action.Type != llm.ActionClick || out.Action.TargetID != 2 {
+		t.Fatalf("first decision = %+v, %v", out, err)
+	}
+	out, err = c.DecideAction(ctx, llm.DecisionInput{})
+	if err != nil || out.Action.Type != llm.ActionFinish || out.Action.Answer != "ok" {
+		t.Fatalf("second decision = %+v, %v", out, err)
+	}
+	if _, err := c.DecideAction(ctx, llm.DecisionInput{}); !errors.Is(err, ErrScriptExhausted) {
+		t.Errorf("err = %v, want ErrScriptExhausted", err)
+	}
+	if len(c.Inputs()) != 3 {
+		t.Errorf("inputs = %d", len(c.Inputs()))
+	}
+
+	if _, err := ClickMatching("Checkout")(llm.DecisionInput{DOMTree: dom}); err == nil {
+		t.Error("expected error for missing element")
+	}
+}
@@ -23,6 +23,10 @@ RULES:
 - Only use IDs from DOM
 - Avoid loops
 - Prefer scroll if unsure
+- When you finish, put the final answer to the USER TASK into "answer"
+  (e.g. the price or status the user asked about), set "success" to
+  true, "partial" or false, and list in "evidence" the short facts from
+  the page that support the answer.
 
 PHASES:
 SEARCH → EXECUTION → VERIFICATION
@@ -37,11 +41,25 @@ RESPONSE JSON FORMAT:
     "target_id": 123,
     "text": "",
     "submit": false,
-    "is_destructive": false
+    "is_destructive": false,
+    "destructive_reason": "",
+    "answer": "",
+    "success": "true",
+    "evidence": []
   }
 }
 `
 
+const extractionPrompt = `
+DATA EXTRACTION MODE:
+The user expects structured data that conforms to OUTPUT SCHEMA.
+- Navigate until every required field can be filled from what you see on the page.
+- Never invent values; only use data visible in the DOM or screenshot.
+- When done, respond with action type "finish" (or "extract") and put the
+  JSON value conforming to OUTPUT SCHEMA into "action.data".
+- If HISTORY reports a schema validation error, fix the data and finish again.
+`
+
 const summarySystemPrompt = `
 You are an analysis module for a browser automation agent.
 
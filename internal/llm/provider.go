@@ -0,0 +1,155 @@
+package llm
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+type Provider string
+
+const (
+	ProviderOpenAI    Provider = "openai"
+	ProviderAnthropic Provider = "anthropic"
+
+	// OpenAI-compatible local servers; they differ in the default base URL,
+	// do not require an API key and always get plain JSON output (see
+	// PlainJSON).
+	ProviderOllama   Provider = "ollama"
+	ProviderVLLM     Provider = "vllm"
+	ProviderLlamaCpp Provider = "llamacpp"
+)
+
+var localBaseURLs = map[Provider]string{
+	ProviderOllama:   "http://localhost:11434/v1",
+	ProviderVLLM:     "http://localhost:8000/v1",
+	ProviderLlamaCpp: "http://localhost:8080/v1",
+}
+
+type ProviderConfig struct {
+	Provider Provider
+	BaseURL  string
+	Model    string
+
+	// APIKey takes precedence over APIKeyEnv. When both are empty the
+	// provider's conventional variable is used (OPENAI_API_KEY,
+	// ANTHROPIC_API_KEY).
+	APIKey    string
+	APIKeyEnv string
+
+	Headers    map[string]string
+	HTTPClient *http.Client
+
+	// PlainJSON asks OpenAI-compatible servers for a bare JSON object
+	// instead of schema-constrained output, for servers that do not
+	// support response_format json_schema. The local presets always set
+	// it; use ProviderOpenAI with BaseURL for a local server that supports
+	// json_schema.
+	PlainJSON bool
+
+	// Retry controls retries of failed calls; the zero value means
+	// DefaultRetryPolicy.
+	Retry RetryPolicy
+}
+
+func NewClient(cfg ProviderConfig) (Client, error) {
+	provider := Provider(strings.ToLower(string(cfg.Provider)))
+	if provider == "" {
+		provider = ProviderOpenAI
+	}
+	cfg.Provider = provider
+
+	switch provider {
+	case ProviderOpenAI:
+		key, err := cfg.apiKey("OPENAI_API_KEY", cfg.BaseURL == "")
+		if err != nil {
+			return nil, err
+		}
+		return newOpenAICompatibleClient(cfg, key), nil
+
+	case ProviderOllama, ProviderVLLM, ProviderLlamaCpp:
+		if cfg.BaseURL == "" {
+			cfg.BaseURL = localBaseURLs[provider]
+		}
+		if cfg.Model == "" {
+			return nil, fmt.Errorf("%s provider requires a model", provider)
+		}
+		cfg.PlainJSON = true
+		key, err := cfg.apiKey("", false)
+		if err != nil {
+			return nil, err
+		}
+		return newOpenAICompatibleClient(cfg, key), nil
+
+	case ProviderAnthropic:
+		key, err := cfg.apiKey("ANTHROPIC_API_KEY", true)
+		if err != nil {
+			return nil, err
+		}
+		if cfg.Model == "" {
+			return nil, fmt.Errorf("%s provider requires a model", provider)
+		}
+		cfg.PlainJSON = true
+		return newAnthropicClient(cfg, key), nil
+
+	default:
+		return nil, fmt.Errorf("unknown LLM provider %q", cfg.Provider)
+	}
+}
+
+func (cfg ProviderConfig) apiKey(defaultEnv string, required bool) (string, error) {
+	if cfg.APIKey != "" {
+		return cfg.APIKey, nil
+	}
+
+	env := cfg.APIKeyEnv
+	if env == "" {
+		env = defaultEnv
+	}
+	if env != "" {
+		if key := os.Getenv(env); key != "" {
+			return key, nil
+		}
+	}
+
+	if required {
+		if env == "" {
+			return "", fmt.Errorf("API key not set")
+		}
+		return "", fmt.Errorf("%s not set", env)
+	}
+	return "", nil
+}
+
+func (cfg ProviderConfig) httpClient() *http.Client {
+	base := cfg.HTTPClient
+	if base == nil {
+		base = &http.Client{}
+	}
+
+	rt := base.Transport
+	if rt == nil {
+		rt = http.DefaultTransport
+	}
+	if len(cfg.Headers) > 0 {
+		rt = &headerTransport{base: rt, headers: cfg.Headers}
+	}
+
+	client := *base
+	client.Transport = &retryHintTransport{base: rt}
+	return &client
+}
+
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for k, v := range t.headers {
+		req.Header.Set(k, v)
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,171 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const decisionJSON = `{"current_phase":"search","observation":"search box","thought":"type","action":{"type":"type","target_id":3,"text":"pizza"}}`
+
+func TestOpenAICompatibleProvider(t *testing.T) {
+	var gotModel, gotHeader, gotAuth, gotFormat string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/chat/completions" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		var req struct {
+			Model          string `json:"model"`
+			ResponseFormat struct {
+				Type string `json:"type"`
+			} `json:"response_format"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		gotModel = req.Model
+		gotFormat = req.ResponseFormat.Type
+		gotHeader = r.Header.Get("X-Team")
+		gotAuth = r.Header.Get("Authorization")
+
+		content, _ := json.Marshal(decisionJSON)
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = io.WriteString(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":`+string(content)+`}}]}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(ProviderConfig{
+		Provider: ProviderOllama,
+		BaseURL:  srv.URL + "/v1",
+		Model:    "llava:13b",
+		Headers:  map[string]string{"X-Team": "agents"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := client.DecideAction(context.Background(), DecisionInput{Task: "find pizza", DOMTree: "[3] [searchBox]"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Action.Type != ActionTypeInput || out.Action.TargetID != 3 || out.Action.Text != "pizza" {
+		t.Errorf("unexpected action: %+v", out.Action)
+	}
+	if gotModel != "llava:13b" {
+		t.Errorf("model = %q, want llava:13b", gotModel)
+	}
+	if gotHeader != "agents" {
+		t.Errorf("X-Team header = %q", gotHeader)
+	}
+	if gotFormat != "json_object" {
+		t.Errorf("response_format = %q, want json_object for a local preset", gotFormat)
+	}
+	if gotAuth != "" && gotAuth != "Bearer " {
+		t.Errorf("unexpected Authorization header %q for a keyless local provider", gotAuth)
+	}
+
+	_, err = client.DecideAction(context.Background(), DecisionInput{
+		Params: ModelParams{Model: "qwen2-vl"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotModel != "qwen2-vl" {
+		t.Errorf("per-call model override ignored: %q", gotModel)
+	}
+}
+
+func TestAnthropicProvider(t *testing.T) {
+	var gotKey, gotVersion string
+	var gotReq anthropicRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/messages" {
+			t.Errorf("unexpected path %s", r.URL.Path)
+		}
+		gotKey = r.Header.Get("x-api-key")
+		gotVersion = r.Header.Get("anthropic-version")
+		_ = json.NewDecoder(r.Body).Decode(&gotReq)
+
+		resp := anthropicResponse{Content: []anthropicContent{{
+			Type:  "tool_use",
+			ID:    "toolu_1",
+			Name:  decisionSchemaName,
+			Input: json.RawMessage(decisionJSON),
+		}}}
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(ProviderConfig{
+		Provider: ProviderAnthropic,
+		BaseURL:  srv.URL,
+		Model:    "test-model",
+		APIKey:   "secret",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := client.DecideAction(context.Background(), DecisionInput{
+		Task:             "find pizza",
+		ScreenshotBase64: "aGVsbG8=",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Action.Type != ActionTypeInput || out.Action.TargetID != 3 {
+		t.Errorf("unexpected action: %+v", out.Action)
+	}
+	if gotKey != "secret" || gotVersion != anthropicVersion {
+		t.Errorf("headers: key=%q version=%q", gotKey, gotVersion)
+	}
+	if gotReq.Model != "test-model" || len(gotReq.Messages) != 1 || len(gotReq.Messages[0].Content) != 2 {
+		t.Errorf("unexpected request: %+v", gotReq)
+	}
+	if !strings.Contains(gotReq.System, "autonomous intelligent agent") {
+		t.Errorf("system prompt not sent")
+	}
+	if len(gotReq.Tools) != 1 || gotReq.ToolChoice == nil || gotReq.ToolChoice.Name != gotReq.Tools[0].Name {
+		t.Errorf("decision tool not forced: tools=%+v choice=%+v", gotReq.Tools, gotReq.ToolChoice)
+	}
+}
+
+func TestAnthropicProviderError(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = io.WriteString(w, `{"type":"error","error":{"type":"authentication_error","message":"invalid x-api-key"}}`)
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(ProviderConfig{Provider: ProviderAnthropic, BaseURL: srv.URL, Model: "m", APIKey: "bad"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = client.SummarizeRun(context.Background(), SummaryInput{Task: "x"})
+	if err == nil || !strings.Contains(err.Error(), "authentication_error") {
+		t.Fatalf("expected authentication error, got %v", err)
+	}
+}
+
+func TestNewClientConfigErrors(t *testing.T) {
+	t.Setenv("OPENAI_API_KEY", "")
+	t.Setenv("ANTHROPIC_API_KEY", "")
+
+	cases := []ProviderConfig{
+		{Provider: ProviderOpenAI},
+		{Provider: ProviderAnthropic, Model: "m"},
+		{Provider: ProviderOllama},
+		{Provider: "bard"},
+	}
+	for _, cfg := range cases {
+		if _, err := NewClient(cfg); err == nil {
+			t.Errorf("%+v: expected error", cfg)
+		}
+	}
+
+	if _, err := NewClient(ProviderConfig{Provider: ProviderOpenAI, BaseURL: "http://localhost:1/v1"}); err != nil {
+		t.Errorf("OpenAI-compatible base URL without key: %v", err)
+	}
+}
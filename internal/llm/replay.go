@@ -0,0 +1,146 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var ErrCassetteExhausted = errors.New("replay: no recorded interactions left")
+
+// MismatchError reports a call that does not match the next recorded
+// interaction.
+type MismatchError struct {
+	Index int
+	// Field is the first request field that differs, or "kind" when a
+	// summary was requested instead of a decision or vice versa.
+	Field string
+	// Offset is the byte offset of the first difference in the field.
+	Offset   int
+	Recorded string
+	Got      string
+}
+
+func (e *MismatchError) Error() string {
+	return fmt.Sprintf("replay: interaction %d: %s differs at byte %d\n recorded: %s\n      got: %s",
+		e.Index, e.Field, e.Offset, excerpt(e.Recorded, e.Offset), excerpt(e.Got, e.Offset))
+}
+
+// excerpt quotes up to 120 bytes of s around offset.
+func excerpt(s string, offset int) string {
+	start := max(0, min(offset-40, len(s)))
+	end := min(len(s), start+120)
+
+	out := fmt.Sprintf("%q", s[start:end])
+	if start > 0 {
+		out = "..." + out
+	}
+	if end < len(s) {
+		out += "..."
+	}
+	return out
+}
+
+// Replayer is a Client that serves the interactions of a cassette in order,
+// without network access. Every call must match the fingerprint of the next
+// recorded interaction.
+type Replayer struct {
+	mu       sync.Mutex
+	cassette *Cassette
+	next     int
+}
+
+func NewReplayer(c *Cassette) *Replayer {
+	return &Replayer{cassette: c}
+}
+
+// NewReplayerFromFile loads the cassette at path.
+func NewReplayerFromFile(path string) (*Replayer, error) {
+	c, err := LoadCassette(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayer(c), nil
+}
+
+// Remaining returns the number of interactions not replayed yet.
+func (r *Replayer) Remaining() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.cassette.Interactions) - r.next
+}
+
+func (r *Replayer) DecideAction(_ context.Context, input DecisionInput) (*DecisionOutput, error) {
+	it, err := r.take(kindDecide, decisionRequest(input))
+	if err != nil {
+		return nil, err
+	}
+	if it.Error != nil {
+		return nil, it.Error.err(it.Usage)
+	}
+	if it.Decision == nil {
+		return nil, fmt.Errorf("replay: interaction has neither decision nor error")
+	}
+
+	out := *it.Decision
+	out.Usage = it.Usage
+	return &out, nil
+}
+
+func (r *Replayer) SummarizeRun(_ context.Context, input SummaryInput) (*SummaryOutput, error) {
+	it, err := r.take(kindSummarize, summaryRequest(input))
+	if err != nil {
+		return nil, err
+	}
+	if it.Error != nil {
+		return nil, it.Error.err(it.Usage)
+	}
+	return &SummaryOutput{Text: it.Summary, Usage: it.Usage}, nil
+}
+
+func (r *Replayer) take(kind interactionKind, req map[string]string) (*Interaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.next >= len(r.cassette.Interactions) {
+		return nil, fmt.Errorf("%w (%s call after %d interactions)", ErrCassetteExhausted, kind, r.next)
+	}
+
+	it := &r.cassette.Interactions[r.next]
+	if it.Kind != kind {
+		return nil, &MismatchError{Index: r.next, Field: "kind", Recorded: string(it.Kind), Got: string(kind)}
+	}
+	if it.Fingerprint != fingerprint(kind, req) {
+		return nil, mismatch(r.next, it.Request, req)
+	}
+
+	r.next++
+	return it, nil
+}
+
+func mismatch(index int, recorded, got map[string]string) *MismatchError {
+	keys := make([]string, 0, len(got))
+	for k := range got {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if recorded[k] != got[k] {
+			return &MismatchError{Index: index, Field: k, Offset: commonPrefix(recorded[k], got[k]), Recorded: recorded[k], Got: got[k]}
+		}
+	}
+	return &MismatchError{Index: index, Field: "fingerprint"}
+}
+
+func commonPrefix(a, b string) int {
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+	return n
+}
@@ -0,0 +1,290 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	openai "github.com/sashabaranov/go-openai"
+)
+
+// ErrorKind classifies LLM API failures.
+type ErrorKind string
+
+const (
+	// ErrorAuth: the key is missing, invalid or not allowed to use the model.
+	ErrorAuth ErrorKind = "auth"
+	// ErrorQuota: the account is out of credits or over its spend limit.
+	ErrorQuota ErrorKind = "quota"
+	// ErrorRateLimit: too many requests; retried after the server's hint.
+	ErrorRateLimit ErrorKind = "rate_limit"
+	// ErrorTransient: 5xx, overload, network failures and attempt timeouts.
+	ErrorTransient ErrorKind = "transient"
+	// ErrorInvalidRequest: the request itself was rejected.
+	ErrorInvalidRequest ErrorKind = "invalid_request"
+)
+
+// APIError is the error returned by clients for failed API calls.
+type APIError struct {
+	Provider   Provider
+	StatusCode int
+	Kind       ErrorKind
+	Message    string
+	// RetryAfter is the delay requested by the server, if any.
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" && e.Err != nil {
+		msg = e.Err.Error()
+	}
+	provider := e.Provider
+	if provider == "" {
+		provider = "llm"
+	}
+	if e.StatusCode == 0 {
+		return fmt.Sprintf("%s: %s", provider, msg)
+	}
+	return fmt.Sprintf("%s: status %d: %s", provider, e.StatusCode, msg)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.Err
+}
+
+func (e *APIError) Retryable() bool {
+	return e.Kind == ErrorRateLimit || e.Kind == ErrorTransient
+}
+
+// Fatal reports whether further calls cannot succeed without operator
+// action, so a run should stop instead of trying the next step.
+func (e *APIError) Fatal() bool {
+	return e.Kind == ErrorAuth || e.Kind == ErrorQuota
+}
+
+// IsFatal reports whether err contains a fatal APIError.
+func IsFatal(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Fatal()
+}
+
+func kindForStatus(status int) ErrorKind {
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return ErrorAuth
+	case status == http.StatusPaymentRequired:
+		return ErrorQuota
+	case status == http.StatusTooManyRequests:
+		return ErrorRateLimit
+	case status == http.StatusRequestTimeout || status >= 500:
+		return ErrorTransient
+	default:
+		return ErrorInvalidRequest
+	}
+}
+
+// RetryPolicy controls retries of LLM calls. The zero value is replaced by
+// DefaultRetryPolicy; otherwise zero delays fall back to the defaults and a
+// zero AttemptTimeout means no limit. Set MaxAttempts to 1 to disable
+// retries.
+type RetryPolicy struct {
+	MaxAttempts int
+	BaseDelay   time.Duration
+	MaxDelay    time.Duration
+	// AttemptTimeout bounds a single HTTP call.
+	AttemptTimeout time.Duration
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    5,
+		BaseDelay:      2 * time.Second,
+		MaxDelay:       time.Minute,
+		AttemptTimeout: 2 * time.Minute,
+	}
+}
+
+func (p RetryPolicy) withDefaults() RetryPolicy {
+	def := DefaultRetryPolicy()
+	if p == (RetryPolicy{}) {
+		return def
+	}
+	if p.MaxAttempts <= 0 {
+		p.MaxAttempts = def.MaxAttempts
+	}
+	if p.BaseDelay <= 0 {
+		p.BaseDelay = def.BaseDelay
+	}
+	if p.MaxDelay <= 0 {
+		p.MaxDelay = def.MaxDelay
+	}
+	if p.AttemptTimeout < 0 {
+		p.AttemptTimeout = 0
+	}
+	return p
+}
+
+// backoff returns the exponential delay before retry n (1-based), with the
+// upper half randomized so that parallel runs do not retry in lockstep.
+func (p RetryPolicy) backoff(n int) time.Duration {
+	d := p.BaseDelay << (n - 1)
+	if d <= 0 || d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	return d/2 + rand.N(d/2+1)
+}
+
+// Do calls fn until it succeeds, fails with a non-retryable error or the
+// attempts run out. Each attempt gets its own deadline; the server's
+// Retry-After hint is used instead of the backoff when it is longer.
+func (p RetryPolicy) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	p = p.withDefaults()
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		err = p.attempt(ctx, fn)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || !apiErr.Retryable() || attempt == p.MaxAttempts {
+			return err
+		}
+
+		delay := p.backoff(attempt)
+		if apiErr.RetryAfter > delay {
+			delay = apiErr.RetryAfter
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(delay):
+		}
+	}
+}
+
+func (p RetryPolicy) attempt(ctx context.Context, fn func(ctx context.Context) error) error {
+	hint := &retryHint{}
+	attemptCtx := context.WithValue(ctx, retryHintKey{}, hint)
+	if p.AttemptTimeout > 0 {
+		var cancel context.CancelFunc
+		attemptCtx, cancel = context.WithTimeout(attemptCtx, p.AttemptTimeout)
+		defer cancel()
+	}
+
+	err := fn(attemptCtx)
+	if err == nil {
+		return nil
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		if apiErr.RetryAfter == 0 {
+			apiErr.RetryAfter = hint.after
+		}
+		return err
+	}
+	if ctx.Err() == nil && errors.Is(attemptCtx.Err(), context.DeadlineExceeded) {
+		return &APIError{
+			Kind:    ErrorTransient,
+			Message: fmt.Sprintf("attempt timed out after %s", p.AttemptTimeout),
+			Err:     err,
+		}
+	}
+	return err
+}
+
+// retryHint carries the Retry-After header of the last response of an
+// attempt from the transport back to RetryPolicy.Do, for clients that do
+// not expose response headers in their errors.
+type retryHint struct {
+	after time.Duration
+}
+
+type retryHintKey struct{}
+
+type retryHintTransport struct {
+	base http.RoundTripper
+}
+
+func (t *retryHintTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return resp, err
+	}
+	if hint, ok := req.Context().Value(retryHintKey{}).(*retryHint); ok {
+		hint.after = parseRetryAfter(resp.Header)
+	}
+	return resp, nil
+}
+
+func parseRetryAfter(h http.Header) time.Duration {
+	if ms, err := strconv.Atoi(h.Get("retry-after-ms")); err == nil && ms > 0 {
+		return time.Duration(ms) * time.Millisecond
+	}
+
+	v := strings.TrimSpace(h.Get("Retry-After"))
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// openAIError converts errors of the go-openai client into *APIError,
+// labelled with the configured provider.
+func openAIError(provider Provider, err error) error {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return err
+	}
+
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		kind := kindForStatus(apiErr.HTTPStatusCode)
+		if code, _ := apiErr.Code.(string); code == "insufficient_quota" || apiErr.Type == "insufficient_quota" {
+			kind = ErrorQuota
+		}
+		return &APIError{
+			Provider:   provider,
+			StatusCode: apiErr.HTTPStatusCode,
+			Kind:       kind,
+			Message:    apiErr.Message,
+			Err:        err,
+		}
+	}
+
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return &APIError{
+			Provider:   provider,
+			StatusCode: reqErr.HTTPStatusCode,
+			Kind:       kindForStatus(reqErr.HTTPStatusCode),
+			Message:    strings.TrimSpace(string(reqErr.Body)),
+			Err:        err,
+		}
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+	return &APIError{Provider: provider, Kind: ErrorTransient, Err: err}
+}
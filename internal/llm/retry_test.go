@@ -0,0 +1,188 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+var fastRetry = RetryPolicy{MaxAttempts: 3, BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond}
+
+func TestParseRetryAfter(t *testing.T) {
+	cases := []struct {
+		header, value string
+		want          time.Duration
+	}{
+		{"Retry-After", "3", 3 * time.Second},
+		{"retry-after-ms", "250", 250 * time.Millisecond},
+		{"Retry-After", "soon", 0},
+		{"Retry-After", "", 0},
+	}
+	for _, tc := range cases {
+		h := http.Header{}
+		h.Set(tc.header, tc.value)
+		if got := parseRetryAfter(h); got != tc.want {
+			t.Errorf("%s: %q = %s, want %s", tc.header, tc.value, got, tc.want)
+		}
+	}
+
+	h := http.Header{}
+	h.Set("Retry-After", time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+	if got := parseRetryAfter(h); got < 59*time.Minute {
+		t.Errorf("HTTP date: %s", got)
+	}
+}
+
+func TestRetryPolicyDo(t *testing.T) {
+	transient := &APIError{Kind: ErrorTransient}
+	auth := &APIError{Kind: ErrorAuth}
+
+	cases := []struct {
+		name  string
+		errs  []error
+		calls int
+		want  error
+	}{
+		{"success", []error{nil}, 1, nil},
+		{"transient then success", []error{transient, transient, nil}, 3, nil},
+		{"gives up", []error{transient, transient, transient, nil}, 3, transient},
+		{"auth is not retried", []error{auth, nil}, 1, auth},
+		{"plain errors are not retried", []error{io.ErrUnexpectedEOF, nil}, 1, io.ErrUnexpectedEOF},
+	}
+
+	for _, tc := range cases {
+		calls := 0
+		err := fastRetry.Do(context.Background(), func(context.Context) error {
+			calls++
+			return tc.errs[calls-1]
+		})
+		if calls != tc.calls || !errors.Is(err, tc.want) {
+			t.Errorf("%s: calls=%d err=%v, want calls=%d err=%v", tc.name, calls, err, tc.calls, tc.want)
+		}
+	}
+}
+
+func TestRetryPolicyAttemptTimeout(t *testing.T) {
+	p := fastRetry
+	p.AttemptTimeout = 10 * time.Millisecond
+
+	calls := 0
+	err := p.Do(context.Background(), func(ctx context.Context) error {
+		calls++
+		if calls == 1 {
+			<-ctx.Done()
+			return ctx.Err()
+		}
+		return nil
+	})
+	if err != nil || calls != 2 {
+		t.Fatalf("calls=%d err=%v, want a retry after the attempt timeout", calls, err)
+	}
+}
+
+func TestRetryPolicyStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	err := fastRetry.Do(ctx, func(context.Context) error {
+		calls++
+		cancel()
+		return &APIError{Kind: ErrorTransient}
+	})
+	if calls != 1 || err == nil {
+		t.Errorf("calls=%d err=%v", calls, err)
+	}
+}
+
+func TestOpenAIClientRetries(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch calls.Add(1) {
+		case 1:
+			w.Header().Set("retry-after-ms", "20")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, _ = io.WriteString(w, `{"error":{"message":"slow down","type":"requests","code":"rate_limit_exceeded"}}`)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+			_, _ = io.WriteString(w, `{"error":{"message":"upstream","type":"server_error"}}`)
+		default:
+			_, _ = io.WriteString(w, `{"choices":[{"index":0,"message":{"role":"assistant","content":"done"}}]}`)
+		}
+	}))
+	defer srv.Close()
+
+	client, err := NewClient(ProviderConfig{Provider: ProviderOpenAI, BaseURL: srv.URL, Model: "m", Retry: fastRetry})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Now()
+	out, err := client.SummarizeRun(context.Background(), SummaryInput{Task: "t"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out.Text != "done" || calls.Load() != 3 {
+		t.Errorf("text=%q calls=%d", out.Text, calls.Load())
+	}
+	if time.Since(start) < 20*time.Millisecond {
+		t.Errorf("Retry-After hint was not honored")
+	}
+}
+
+func TestClientErrorKinds(t *testing.T) {
+	cases := []struct {
+		provider Provider
+		status   int
+		body     string
+		want     ErrorKind
+	}{
+		{ProviderOpenAI, 401, `{"error":{"message":"bad key","type":"invalid_request_error","code":"invalid_api_key"}}`, ErrorAuth},
+		{ProviderOpenAI, 429, `{"error":{"message":"no credits","type":"insufficient_quota","code":"insufficient_quota"}}`, ErrorQuota},
+		{ProviderOpenAI, 400, `{"error":{"message":"bad","type":"invalid_request_error"}}`, ErrorInvalidRequest},
+		{ProviderVLLM, 503, `{"error":{"message":"loading","type":"server_error"}}`, ErrorTransient},
+		{ProviderAnthropic, 403, `{"type":"error","error":{"type":"permission_error","message":"nope"}}`, ErrorAuth},
+		{ProviderAnthropic, 400, `{"type":"error","error":{"type":"invalid_request_error","message":"Your credit balance is too low"}}`, ErrorQuota},
+		{ProviderAnthropic, 529, `{"type":"error","error":{"type":"overloaded_error","message":"Overloaded"}}`, ErrorTransient},
+	}
+
+	for _, tc := range cases {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(tc.status)
+			_, _ = io.WriteString(w, tc.body)
+		}))
+
+		client, err := NewClient(ProviderConfig{
+			Provider: tc.provider,
+			BaseURL:  srv.URL,
+			Model:    "m",
+			APIKey:   "k",
+			Retry:    RetryPolicy{MaxAttempts: 1},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = client.SummarizeRun(context.Background(), SummaryInput{Task: "t"})
+		srv.Close()
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Errorf("%s %d: error %v is not an APIError", tc.provider, tc.status, err)
+			continue
+		}
+		if apiErr.Kind != tc.want || apiErr.StatusCode != tc.status {
+			t.Errorf("%s %d: kind=%s status=%d, want %s", tc.provider, tc.status, apiErr.Kind, apiErr.StatusCode, tc.want)
+		}
+		if apiErr.Provider != tc.provider {
+			t.Errorf("%s %d: error labelled %q", tc.provider, tc.status, apiErr.Provider)
+		}
+		if apiErr.Fatal() != (tc.want == ErrorAuth || tc.want == ErrorQuota) {
+			t.Errorf("%s %d: Fatal() = %v", tc.provider, tc.status, apiErr.Fatal())
+		}
+	}
+}
@@ -0,0 +1,425 @@
+package llm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type SchemaError struct {
+	Path    string
+	Message string
+}
+
+func (e *SchemaError) Error() string {
+	if e.Path == "" {
+		return e.Message
+	}
+	return e.Path + ": " + e.Message
+}
+
+type jsonSchema struct {
+	Type                 json.RawMessage        `json:"type,omitempty"`
+	Properties           map[string]*jsonSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	AdditionalProperties json.RawMessage        `json:"additionalProperties,omitempty"`
+	Items                *jsonSchema            `json:"items,omitempty"`
+	Enum                 []json.RawMessage      `json:"enum,omitempty"`
+	Const                json.RawMessage        `json:"const,omitempty"`
+	MinItems             *int                   `json:"minItems,omitempty"`
+	MaxItems             *int                   `json:"maxItems,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty"`
+	MaxLength            *int                   `json:"maxLength,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	Maximum              *float64               `json:"maximum,omitempty"`
+	Pattern              string                 `json:"pattern,omitempty"`
+	AnyOf                []*jsonSchema          `json:"anyOf,omitempty"`
+}
+
+// unsupportedKeywords are the validation keywords jsonSchema does not
+// implement. A schema using them is rejected rather than half-enforced.
+var unsupportedKeywords = []string{
+	"$ref", "$defs", "definitions", "$dynamicRef", "$anchor",
+	"oneOf", "allOf", "not", "if", "then", "else",
+	"prefixItems", "additionalItems", "contains", "minContains", "maxContains", "uniqueItems",
+	"patternProperties", "propertyNames", "minProperties", "maxProperties",
+	"dependentRequired", "dependentSchemas", "dependencies",
+	"unevaluatedProperties", "unevaluatedItems",
+	"exclusiveMinimum", "exclusiveMaximum", "multipleOf",
+}
+
+// ValidateSchema checks that schema is a JSON Schema document this package
+// can validate against. Only the keywords listed in jsonSchema are enforced;
+// annotations (format, description, $schema, ...) are accepted and ignored,
+// and the validation keywords in unsupportedKeywords are rejected.
+func ValidateSchema(schema json.RawMessage) error {
+	_, err := parseSchema(schema)
+	return err
+}
+
+// ValidateJSON validates data against schema and returns a *SchemaError
+// describing the first violation found.
+func ValidateJSON(schema, data json.RawMessage) error {
+	s, err := parseSchema(schema)
+	if err != nil {
+		return err
+	}
+
+	var v any
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&v); err != nil {
+		return &SchemaError{Message: fmt.Sprintf("invalid JSON: %v", err)}
+	}
+
+	return s.validate("$", v)
+}
+
+func parseSchema(schema json.RawMessage) (*jsonSchema, error) {
+	if len(bytes.TrimSpace(schema)) == 0 {
+		return nil, fmt.Errorf("empty schema")
+	}
+	if err := checkSchema("$", schema); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	var s jsonSchema
+	if err := json.Unmarshal(schema, &s); err != nil {
+		return nil, fmt.Errorf("invalid schema: %w", err)
+	}
+	return &s, nil
+}
+
+// checkSchema walks the subschemas of schema, at path, for keywords it
+// cannot enforce, bad types and patterns that do not compile.
+func checkSchema(path string, schema json.RawMessage) error {
+	var keywords map[string]json.RawMessage
+	if err := json.Unmarshal(schema, &keywords); err != nil {
+		return &SchemaError{Path: path, Message: "schema must be an object"}
+	}
+	for _, k := range unsupportedKeywords {
+		if _, ok := keywords[k]; ok {
+			return &SchemaError{Path: path, Message: fmt.Sprintf("keyword %q is not supported", k)}
+		}
+	}
+
+	var s jsonSchema
+	if err := json.Unmarshal(schema, &s); err != nil {
+		if items := bytes.TrimSpace(keywords["items"]); len(items) > 0 && items[0] == '[' {
+			return &SchemaError{Path: path, Message: "tuple-form items is not supported"}
+		}
+		return &SchemaError{Path: path, Message: err.Error()}
+	}
+	if _, err := s.types(); err != nil {
+		return &SchemaError{Path: path, Message: err.Error()}
+	}
+	if s.Pattern != "" {
+		if _, err := regexp.Compile(s.Pattern); err != nil {
+			return &SchemaError{Path: path, Message: fmt.Sprintf("invalid pattern %q: %v", s.Pattern, err)}
+		}
+	}
+
+	var sub struct {
+		Properties           map[string]json.RawMessage `json:"properties"`
+		Items                json.RawMessage            `json:"items"`
+		AnyOf                []json.RawMessage          `json:"anyOf"`
+		AdditionalProperties json.RawMessage            `json:"additionalProperties"`
+	}
+	if err := json.Unmarshal(schema, &sub); err != nil {
+		return &SchemaError{Path: path, Message: err.Error()}
+	}
+	names := make([]string, 0, len(sub.Properties))
+	for name := range sub.Properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if err := checkSchema(path+".properties."+name, sub.Properties[name]); err != nil {
+			return err
+		}
+	}
+	if len(sub.Items) > 0 {
+		if err := checkSchema(path+".items", sub.Items); err != nil {
+			return err
+		}
+	}
+	for i, alt := range sub.AnyOf {
+		if err := checkSchema(fmt.Sprintf("%s.anyOf[%d]", path, i), alt); err != nil {
+			return err
+		}
+	}
+	if extra := bytes.TrimSpace(sub.AdditionalProperties); len(extra) > 0 && extra[0] == '{' {
+		if err := checkSchema(path+".additionalProperties", extra); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *jsonSchema) types() ([]string, error) {
+	if len(s.Type) == 0 {
+		return nil, nil
+	}
+	var one string
+	if err := json.Unmarshal(s.Type, &one); err == nil {
+		return []string{one}, nil
+	}
+	var many []string
+	if err := json.Unmarshal(s.Type, &many); err != nil {
+		return nil, fmt.Errorf("type must be a string or an array of strings")
+	}
+	return many, nil
+}
+
+func (s *jsonSchema) validate(path string, v any) error {
+	if s == nil {
+		return nil
+	}
+
+	types, err := s.types()
+	if err != nil {
+		return &SchemaError{Path: path, Message: err.Error()}
+	}
+	if len(types) > 0 {
+		matched := false
+		for _, t := range types {
+			if jsonTypeMatches(t, v) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return &SchemaError{
+				Path:    path,
+				Message: fmt.Sprintf("expected %s, got %s", strings.Join(types, " or "), jsonTypeName(v)),
+			}
+		}
+	}
+
+	if len(s.Const) > 0 && !jsonEqual(s.Const, v) {
+		return &SchemaError{Path: path, Message: fmt.Sprintf("must be %s", string(s.Const))}
+	}
+
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			if jsonEqual(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			allowed := make([]string, 0, len(s.Enum))
+			for _, e := range s.Enum {
+				allowed = append(allowed, string(e))
+			}
+			return &SchemaError{Path: path, Message: "must be one of " + strings.Join(allowed, ", ")}
+		}
+	}
+
+	if len(s.AnyOf) > 0 {
+		var first error
+		ok := false
+		for _, sub := range s.AnyOf {
+			if err := sub.validate(path, v); err == nil {
+				ok = true
+				break
+			} else if first == nil {
+				first = err
+			}
+		}
+		if !ok {
+			return &SchemaError{Path: path, Message: fmt.Sprintf("does not match any allowed schema (%v)", first)}
+		}
+	}
+
+	switch vv := v.(type) {
+	case map[string]any:
+		return s.validateObject(path, vv)
+	case []any:
+		return s.validateArray(path, vv)
+	case string:
+		return s.validateString(path, vv)
+	case json.Number:
+		return s.validateNumber(path, vv)
+	}
+	return nil
+}
+
+func (s *jsonSchema) validateObject(path string, obj map[string]any) error {
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			return &SchemaError{Path: path, Message: fmt.Sprintf("missing required property %q", name)}
+		}
+	}
+
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		sub, ok := s.Properties[k]
+		if ok {
+			if err := sub.validate(path+"."+k, obj[k]); err != nil {
+				return err
+			}
+			continue
+		}
+
+		extra := bytes.TrimSpace(s.AdditionalProperties)
+		switch {
+		case len(extra) == 0 || bytes.Equal(extra, []byte("true")):
+		case bytes.Equal(extra, []byte("false")):
+			return &SchemaError{Path: path, Message: fmt.Sprintf("unexpected property %q", k)}
+		default:
+			var extraSchema jsonSchema
+			if err := json.Unmarshal(extra, &extraSchema); err != nil {
+				return &SchemaError{Path: path, Message: "invalid additionalProperties schema"}
+			}
+			if err := extraSchema.validate(path+"."+k, obj[k]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (s *jsonSchema) validateArray(path string, arr []any) error {
+	if s.MinItems != nil && len(arr) < *s.MinItems {
+		return &SchemaError{Path: path, Message: fmt.Sprintf("expected at least %d items, got %d", *s.MinItems, len(arr))}
+	}
+	if s.MaxItems != nil && len(arr) > *s.MaxItems {
+		return &SchemaError{Path: path, Message: fmt.Sprintf("expected at most %d items, got %d", *s.MaxItems, len(arr))}
+	}
+	for i, item := range arr {
+		if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *jsonSchema) validateString(path, str string) error {
+	n := len([]rune(str))
+	if s.MinLength != nil && n < *s.MinLength {
+		return &SchemaError{Path: path, Message: fmt.Sprintf("expected at least %d characters", *s.MinLength)}
+	}
+	if s.MaxLength != nil && n > *s.MaxLength {
+		return &SchemaError{Path: path, Message: fmt.Sprintf("expected at most %d characters", *s.MaxLength)}
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return &SchemaError{Path: path, Message: fmt.Sprintf("invalid pattern %q", s.Pattern)}
+		}
+		if !re.MatchString(str) {
+			return &SchemaError{Path: path, Message: fmt.Sprintf("does not match pattern %q", s.Pattern)}
+		}
+	}
+	return nil
+}
+
+func (s *jsonSchema) validateNumber(path string, num json.Number) error {
+	f, err := num.Float64()
+	if err != nil {
+		return &SchemaError{Path: path, Message: fmt.Sprintf("invalid number %s", num)}
+	}
+	if s.Minimum != nil && f < *s.Minimum {
+		return &SchemaError{Path: path, Message: fmt.Sprintf("must be >= %v", *s.Minimum)}
+	}
+	if s.Maximum != nil && f > *s.Maximum {
+		return &SchemaError{Path: path, Message: fmt.Sprintf("must be <= %v", *s.Maximum)}
+	}
+	return nil
+}
+
+func jsonTypeMatches(t string, v any) bool {
+	switch t {
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "null":
+		return v == nil
+	case "number":
+		_, ok := v.(json.Number)
+		return ok
+	case "integer":
+		n, ok := v.(json.Number)
+		if !ok {
+			return false
+		}
+		f, err := n.Float64()
+		return err == nil && f == math.Trunc(f)
+	default:
+		return false
+	}
+}
+
+func jsonTypeName(v any) string {
+	switch v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%T", v)
+	}
+}
+
+func jsonEqual(raw json.RawMessage, v any) bool {
+	var want any
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err := dec.Decode(&want); err != nil {
+		return false
+	}
+	a, errA := json.Marshal(normalizeJSONValue(want))
+	b, errB := json.Marshal(normalizeJSONValue(v))
+	return errA == nil && errB == nil && bytes.Equal(a, b)
+}
+
+func normalizeJSONValue(v any) any {
+	switch vv := v.(type) {
+	case json.Number:
+		if f, err := vv.Float64(); err == nil {
+			return f
+		}
+		return vv.String()
+	case []any:
+		out := make([]any, len(vv))
+		for i, item := range vv {
+			out[i] = normalizeJSONValue(item)
+		}
+		return out
+	case map[string]any:
+		out := make(map[string]any, len(vv))
+		for k, item := range vv {
+			out[k] = normalizeJSONValue(item)
+		}
+		return out
+	default:
+		return v
+	}
+}
@@ -0,0 +1,104 @@
+package llm
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const productSchema = `{
+	"type": "object",
+	"required": ["name", "price", "tags"],
+	"additionalProperties": false,
+	"properties": {
+		"name":  {"type": "string", "minLength": 1},
+		"price": {"type": "number", "minimum": 0},
+		"currency": {"enum": ["TRY", "RUB", "USD"]},
+		"tags":  {"type": "array", "maxItems": 2, "items": {"type": "string"}}
+	}
+}`
+
+func TestValidateJSON(t *testing.T) {
+	cases := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"valid", `{"name":"Mix Tost","price":120.5,"currency":"TRY","tags":["food"]}`, ""},
+		{"missing required", `{"name":"Mix Tost","tags":[]}`, `$: missing required property "price"`},
+		{"wrong type", `{"name":"Mix Tost","price":"120","tags":[]}`, "$.price: expected number, got string"},
+		{"enum", `{"name":"x","price":1,"currency":"EUR","tags":[]}`, `$.currency: must be one of "TRY", "RUB", "USD"`},
+		{"extra property", `{"name":"x","price":1,"tags":[],"qty":2}`, `$: unexpected property "qty"`},
+		{"array item", `{"name":"x","price":1,"tags":[1]}`, "$.tags[0]: expected string, got number"},
+		{"max items", `{"name":"x","price":1,"tags":["a","b","c"]}`, "$.tags: expected at most 2 items, got 3"},
+		{"minimum", `{"name":"x","price":-1,"tags":[]}`, "$.price: must be >= 0"},
+		{"not json", `{"name":`, "invalid JSON: unexpected EOF"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateJSON(json.RawMessage(productSchema), json.RawMessage(tc.data))
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || err.Error() != tc.wantErr {
+				t.Fatalf("got error %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSchemaRejectsBrokenSchema(t *testing.T) {
+	for _, schema := range []string{``, `{"type": 5}`, `{"pattern": "("}`, `[`} {
+		if err := ValidateSchema(json.RawMessage(schema)); err == nil {
+			t.Errorf("schema %q: expected error", schema)
+		}
+	}
+}
+
+func TestValidateSchemaNestedPattern(t *testing.T) {
+	cases := []struct {
+		schema  string
+		wantErr string
+	}{
+		{`{"properties": {"sku": {"type": "string", "pattern": "("}}}`, "$.properties.sku: invalid pattern"},
+		{`{"items": {"pattern": "[a-"}}`, "$.items: invalid pattern"},
+		{`{"anyOf": [{"type": "string"}, {"pattern": "(?<x"}]}`, "$.anyOf[1]: invalid pattern"},
+		{`{"additionalProperties": {"pattern": "*"}}`, "$.additionalProperties: invalid pattern"},
+	}
+	for _, tc := range cases {
+		err := ValidateSchema(json.RawMessage(tc.schema))
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("schema %s: got error %v, want %q", tc.schema, err, tc.wantErr)
+		}
+	}
+}
+
+func TestValidateSchemaRejectsUnsupportedKeywords(t *testing.T) {
+	cases := []struct {
+		schema  string
+		wantErr string
+	}{
+		{`{"$ref": "#/$defs/item", "$defs": {"item": {"type": "string"}}}`, `$: keyword "$ref" is not supported`},
+		{`{"properties": {"x": {"oneOf": [{"type": "string"}]}}}`, `$.properties.x: keyword "oneOf" is not supported`},
+		{`{"allOf": [{"type": "object"}]}`, `$: keyword "allOf" is not supported`},
+		{`{"items": [{"type": "string"}, {"type": "number"}]}`, "$: tuple-form items is not supported"},
+		{`{"items": {"type": "number", "exclusiveMinimum": 0}}`, `$.items: keyword "exclusiveMinimum" is not supported`},
+	}
+	for _, tc := range cases {
+		err := ValidateSchema(json.RawMessage(tc.schema))
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("schema %s: got error %v, want %q", tc.schema, err, tc.wantErr)
+		}
+	}
+
+	// anyOf and annotations are supported.
+	ok := `{"$schema": "https://json-schema.org/draft/2020-12/schema", "description": "d",
+		"anyOf": [{"type": "string", "format": "email"}, {"type": "null"}]}`
+	if err := ValidateSchema(json.RawMessage(ok)); err != nil {
+		t.Errorf("supported schema rejected: %v", err)
+	}
+}
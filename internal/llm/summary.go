@@ -2,15 +2,12 @@ package llm
 
 import (
 	"context"
-	"fmt"
 	"strings"
 
 	openai "github.com/sashabaranov/go-openai"
 )
 
-func (c *OpenAIClient) SummarizeRun(input SummaryInput) (string, error) {
-	ctx := context.Background()
-
+func buildSummaryPrompt(input SummaryInput) string {
 	var sb strings.Builder
 	sb.WriteString("TASK:\n" + input.Task + "\n\n")
 	sb.WriteString("EXIT_REASON:\n" + input.ExitReason + "\n\n")
@@ -26,23 +23,21 @@ func (c *OpenAIClient) SummarizeRun(input SummaryInput) (string, error) {
 			sb.WriteString(s + "\n")
 		}
 	}
+	return sb.String()
+}
 
-	resp, err := c.client.CreateChatCompletion(ctx, openai.ChatCompletionRequest{
-		Model: "gpt-4o",
+func (c *OpenAIClient) SummarizeRun(ctx context.Context, input SummaryInput) (*SummaryOutput, error) {
+	text, usage, err := c.complete(ctx, openai.ChatCompletionRequest{
+		Model: c.model(ModelParams{Model: input.Model}),
 		Messages: []openai.ChatCompletionMessage{
 			{Role: openai.ChatMessageRoleSystem, Content: summarySystemPrompt},
-			{Role: openai.ChatMessageRoleUser, Content: sb.String()},
+			{Role: openai.ChatMessageRoleUser, Content: buildSummaryPrompt(input)},
 		},
-		Temperature: 0.2,
-		MaxTokens:   600,
+		Temperature: defaultSummaryTemperature,
+		MaxTokens:   defaultSummaryMaxTokens,
 	})
 	if err != nil {
-		return "", err
+		return nil, err
 	}
-
-	if len(resp.Choices) == 0 {
-		return "", fmt.Errorf("no summary choices")
-	}
-
-	return resp.Choices[0].Message.Content, nil
+	return &SummaryOutput{Text: text, Usage: usage}, nil
 }
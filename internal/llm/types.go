@@ -1,5 +1,12 @@
 package llm
 
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
 type ActionType string
 
 const (
@@ -9,22 +16,78 @@ const (
 	ActionFinish    ActionType = "finish"
 )
 
+type FinishStatus string
+
+const (
+	FinishSuccess FinishStatus = "true"
+	FinishPartial FinishStatus = "partial"
+	FinishFailure FinishStatus = "false"
+)
+
+func (s *FinishStatus) UnmarshalJSON(b []byte) error {
+	var flag bool
+	if err := json.Unmarshal(b, &flag); err == nil {
+		if flag {
+			*s = FinishSuccess
+		} else {
+			*s = FinishFailure
+		}
+		return nil
+	}
+
+	var str string
+	if err := json.Unmarshal(b, &str); err != nil {
+		return fmt.Errorf("success must be true, false or \"partial\": %s", b)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(str)) {
+	case "true", "yes", "success":
+		*s = FinishSuccess
+	case "partial":
+		*s = FinishPartial
+	case "false", "no", "failure", "failed":
+		*s = FinishFailure
+	case "":
+		*s = ""
+	default:
+		return fmt.Errorf("success must be true, false or \"partial\": %s", b)
+	}
+	return nil
+}
+
 type Action struct {
 	Type     ActionType `json:"type"`
 	TargetID int        `json:"target_id,omitempty"`
 	Text     string     `json:"text,omitempty"`
 	Submit   bool       `json:"submit,omitempty"`
 
+	Answer   string          `json:"answer,omitempty"`
+	Success  FinishStatus    `json:"success,omitempty"`
+	Evidence []string        `json:"evidence,omitempty"`
+	Data     json.RawMessage `json:"data,omitempty"`
+
 	IsDestructive     bool   `json:"is_destructive,omitempty"`
 	DestructiveReason string `json:"destructive_reason,omitempty"`
 }
 
+type ModelParams struct {
+	Model       string
+	Temperature float32
+	MaxTokens   int
+}
+
 type DecisionInput struct {
 	Task             string
 	DOMTree          string
 	CurrentURL       string
 	History          string
 	ScreenshotBase64 string
+	OutputSchema     string
+	Params           ModelParams
+
+	// TargetIDs lists the element IDs present in DOMTree. When non-nil,
+	// decisions targeting any other ID are rejected and re-asked.
+	TargetIDs []int
 }
 
 type DecisionOutput struct {
@@ -33,6 +96,9 @@ type DecisionOutput struct {
 	Thought      string `json:"thought"`
 	StepDone     bool   `json:"step_done"`
 	Action       Action `json:"action"`
+
+	// Usage is filled by the client, not by the model.
+	Usage Usage `json:"-"`
 }
 
 type SummaryInput struct {
@@ -42,9 +108,15 @@ type SummaryInput struct {
 	FinalAction Action
 	Duration    string
 	Steps       []string
+	Model       string
+}
+
+type SummaryOutput struct {
+	Text  string
+	Usage Usage
 }
 
 type Client interface {
-	DecideAction(input DecisionInput) (*DecisionOutput, error)
-	SummarizeRun(input SummaryInput) (string, error)
+	DecideAction(ctx context.Context, input DecisionInput) (*DecisionOutput, error)
+	SummarizeRun(ctx context.Context, input SummaryInput) (*SummaryOutput, error)
 }
@@ -0,0 +1,42 @@
+package llm
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestActionFinishPayload(t *testing.T) {
+	cases := []struct {
+		raw  string
+		want FinishStatus
+	}{
+		{`{"type":"finish","success":true}`, FinishSuccess},
+		{`{"type":"finish","success":false}`, FinishFailure},
+		{`{"type":"finish","success":"partial"}`, FinishPartial},
+		{`{"type":"finish","success":"True"}`, FinishSuccess},
+		{`{"type":"finish"}`, ""},
+	}
+
+	for _, tc := range cases {
+		var a Action
+		if err := json.Unmarshal([]byte(tc.raw), &a); err != nil {
+			t.Fatalf("%s: %v", tc.raw, err)
+		}
+		if a.Success != tc.want {
+			t.Errorf("%s: success = %q, want %q", tc.raw, a.Success, tc.want)
+		}
+	}
+
+	var a Action
+	raw := `{"type":"finish","answer":"129 TL","success":"partial","evidence":["Mix Tost 129 TL"]}`
+	if err := json.Unmarshal([]byte(raw), &a); err != nil {
+		t.Fatal(err)
+	}
+	if a.Answer != "129 TL" || len(a.Evidence) != 1 || a.Evidence[0] != "Mix Tost 129 TL" {
+		t.Errorf("unexpected action: %+v", a)
+	}
+
+	if err := json.Unmarshal([]byte(`{"success":"maybe"}`), &a); err == nil {
+		t.Error("expected error for unknown success value")
+	}
+}
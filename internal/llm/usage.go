@@ -0,0 +1,154 @@
+package llm
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"os"
+	"strings"
+)
+
+// Usage counts the tokens of one or more LLM calls. ImageTokens is an
+// estimate of the part of PromptTokens spent on screenshots.
+type Usage struct {
+	Model            string `json:"model,omitempty"`
+	Calls            int    `json:"calls"`
+	PromptTokens     int    `json:"prompt_tokens"`
+	CompletionTokens int    `json:"completion_tokens"`
+	ImageTokens      int    `json:"image_tokens,omitempty"`
+}
+
+func (u Usage) TotalTokens() int {
+	return u.PromptTokens + u.CompletionTokens
+}
+
+// Add returns the sum of u and o. Model is kept only when both agree.
+func (u Usage) Add(o Usage) Usage {
+	model := u.Model
+	if u.Calls == 0 {
+		model = o.Model
+	} else if o.Calls > 0 && o.Model != u.Model {
+		model = ""
+	}
+	return Usage{
+		Model:            model,
+		Calls:            u.Calls + o.Calls,
+		PromptTokens:     u.PromptTokens + o.PromptTokens,
+		CompletionTokens: u.CompletionTokens + o.CompletionTokens,
+		ImageTokens:      u.ImageTokens + o.ImageTokens,
+	}
+}
+
+// Price is the cost of a model in USD per million tokens.
+type Price struct {
+	InputPerMTok  float64 `json:"input_per_mtok"`
+	OutputPerMTok float64 `json:"output_per_mtok"`
+}
+
+// PriceTable maps model names to prices. A model matches the longest
+// table key it starts with, so "gpt-4o" also prices "gpt-4o-2024-08-06".
+type PriceTable map[string]Price
+
+// DefaultPrices holds list prices of common models.
+var DefaultPrices = PriceTable{
+	"gpt-4o":            {InputPerMTok: 2.50, OutputPerMTok: 10.00},
+	"gpt-4o-mini":       {InputPerMTok: 0.15, OutputPerMTok: 0.60},
+	"gpt-4.1":           {InputPerMTok: 2.00, OutputPerMTok: 8.00},
+	"gpt-4.1-mini":      {InputPerMTok: 0.40, OutputPerMTok: 1.60},
+	"gpt-4.1-nano":      {InputPerMTok: 0.10, OutputPerMTok: 0.40},
+	"claude-sonnet-4":   {InputPerMTok: 3.00, OutputPerMTok: 15.00},
+	"claude-opus-4":     {InputPerMTok: 15.00, OutputPerMTok: 75.00},
+	"claude-3-5-haiku":  {InputPerMTok: 0.80, OutputPerMTok: 4.00},
+	"claude-3-7-sonnet": {InputPerMTok: 3.00, OutputPerMTok: 15.00},
+}
+
+// LoadPrices reads a JSON price table from path and merges it over
+// DefaultPrices.
+func LoadPrices(path string) (PriceTable, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var custom PriceTable
+	if err := json.Unmarshal(raw, &custom); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	prices := make(PriceTable, len(DefaultPrices)+len(custom))
+	for model, p := range DefaultPrices {
+		prices[model] = p
+	}
+	for model, p := range custom {
+		prices[model] = p
+	}
+	return prices, nil
+}
+
+func (t PriceTable) lookup(model string) (Price, bool) {
+	var (
+		best  Price
+		found bool
+		size  int
+	)
+	for name, p := range t {
+		if strings.HasPrefix(model, name) && len(name) > size {
+			best, found, size = p, true, len(name)
+		}
+	}
+	return best, found
+}
+
+// Cost returns the price of u in USD. ok is false when the model is not in
+// the table.
+func (t PriceTable) Cost(u Usage) (cost float64, ok bool) {
+	p, ok := t.lookup(u.Model)
+	if !ok {
+		return 0, false
+	}
+	return (float64(u.PromptTokens)*p.InputPerMTok + float64(u.CompletionTokens)*p.OutputPerMTok) / 1e6, true
+}
+
+func decodeImageSize(b64 string) (w, h int, ok bool) {
+	raw, err := base64.StdEncoding.DecodeString(b64)
+	if err != nil {
+		return 0, 0, false
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(raw))
+	if err != nil {
+		return 0, 0, false
+	}
+	return cfg.Width, cfg.Height, true
+}
+
+// openAIImageTokens estimates the tokens of a high-detail image: the image
+// is fit into 2048x2048, its short side scaled to 768, then billed at 170
+// tokens per 512px tile plus 85.
+func openAIImageTokens(b64 string) int {
+	w, h, ok := decodeImageSize(b64)
+	if !ok || w == 0 || h == 0 {
+		return 0
+	}
+	fw, fh := float64(w), float64(h)
+	if s := 2048 / math.Max(fw, fh); s < 1 {
+		fw, fh = fw*s, fh*s
+	}
+	if s := 768 / math.Min(fw, fh); s < 1 {
+		fw, fh = fw*s, fh*s
+	}
+	tiles := math.Ceil(fw/512) * math.Ceil(fh/512)
+	return 85 + 170*int(tiles)
+}
+
+// anthropicImageTokens estimates image tokens as width*height/750.
+func anthropicImageTokens(b64 string) int {
+	w, h, ok := decodeImageSize(b64)
+	if !ok {
+		return 0
+	}
+	return w * h / 750
+}
@@ -0,0 +1,93 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"image"
+	"image/png"
+	"math"
+	"testing"
+)
+
+func TestUsageAdd(t *testing.T) {
+	a := Usage{Model: "gpt-4o", Calls: 1, PromptTokens: 100, CompletionTokens: 10, ImageTokens: 85}
+	b := Usage{Model: "gpt-4o", Calls: 2, PromptTokens: 50, CompletionTokens: 5}
+
+	sum := Usage{}.Add(a).Add(b)
+	if sum.Model != "gpt-4o" || sum.Calls != 3 || sum.PromptTokens != 150 || sum.CompletionTokens != 15 || sum.ImageTokens != 85 {
+		t.Errorf("unexpected sum: %+v", sum)
+	}
+	if sum.TotalTokens() != 165 {
+		t.Errorf("total = %d", sum.TotalTokens())
+	}
+	if mixed := sum.Add(Usage{Model: "claude-sonnet-4", Calls: 1}); mixed.Model != "" {
+		t.Errorf("mixed models kept %q", mixed.Model)
+	}
+}
+
+func TestPriceTableCost(t *testing.T) {
+	prices := PriceTable{
+		"gpt-4o":      {InputPerMTok: 2.5, OutputPerMTok: 10},
+		"gpt-4o-mini": {InputPerMTok: 0.15, OutputPerMTok: 0.6},
+	}
+	u := Usage{PromptTokens: 1_000_000, CompletionTokens: 100_000}
+
+	cases := []struct {
+		model string
+		want  float64
+		ok    bool
+	}{
+		{"gpt-4o", 3.5, true},
+		{"gpt-4o-2024-08-06", 3.5, true},
+		{"gpt-4o-mini-2024-07-18", 0.21, true},
+		{"llava:13b", 0, false},
+	}
+	for _, tc := range cases {
+		u.Model = tc.model
+		got, ok := prices.Cost(u)
+		if ok != tc.ok || math.Abs(got-tc.want) > 1e-9 {
+			t.Errorf("%s: cost = %v, %v; want %v, %v", tc.model, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestImageTokenEstimates(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewGray(image.Rect(0, 0, 1280, 800))); err != nil {
+		t.Fatal(err)
+	}
+	b64 := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	// 1280x800 scales to 1229x768: 3x2 tiles.
+	if got := openAIImageTokens(b64); got != 85+170*6 {
+		t.Errorf("openai image tokens = %d", got)
+	}
+	if got := anthropicImageTokens(b64); got != 1280*800/750 {
+		t.Errorf("anthropic image tokens = %d", got)
+	}
+	if got := openAIImageTokens("not base64"); got != 0 {
+		t.Errorf("invalid image estimated at %d tokens", got)
+	}
+}
+
+func TestDecideActionReportsUsage(t *testing.T) {
+	srv, _ := openAIStub(t,
+		`{"action":{"type":"click","target_id":42}}`,
+		`{"action":{"type":"click","target_id":3}}`,
+	)
+
+	client, err := NewClient(ProviderConfig{Provider: ProviderOpenAI, BaseURL: srv.URL, Model: "m"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := client.DecideAction(context.Background(), DecisionInput{TargetIDs: []int{3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Usage{Model: "gpt-4o-2024-08-06", Calls: 2, PromptTokens: 200, CompletionTokens: 40}
+	if out.Usage != want {
+		t.Errorf("usage = %+v, want %+v", out.Usage, want)
+	}
+}
@@ -0,0 +1,324 @@
+package browseragent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/agent"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/browser"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/llm"
+)
+
+// LLM decides on the next action for each step and summarizes finished
+// runs. Implement it to plug in your own model; NewOpenAI returns the
+// built-in OpenAI implementation.
+type LLM = llm.Client
+
+// Browser is a browser the agent can drive. Values are created with
+// NewBrowser; the interface is sealed so the agent can rely on the
+// underlying driver.
+type Browser interface {
+	// Navigate opens url in the current tab and waits for the load.
+	Navigate(ctx context.Context, url string) error
+	// ExportStorageState saves the cookies and the web storage of the
+	// open origins to a JSON file, to reuse a login in later runs.
+	ExportStorageState(ctx context.Context, path string) error
+	// ImportStorageState loads a file written by ExportStorageState; call
+	// it before the first Navigate.
+	ImportStorageState(ctx context.Context, path string) error
+	// Close shuts the browser down.
+	Close()
+
+	driver() browser.Driver
+}
+
+type openBrowser struct {
+	b      browser.Browser
+	policy URLPolicy // BrowserOptions.Policy, restored after StayOnSite runs
+}
+
+func (b *openBrowser) Navigate(ctx context.Context, url string) error {
+	return b.b.Navigate(ctx, url)
+}
+
+func (b *openBrowser) ExportStorageState(ctx context.Context, path string) error {
+	return b.b.ExportStorageState(ctx, path)
+}
+
+func (b *openBrowser) ImportStorageState(ctx context.Context, path string) error {
+	return b.b.ImportStorageState(ctx, path)
+}
+
+func (b *openBrowser) Close() {
+	b.b.Close()
+}
+
+func (b *openBrowser) driver() browser.Driver {
+	return b.b
+}
+
+// BrowserOptions configures the browser: backend, headless mode, window
+// size, binary, profile, sandbox and extra flags.
+type BrowserOptions = browser.Options
+
+// Flags maps Chrome switches, by name without the leading dashes, to their
+// values; set it in BrowserOptions.Flags. A true value passes the bare
+// switch, false removes it.
+type Flags = browser.Flags
+
+// Backend selects how the browser is driven; set it in
+// BrowserOptions.Backend.
+type Backend = browser.Backend
+
+const (
+	// BackendChromedp drives Chrome over the DevTools protocol. It is the
+	// default and supports every BrowserOptions field.
+	BackendChromedp = browser.BackendChromedp
+	// BackendPlaywright drives Chromium, Firefox or WebKit, selected by
+	// BrowserOptions.Engine, through Playwright.
+	BackendPlaywright = browser.BackendPlaywright
+)
+
+// HeadlessMode selects how Chrome runs without a window.
+type HeadlessMode = browser.HeadlessMode
+
+const (
+	HeadlessOff = browser.HeadlessOff
+	Headless    = browser.Headless
+	HeadlessNew = browser.HeadlessNew
+)
+
+// URLPolicy limits the sites the browser tab may load; set it in
+// BrowserOptions.Policy.
+type URLPolicy = browser.URLPolicy
+
+// BlockOptions selects requests the browser does not load, by resource
+// type, URL pattern or the built-in tracker list; set it in
+// BrowserOptions.Block.
+type BlockOptions = browser.BlockOptions
+
+// BlockStats counts blocked requests; Result.Blocked holds those of a run.
+type BlockStats = browser.BlockStats
+
+// Emulation sets the device preset, locale, time zone and geolocation the
+// pages see; set it in BrowserOptions.Emulation.
+type Emulation = browser.Emulation
+
+// Geolocation is an emulated position in degrees.
+type Geolocation = browser.Geolocation
+
+// Proxy routes the browser through a proxy server, with optional
+// credentials; set it in BrowserOptions.Proxy.
+type Proxy = browser.Proxy
+
+// SiteCredentials log in to a site protected by HTTP authentication; list
+// them in BrowserOptions.Credentials.
+type SiteCredentials = browser.SiteCredentials
+
+// ErrNavigationBlocked is returned when navigating to a URL the URLPolicy
+// does not allow.
+var ErrNavigationBlocked = browser.ErrNavigationBlocked
+
+// ErrBrowserCrashed ends a run with ExitCrashed when Chrome crashed more
+// often than Options.MaxRecoveries allows.
+var ErrBrowserCrashed = browser.ErrBrowserCrashed
+
+// ErrNoPlaywright is returned by NewBrowserWithOptions for BackendPlaywright
+// when the Playwright driver is not installed.
+var ErrNoPlaywright = browser.ErrNoPlaywright
+
+// ErrProfileInUse is returned by NewBrowserWithOptions when another agent
+// or a Chrome instance already uses the profile directory.
+var ErrProfileInUse = browser.ErrProfileInUse
+
+// DefaultBrowserOptions returns the options used by NewBrowser: a visible
+// 1280x800 window with a persistent profile in the temp directory.
+func DefaultBrowserOptions() BrowserOptions {
+	return browser.DefaultOptions()
+}
+
+// NewBrowser launches Chrome for the agent with DefaultBrowserOptions.
+func NewBrowser() (Browser, error) {
+	return NewBrowserWithOptions(DefaultBrowserOptions())
+}
+
+// NewBrowserWithOptions launches the browser configured by opts.
+func NewBrowserWithOptions(opts BrowserOptions) (Browser, error) {
+	b, err := browser.Open(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &openBrowser{b: b, policy: opts.Policy}, nil
+}
+
+// NewOpenAI returns an LLM backed by the OpenAI API. The API key is read
+// from OPENAI_API_KEY.
+func NewOpenAI() (LLM, error) {
+	return llm.NewOpenAIClient()
+}
+
+// ProviderConfig selects an LLM provider: base URL, model, API key source
+// and extra HTTP headers.
+type ProviderConfig = llm.ProviderConfig
+
+// Provider names an LLM backend.
+type Provider = llm.Provider
+
+// Supported providers. ProviderOpenAI also covers any OpenAI-compatible
+// server when ProviderConfig.BaseURL is set.
+const (
+	ProviderOpenAI    = llm.ProviderOpenAI
+	ProviderAnthropic = llm.ProviderAnthropic
+	ProviderOllama    = llm.ProviderOllama
+	ProviderVLLM      = llm.ProviderVLLM
+	ProviderLlamaCpp  = llm.ProviderLlamaCpp
+)
+
+// RetryPolicy controls retries of LLM calls; set it in ProviderConfig.Retry.
+type RetryPolicy = llm.RetryPolicy
+
+// DefaultRetryPolicy returns the retry policy used when none is set.
+func DefaultRetryPolicy() RetryPolicy {
+	return llm.DefaultRetryPolicy()
+}
+
+// APIError is returned by the built-in LLMs for failed API calls. Runs stop
+// with ExitLLMError when the error Kind is ErrorAuth or ErrorQuota.
+type APIError = llm.APIError
+
+// ErrorKind classifies an APIError.
+type ErrorKind = llm.ErrorKind
+
+// Error kinds of APIError.
+const (
+	ErrorAuth           = llm.ErrorAuth
+	ErrorQuota          = llm.ErrorQuota
+	ErrorRateLimit      = llm.ErrorRateLimit
+	ErrorTransient      = llm.ErrorTransient
+	ErrorInvalidRequest = llm.ErrorInvalidRequest
+)
+
+// NewLLM returns an LLM for the configured provider.
+func NewLLM(cfg ProviderConfig) (LLM, error) {
+	return llm.NewClient(cfg)
+}
+
+// Task describes what the agent should do.
+type Task struct {
+	// StartURL is opened before the first step. Leave empty to start from
+	// the page that is already open.
+	StartURL string
+	// Instruction is the natural-language task.
+	Instruction string
+	// MaxSteps overrides Options.MaxSteps when positive.
+	MaxSteps int
+	// StayOnSite adds the StartURL host to the allowlist of the browser's
+	// URLPolicy for the run, so the tab cannot leave the site.
+	StayOnSite bool
+	// Schema switches the run to structured extraction: the model must
+	// finish with data matching this JSON Schema, available in
+	// Result.Answer.Data.
+	Schema json.RawMessage
+}
+
+// Agent runs tasks in a Browser using an LLM.
+type Agent struct {
+	browser Browser
+	inner   *agent.Agent
+}
+
+// New creates an Agent. Zero-valued options fall back to DefaultOptions;
+// a negative StepDelay, StepTimeout or MaxRecoveries and an empty non-nil
+// Observers turn them off.
+func New(b Browser, model LLM, opts Options) (*Agent, error) {
+	if b == nil {
+		return nil, errors.New("browseragent: nil browser")
+	}
+	if model == nil {
+		return nil, errors.New("browseragent: nil LLM")
+	}
+	return &Agent{
+		browser: b,
+		inner:   agent.NewAgentWithOptions(b.driver(), model, opts),
+	}, nil
+}
+
+// Options returns the effective options of the agent.
+func (a *Agent) Options() Options {
+	return a.inner.Options()
+}
+
+// Run executes task and returns its Result. The Result is returned even
+// when err is non-nil (for example ErrMaxSteps or ErrInterrupted), unless
+// the task could not be started at all.
+func (a *Agent) Run(ctx context.Context, task Task) (*Result, error) {
+	if task.Instruction == "" {
+		return nil, errors.New("browseragent: empty task instruction")
+	}
+
+	if task.StayOnSite {
+		restore, err := a.stayOnSite(ctx, task.StartURL)
+		if err != nil {
+			return nil, err
+		}
+		defer restore()
+	}
+
+	if task.StartURL != "" {
+		if err := a.browser.Navigate(ctx, task.StartURL); err != nil {
+			return nil, fmt.Errorf("open start URL %s: %w", task.StartURL, err)
+		}
+	}
+
+	instruction := task.Instruction
+	if task.StayOnSite {
+		instruction = agent.BuildTaskWithEnvironment(instruction, task.StartURL)
+	}
+
+	if task.Schema != nil {
+		return a.inner.RunWithSchema(ctx, instruction, task.Schema, task.MaxSteps)
+	}
+	return a.inner.RunWithResult(ctx, instruction, task.MaxSteps)
+}
+
+// stayOnSite adds the host of startURL to the allowlist of the browser and
+// returns a function that restores its own policy.
+func (a *Agent) stayOnSite(ctx context.Context, startURL string) (func(), error) {
+	u, err := url.Parse(startURL)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("browseragent: StayOnSite needs a start URL with a host, got %q", startURL)
+	}
+	ob := a.browser.(*openBrowser)
+	policy := ob.policy
+	policy.Allow = append(slices.Clone(policy.Allow), u.Host)
+	if err := ob.b.SetURLPolicy(ctx, policy); err != nil {
+		return nil, fmt.Errorf("browseragent: stay on site: %w", err)
+	}
+	return func() {
+		// The run context may be done; restoring must not depend on it.
+		_ = ob.b.SetURLPolicy(context.WithoutCancel(ctx), ob.policy)
+	}, nil
+}
+
+// Extract runs task in extraction mode and decodes the extracted data
+// into T. task.Schema must be set.
+func Extract[T any](ctx context.Context, a *Agent, task Task) (T, *Result, error) {
+	var out T
+
+	if task.Schema == nil {
+		return out, nil, errors.New("browseragent: Extract requires Task.Schema")
+	}
+
+	res, err := a.Run(ctx, task)
+	if err != nil {
+		return out, res, err
+	}
+	if err := json.Unmarshal(res.Answer.Data, &out); err != nil {
+		return out, res, fmt.Errorf("decode extracted data: %w", err)
+	}
+	return out, res, nil
+}
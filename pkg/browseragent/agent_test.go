@@ -0,0 +1,87 @@
+package browseragent
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/browser"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/browser/browsertest"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/llm"
+)
+
+// policyBrowser is a browser.Browser on a browsertest.Fake that records the
+// URL policies it is given.
+type policyBrowser struct {
+	*browsertest.Fake
+	policies []URLPolicy
+}
+
+func (b *policyBrowser) SetURLPolicy(_ context.Context, p URLPolicy) error {
+	b.policies = append(b.policies, p)
+	return nil
+}
+
+func (b *policyBrowser) Text(context.Context) (string, error)             { return "", nil }
+func (b *policyBrowser) ExportStorageState(context.Context, string) error { return nil }
+func (b *policyBrowser) ImportStorageState(context.Context, string) error { return nil }
+func (b *policyBrowser) Remote() bool                                     { return false }
+func (b *policyBrowser) BlockedNavigations() []browser.BlockedNavigation  { return nil }
+
+type finishLLM struct{}
+
+func (finishLLM) DecideAction(context.Context, llm.DecisionInput) (*llm.DecisionOutput, error) {
+	return &llm.DecisionOutput{Action: llm.Action{Type: llm.ActionFinish}}, nil
+}
+
+func (finishLLM) SummarizeRun(context.Context, llm.SummaryInput) (*llm.SummaryOutput, error) {
+	return &llm.SummaryOutput{}, nil
+}
+
+func TestStayOnSiteInstallsAllowlist(t *testing.T) {
+	pb := &policyBrowser{Fake: browsertest.New(map[string]*browsertest.Page{
+		"https://shop.example.com/cart": {Title: "Cart"},
+	})}
+	base := URLPolicy{Allow: []string{"cdn.example.net"}, Deny: []string{"pay.example.org"}}
+	opts := DefaultOptions()
+	opts.StepDelay = -1
+	opts.Observers = []Observer{}
+	a, err := New(&openBrowser{b: pb, policy: base}, finishLLM{}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = a.Run(context.Background(), Task{
+		StartURL:    "https://shop.example.com/cart",
+		Instruction: "check the cart",
+		StayOnSite:  true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []URLPolicy{
+		{Allow: []string{"cdn.example.net", "shop.example.com"}, Deny: []string{"pay.example.org"}},
+		base,
+	}
+	if !reflect.DeepEqual(pb.policies, want) {
+		t.Errorf("policies = %+v, want %+v", pb.policies, want)
+	}
+	if len(base.Allow) != 1 {
+		t.Errorf("base allowlist modified: %v", base.Allow)
+	}
+}
+
+func TestStayOnSiteNeedsStartURL(t *testing.T) {
+	pb := &policyBrowser{Fake: browsertest.New(nil)}
+	a, err := New(&openBrowser{b: pb}, finishLLM{}, DefaultOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Run(context.Background(), Task{Instruction: "x", StayOnSite: true}); err == nil {
+		t.Error("expected error without a start URL")
+	}
+	if len(pb.policies) != 0 {
+		t.Errorf("policy set without a start URL: %+v", pb.policies)
+	}
+}
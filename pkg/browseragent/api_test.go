@@ -0,0 +1,379 @@
+package browseragent_test
+
+import (
+	"context"
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/nbenliogludev/go-browser-ai-agent/pkg/browseragent"
+)
+
+var (
+	_ func() (browseragent.Browser, error)                                                                  = browseragent.NewBrowser
+	_ func(browseragent.BrowserOptions) (browseragent.Browser, error)                                       = browseragent.NewBrowserWithOptions
+	_ func() (browseragent.LLM, error)                                                                      = browseragent.NewOpenAI
+	_ func(browseragent.ProviderConfig) (browseragent.LLM, error)                                           = browseragent.NewLLM
+	_ func() browseragent.Options                                                                           = browseragent.DefaultOptions
+	_ func(browseragent.Browser, browseragent.LLM, browseragent.Options) (*browseragent.Agent, error)       = browseragent.New
+	_ func(context.Context, *browseragent.Agent, browseragent.Task) (struct{}, *browseragent.Result, error) = browseragent.Extract[struct{}]
+
+	_ func(*browseragent.Agent, context.Context, browseragent.Task) (*browseragent.Result, error) = (*browseragent.Agent).Run
+	_ func(*browseragent.Agent) browseragent.Options                                              = (*browseragent.Agent).Options
+
+	_ browseragent.Observer = browseragent.NopObserver{}
+	_ browseragent.LLM      = fakeLLM{}
+)
+
+type fakeLLM struct{}
+
+func (fakeLLM) DecideAction(context.Context, browseragent.DecisionInput) (*browseragent.Decision, error) {
+	return &browseragent.Decision{Action: browseragent.Action{Type: browseragent.ActionFinish}}, nil
+}
+
+func (fakeLLM) SummarizeRun(context.Context, browseragent.SummaryInput) (*browseragent.SummaryOutput, error) {
+	return &browseragent.SummaryOutput{}, nil
+}
+
+var exportedAPI = []string{
+	"ActionClick", "ActionFinish", "ActionScroll", "ActionType", "ActionTypeInput", "Action",
+	"APIError", "Agent", "Backend", "BackendChromedp", "BackendPlaywright", "BlockOptions", "BlockStats", "Browser", "BrowserOptions", "Decision", "DecisionInput", "DefaultBrowserOptions", "DefaultOptions", "DefaultRetryPolicy",
+	"Element", "ElementMap", "Flags",
+	"ErrorAuth", "ErrorInvalidRequest", "ErrorKind", "ErrorQuota", "ErrorRateLimit", "ErrorTransient",
+	"ErrActionDeclined", "ErrBrowserCrashed", "ErrBudget", "ErrDeadline", "ErrExtractionRejected", "ErrInterrupted",
+	"ErrLLMFail", "ErrMaxSteps", "ErrNavigationBlocked", "ErrNoPlaywright", "ErrProfileInUse", "ErrSnapshotFail",
+	"DefaultPrices", "Emulation", "Geolocation", "ExitBudget", "ExitCancelled", "ExitCrashed", "ExitDeadline", "ExitFinished", "ExitLLMError", "ExitMaxSteps", "ExitReason",
+	"Extract", "FinalAnswer", "Headless", "HeadlessMode", "HeadlessNew", "HeadlessOff", "FinishFailure", "FinishPartial", "FinishStatus", "FinishSuccess",
+	"LLM", "ModelParams", "New", "NewBrowser", "NewBrowserWithOptions", "NewLLM", "NewOpenAI", "NopObserver", "Observer", "Options",
+	"OutcomeBlocked", "OutcomeDeclined", "OutcomeError", "OutcomeExecuted", "OutcomeFailed",
+	"OutcomeFinished", "OutcomeRejected",
+	"PageSnapshot", "Price", "PriceTable", "Provider", "Proxy", "ProviderAnthropic", "ProviderConfig", "ProviderLlamaCpp",
+	"ProviderOllama", "ProviderOpenAI", "ProviderVLLM", "Result", "RetryPolicy", "SiteCredentials", "SnapshotInfo", "StepOutcome", "StepRecord", "StepTimings",
+	"SummaryInput", "SummaryOutput", "Task", "URLPolicy", "Usage",
+}
+
+func TestExportedIdentifiers(t *testing.T) {
+	entries, err := os.ReadDir(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fset := token.NewFileSet()
+	var got []string
+	for _, e := range entries {
+		name := e.Name()
+		if !strings.HasSuffix(name, ".go") || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(fset, name, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, exportedNames(f)...)
+	}
+
+	want := append([]string(nil), exportedAPI...)
+	sort.Strings(got)
+	sort.Strings(want)
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("exported API changed; update exportedAPI after review.\n got: %v\nwant: %v", got, want)
+	}
+}
+
+func exportedNames(f *ast.File) []string {
+	var names []string
+	for _, decl := range f.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Recv == nil && d.Name.IsExported() {
+				names = append(names, d.Name.Name)
+			}
+		case *ast.GenDecl:
+			for _, spec := range d.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if s.Name.IsExported() {
+						names = append(names, s.Name.Name)
+					}
+				case *ast.ValueSpec:
+					for _, n := range s.Names {
+						if n.IsExported() {
+							names = append(names, n.Name)
+						}
+					}
+				}
+			}
+		}
+	}
+	return names
+}
+
+func TestObserverMethods(t *testing.T) {
+	want := []string{
+		"OnActionResult", "OnApprovalRequested", "OnDecision", "OnFinish",
+		"OnLoopBlocked", "OnSnapshot", "OnStepEnd", "OnStepStart",
+	}
+	typ := reflect.TypeOf((*browseragent.Observer)(nil)).Elem()
+	var got []string
+	for i := 0; i < typ.NumMethod(); i++ {
+		got = append(got, typ.Method(i).Name)
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Observer methods = %v, want %v", got, want)
+	}
+}
+
+func TestStructFields(t *testing.T) {
+	cases := []struct {
+		value  any
+		fields map[string]reflect.Type
+	}{
+		{browseragent.Task{}, map[string]reflect.Type{
+			"StartURL":    reflect.TypeOf(""),
+			"Instruction": reflect.TypeOf(""),
+			"MaxSteps":    reflect.TypeOf(0),
+			"StayOnSite":  reflect.TypeOf(false),
+			"Schema":      reflect.TypeOf(json.RawMessage(nil)),
+		}},
+		{browseragent.Options{}, map[string]reflect.Type{
+			"MaxSteps":           reflect.TypeOf(0),
+			"HistoryWindow":      reflect.TypeOf(0),
+			"LoopThreshold":      reflect.TypeOf(0),
+			"PatternLength":      reflect.TypeOf(0),
+			"PatternRepeats":     reflect.TypeOf(0),
+			"StepDelay":          reflect.TypeOf(time.Duration(0)),
+			"StepTimeout":        reflect.TypeOf(time.Duration(0)),
+			"DisableScreenshots": reflect.TypeOf(false),
+			"MaxRecoveries":      reflect.TypeOf(0),
+			"Model":              reflect.TypeOf(browseragent.ModelParams{}),
+			"Observers":          reflect.TypeOf([]browseragent.Observer(nil)),
+			"Approve":            reflect.TypeOf(func(context.Context, browseragent.Action) bool { return false }),
+			"Prices":             reflect.TypeOf(browseragent.PriceTable(nil)),
+			"TokenBudget":        reflect.TypeOf(0),
+			"CostBudget":         reflect.TypeOf(float64(0)),
+		}},
+		{browseragent.ModelParams{}, map[string]reflect.Type{
+			"Model":       reflect.TypeOf(""),
+			"Temperature": reflect.TypeOf(float32(0)),
+			"MaxTokens":   reflect.TypeOf(0),
+		}},
+		{browseragent.Result{}, map[string]reflect.Type{
+			"Task":       reflect.TypeOf(""),
+			"ExitReason": reflect.TypeOf(browseragent.ExitReason("")),
+			"Error":      reflect.TypeOf(""),
+			"FinalURL":   reflect.TypeOf(""),
+			"Answer":     reflect.TypeOf(&browseragent.FinalAnswer{}),
+			"Summary":    reflect.TypeOf(""),
+			"Steps":      reflect.TypeOf([]browseragent.StepRecord(nil)),
+			"StartedAt":  reflect.TypeOf(time.Time{}),
+			"Duration":   reflect.TypeOf(time.Duration(0)),
+			"Usage":      reflect.TypeOf(browseragent.Usage{}),
+			"Cost":       reflect.TypeOf(float64(0)),
+			"Blocked":    reflect.TypeOf(browseragent.BlockStats{}),
+			"Recoveries": reflect.TypeOf(0),
+		}},
+		{browseragent.StepRecord{}, map[string]reflect.Type{
+			"Step":      reflect.TypeOf(0),
+			"StartedAt": reflect.TypeOf(time.Time{}),
+			"Snapshot":  reflect.TypeOf(&browseragent.SnapshotInfo{}),
+			"Decision":  reflect.TypeOf(&browseragent.Decision{}),
+			"Outcome":   reflect.TypeOf(browseragent.StepOutcome("")),
+			"Note":      reflect.TypeOf(""),
+			"Error":     reflect.TypeOf(""),
+			"Timings":   reflect.TypeOf(browseragent.StepTimings{}),
+			"Usage":     reflect.TypeOf(browseragent.Usage{}),
+			"Cost":      reflect.TypeOf(float64(0)),
+		}},
+		{browseragent.FinalAnswer{}, map[string]reflect.Type{
+			"Answer":   reflect.TypeOf(""),
+			"Success":  reflect.TypeOf(browseragent.FinishStatus("")),
+			"Evidence": reflect.TypeOf([]string(nil)),
+			"Data":     reflect.TypeOf(json.RawMessage(nil)),
+		}},
+		{browseragent.Action{}, map[string]reflect.Type{
+			"Type":              reflect.TypeOf(browseragent.ActionType("")),
+			"TargetID":          reflect.TypeOf(0),
+			"Text":              reflect.TypeOf(""),
+			"Submit":            reflect.TypeOf(false),
+			"IsDestructive":     reflect.TypeOf(false),
+			"DestructiveReason": reflect.TypeOf(""),
+			"Answer":            reflect.TypeOf(""),
+			"Success":           reflect.TypeOf(browseragent.FinishStatus("")),
+			"Evidence":          reflect.TypeOf([]string(nil)),
+			"Data":              reflect.TypeOf(json.RawMessage(nil)),
+		}},
+		{browseragent.BrowserOptions{}, map[string]reflect.Type{
+			"Backend":         reflect.TypeOf(browseragent.Backend("")),
+			"Engine":          reflect.TypeOf(""),
+			"RemoteURL":       reflect.TypeOf(""),
+			"RemoteTab":       reflect.TypeOf(""),
+			"Headless":        reflect.TypeOf(browseragent.HeadlessMode("")),
+			"WindowWidth":     reflect.TypeOf(0),
+			"WindowHeight":    reflect.TypeOf(0),
+			"ExecPath":        reflect.TypeOf(""),
+			"UserDataDir":     reflect.TypeOf(""),
+			"Profile":         reflect.TypeOf(""),
+			"ProfileRoot":     reflect.TypeOf(""),
+			"Ephemeral":       reflect.TypeOf(false),
+			"ProfileTemplate": reflect.TypeOf(""),
+			"NoSandbox":       reflect.TypeOf(false),
+			"Flags":           reflect.TypeOf(browseragent.Flags(nil)),
+			"Policy":          reflect.TypeOf(browseragent.URLPolicy{}),
+			"Block":           reflect.TypeOf(browseragent.BlockOptions{}),
+			"Emulation":       reflect.TypeOf(browseragent.Emulation{}),
+			"Proxy":           reflect.TypeOf(browseragent.Proxy{}),
+			"Credentials":     reflect.TypeOf([]browseragent.SiteCredentials(nil)),
+		}},
+		{browseragent.URLPolicy{}, map[string]reflect.Type{
+			"Allow": reflect.TypeOf([]string(nil)),
+			"Deny":  reflect.TypeOf([]string(nil)),
+		}},
+		{browseragent.BlockOptions{}, map[string]reflect.Type{
+			"ResourceTypes": reflect.TypeOf([]string(nil)),
+			"Patterns":      reflect.TypeOf([]string(nil)),
+			"Trackers":      reflect.TypeOf(false),
+		}},
+		{browseragent.BlockStats{}, map[string]reflect.Type{
+			"Requests": reflect.TypeOf(0),
+			"ByType":   reflect.TypeOf(map[string]int(nil)),
+		}},
+		{browseragent.Emulation{}, map[string]reflect.Type{
+			"Device":      reflect.TypeOf(""),
+			"Locale":      reflect.TypeOf(""),
+			"Timezone":    reflect.TypeOf(""),
+			"Geolocation": reflect.TypeOf(&browseragent.Geolocation{}),
+		}},
+		{browseragent.Geolocation{}, map[string]reflect.Type{
+			"Latitude":  reflect.TypeOf(float64(0)),
+			"Longitude": reflect.TypeOf(float64(0)),
+			"Accuracy":  reflect.TypeOf(float64(0)),
+		}},
+		{browseragent.Proxy{}, map[string]reflect.Type{
+			"Server":   reflect.TypeOf(""),
+			"Bypass":   reflect.TypeOf([]string(nil)),
+			"Username": reflect.TypeOf(""),
+			"Password": reflect.TypeOf(""),
+		}},
+		{browseragent.SiteCredentials{}, map[string]reflect.Type{
+			"Site":     reflect.TypeOf(""),
+			"Username": reflect.TypeOf(""),
+			"Password": reflect.TypeOf(""),
+		}},
+		{browseragent.ProviderConfig{}, map[string]reflect.Type{
+			"Provider":   reflect.TypeOf(browseragent.Provider("")),
+			"BaseURL":    reflect.TypeOf(""),
+			"Model":      reflect.TypeOf(""),
+			"APIKey":     reflect.TypeOf(""),
+			"APIKeyEnv":  reflect.TypeOf(""),
+			"Headers":    reflect.TypeOf(map[string]string(nil)),
+			"HTTPClient": reflect.TypeOf(&http.Client{}),
+			"PlainJSON":  reflect.TypeOf(false),
+			"Retry":      reflect.TypeOf(browseragent.RetryPolicy{}),
+		}},
+		{browseragent.RetryPolicy{}, map[string]reflect.Type{
+			"MaxAttempts":    reflect.TypeOf(0),
+			"BaseDelay":      reflect.TypeOf(time.Duration(0)),
+			"MaxDelay":       reflect.TypeOf(time.Duration(0)),
+			"AttemptTimeout": reflect.TypeOf(time.Duration(0)),
+		}},
+		{browseragent.APIError{}, map[string]reflect.Type{
+			"Provider":   reflect.TypeOf(browseragent.Provider("")),
+			"StatusCode": reflect.TypeOf(0),
+			"Kind":       reflect.TypeOf(browseragent.ErrorKind("")),
+			"Message":    reflect.TypeOf(""),
+			"RetryAfter": reflect.TypeOf(time.Duration(0)),
+			"Err":        reflect.TypeOf((*error)(nil)).Elem(),
+		}},
+		{browseragent.Usage{}, map[string]reflect.Type{
+			"Model":            reflect.TypeOf(""),
+			"Calls":            reflect.TypeOf(0),
+			"PromptTokens":     reflect.TypeOf(0),
+			"CompletionTokens": reflect.TypeOf(0),
+			"ImageTokens":      reflect.TypeOf(0),
+		}},
+		{browseragent.Price{}, map[string]reflect.Type{
+			"InputPerMTok":  reflect.TypeOf(float64(0)),
+			"OutputPerMTok": reflect.TypeOf(float64(0)),
+		}},
+		{browseragent.Decision{}, map[string]reflect.Type{
+			"CurrentPhase": reflect.TypeOf(""),
+			"Observation":  reflect.TypeOf(""),
+			"Thought":      reflect.TypeOf(""),
+			"StepDone":     reflect.TypeOf(false),
+			"Action":       reflect.TypeOf(browseragent.Action{}),
+			"Usage":        reflect.TypeOf(browseragent.Usage{}),
+		}},
+		{browseragent.DecisionInput{}, map[string]reflect.Type{
+			"Task":             reflect.TypeOf(""),
+			"DOMTree":          reflect.TypeOf(""),
+			"CurrentURL":       reflect.TypeOf(""),
+			"History":          reflect.TypeOf(""),
+			"ScreenshotBase64": reflect.TypeOf(""),
+			"OutputSchema":     reflect.TypeOf(""),
+			"Params":           reflect.TypeOf(browseragent.ModelParams{}),
+			"TargetIDs":        reflect.TypeOf([]int(nil)),
+		}},
+		{browseragent.SummaryInput{}, map[string]reflect.Type{
+			"Task":        reflect.TypeOf(""),
+			"ExitReason":  reflect.TypeOf(""),
+			"FinalURL":    reflect.TypeOf(""),
+			"FinalAction": reflect.TypeOf(browseragent.Action{}),
+			"Duration":    reflect.TypeOf(""),
+			"Steps":       reflect.TypeOf([]string(nil)),
+			"Model":       reflect.TypeOf(""),
+		}},
+		{browseragent.SummaryOutput{}, map[string]reflect.Type{
+			"Text":  reflect.TypeOf(""),
+			"Usage": reflect.TypeOf(browseragent.Usage{}),
+		}},
+		{browseragent.PageSnapshot{}, map[string]reflect.Type{
+			"URL":              reflect.TypeOf(""),
+			"Title":            reflect.TypeOf(""),
+			"Tree":             reflect.TypeOf(""),
+			"ScreenshotBase64": reflect.TypeOf(""),
+			"Elements":         reflect.TypeOf(browseragent.ElementMap(nil)),
+		}},
+		{browseragent.StepTimings{}, map[string]reflect.Type{
+			"Snapshot": reflect.TypeOf(time.Duration(0)),
+			"Decision": reflect.TypeOf(time.Duration(0)),
+			"Action":   reflect.TypeOf(time.Duration(0)),
+			"Total":    reflect.TypeOf(time.Duration(0)),
+		}},
+		{browseragent.SnapshotInfo{}, map[string]reflect.Type{
+			"URL":           reflect.TypeOf(""),
+			"Title":         reflect.TypeOf(""),
+			"Elements":      reflect.TypeOf(0),
+			"TreeBytes":     reflect.TypeOf(0),
+			"HasScreenshot": reflect.TypeOf(false),
+			"Unchanged":     reflect.TypeOf(false),
+		}},
+	}
+
+	for _, tc := range cases {
+		typ := reflect.TypeOf(tc.value)
+		for name, want := range tc.fields {
+			f, ok := typ.FieldByName(name)
+			if !ok {
+				t.Errorf("%s.%s: field removed", typ.Name(), name)
+				continue
+			}
+			if f.Type != want {
+				t.Errorf("%s.%s: type %s, want %s", typ.Name(), name, f.Type, want)
+			}
+		}
+	}
+}
+
+func TestNewRejectsNilDependencies(t *testing.T) {
+	if _, err := browseragent.New(nil, fakeLLM{}, browseragent.DefaultOptions()); err == nil {
+		t.Error("expected error for nil browser")
+	}
+}
@@ -0,0 +1,30 @@
+// Package browseragent is the public, importable API of go-browser-ai-agent.
+//
+// The implementation lives in internal packages; this package exposes a
+// stable surface on top of it:
+//
+//   - Browser is the browser the agent drives (see NewBrowser).
+//   - LLM is the model client that decides on actions (see NewOpenAI).
+//   - Agent runs a Task and returns a Result.
+//   - Observer receives run lifecycle events.
+//
+// A minimal run:
+//
+//	b, err := browseragent.NewBrowser()
+//	if err != nil { ... }
+//	defer b.Close()
+//
+//	model, err := browseragent.NewOpenAI()
+//	if err != nil { ... }
+//
+//	ag, err := browseragent.New(b, model, browseragent.DefaultOptions())
+//	if err != nil { ... }
+//
+//	res, err := ag.Run(ctx, browseragent.Task{
+//		StartURL:    "https://example.com",
+//		Instruction: "Find the contact email",
+//	})
+//
+// Everything exported from this package is covered by a compatibility test;
+// removing or changing an identifier is a breaking change.
+package browseragent
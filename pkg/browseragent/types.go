@@ -0,0 +1,151 @@
+package browseragent
+
+import (
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/agent"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/browser"
+	"github.com/nbenliogludev/go-browser-ai-agent/internal/llm"
+)
+
+// Options tunes a run: step limit, loop guard, pacing, model parameters,
+// screenshots, observers and destructive-action approval. Start from
+// DefaultOptions and override what you need.
+type Options = agent.Options
+
+// ModelParams selects the model and sampling parameters for decisions.
+type ModelParams = llm.ModelParams
+
+// DefaultOptions returns the options used by the CLI.
+func DefaultOptions() Options {
+	return agent.DefaultOptions()
+}
+
+// Action is a single browser action chosen by the model.
+type Action = llm.Action
+
+// ActionType is the kind of Action.
+type ActionType = llm.ActionType
+
+// Action types understood by the agent.
+const (
+	ActionClick     = llm.ActionClick
+	ActionTypeInput = llm.ActionTypeInput
+	ActionScroll    = llm.ActionScroll
+	ActionFinish    = llm.ActionFinish
+)
+
+// FinishStatus reports whether the model considers the task done.
+type FinishStatus = llm.FinishStatus
+
+// Finish statuses reported in FinalAnswer.Success.
+const (
+	FinishSuccess = llm.FinishSuccess
+	FinishPartial = llm.FinishPartial
+	FinishFailure = llm.FinishFailure
+)
+
+// SummaryOutput is the run summary returned by an LLM implementation.
+type SummaryOutput = llm.SummaryOutput
+
+// Usage counts the tokens of LLM calls. It is recorded per step in
+// StepRecord.Usage and totalled in Result.Usage.
+type Usage = llm.Usage
+
+// Price is the cost of a model in USD per million tokens.
+type Price = llm.Price
+
+// PriceTable maps model names (or name prefixes) to prices; set it in
+// Options.Prices.
+type PriceTable = llm.PriceTable
+
+// DefaultPrices returns a copy of the built-in price table.
+func DefaultPrices() PriceTable {
+	prices := make(PriceTable, len(llm.DefaultPrices))
+	for model, p := range llm.DefaultPrices {
+		prices[model] = p
+	}
+	return prices
+}
+
+// Decision is the model output for one step.
+type Decision = llm.DecisionOutput
+
+// DecisionInput is what an LLM implementation receives for one step.
+type DecisionInput = llm.DecisionInput
+
+// SummaryInput is what an LLM implementation receives to summarize a run.
+type SummaryInput = llm.SummaryInput
+
+// PageSnapshot is the page state captured before each decision.
+type PageSnapshot = browser.PageSnapshot
+
+// ElementMap maps the element numbers of a PageSnapshot to the driver's
+// references.
+type ElementMap = browser.ElementMap
+
+// Element is a driver's reference to a page element; only the browser that
+// took the snapshot can use it.
+type Element = browser.Element
+
+// Result is the structured outcome of a run: per-step records, exit reason,
+// final URL, final answer and LLM summary. It is JSON-serializable.
+type Result = agent.RunResult
+
+// StepRecord describes one step of a run.
+type StepRecord = agent.StepRecord
+
+// StepTimings holds per-phase durations of a step.
+type StepTimings = agent.StepTimings
+
+// SnapshotInfo is the metadata of the snapshot taken in a step.
+type SnapshotInfo = agent.SnapshotInfo
+
+// StepOutcome tells what happened to the action chosen in a step.
+type StepOutcome = agent.StepOutcome
+
+// Step outcomes recorded in StepRecord.Outcome.
+const (
+	OutcomeExecuted = agent.OutcomeExecuted
+	OutcomeFailed   = agent.OutcomeFailed
+	OutcomeBlocked  = agent.OutcomeBlocked
+	OutcomeRejected = agent.OutcomeRejected
+	OutcomeDeclined = agent.OutcomeDeclined
+	OutcomeFinished = agent.OutcomeFinished
+	OutcomeError    = agent.OutcomeError
+)
+
+// FinalAnswer is the payload of the finish action.
+type FinalAnswer = agent.FinalAnswer
+
+// ExitReason tells why a run stopped.
+type ExitReason = agent.ExitReason
+
+// Exit reasons recorded in Result.ExitReason.
+const (
+	ExitFinished  = agent.ExitFinished
+	ExitMaxSteps  = agent.ExitMaxSteps
+	ExitCancelled = agent.ExitCancelled
+	ExitDeadline  = agent.ExitDeadline
+	ExitBudget    = agent.ExitBudget
+	ExitLLMError  = agent.ExitLLMError
+	ExitCrashed   = agent.ExitCrashed
+)
+
+// Observer receives run lifecycle events. Several observers can be set in
+// Options.Observers; they are called in order from the run goroutine.
+type Observer = agent.Observer
+
+// NopObserver implements Observer with no-op methods. Embed it to handle
+// only the events you need.
+type NopObserver = agent.NopObserver
+
+// Errors returned by Agent.Run. Use errors.Is to match them.
+var (
+	ErrInterrupted        = agent.ErrInterrupted
+	ErrDeadline           = agent.ErrDeadline
+	ErrMaxSteps           = agent.ErrMaxSteps
+	ErrSnapshotFail       = agent.ErrSnapshotFail
+	ErrLLMFail            = agent.ErrLLMFail
+	ErrExtractionRejected = agent.ErrExtractionRejected
+	ErrActionDeclined     = agent.ErrActionDeclined
+	ErrBudget             = agent.ErrBudget
+)